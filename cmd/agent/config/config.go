@@ -9,6 +9,9 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+	"github.com/bjlag/go-metrics/internal/model"
 )
 
 type address struct {
@@ -38,21 +41,56 @@ const (
 	envReportInterval = "REPORT_INTERVAL"
 	envLogLevel       = "LOG_LEVEL"
 	envSecret         = "KEY"
+	envKeyID          = "KEY_ID"
+	envToken          = "TOKEN"
+	envIP             = "AGENT_IP"
+	envInterface      = "AGENT_INTERFACE"
 	envRateLimit      = "RATE_LIMIT"
 	envCrypto         = "CRYPTO_KEY"
 	envConfigPath     = "CONFIG"
+	envLabels         = "LABELS"
+	envRPCStream      = "RPC_STREAM"
+	envQueueDir       = "QUEUE_DIR"
+	envQueueSize      = "QUEUE_SIZE"
+	envStatsDAddress  = "STATSD_ADDRESS"
+	envHTTPTLS        = "HTTP_TLS"
+	envHTTPTLSCA      = "HTTP_TLS_CA"
+	envHTTPTLSCert    = "HTTP_TLS_CERT"
+	envHTTPTLSKey     = "HTTP_TLS_KEY"
+	envRPCTLS         = "RPC_TLS"
+	envRPCTLSCA       = "RPC_TLS_CA"
+	envRPCTLSCert     = "RPC_TLS_CERT"
+	envRPCTLSKey      = "RPC_TLS_KEY"
 )
 
 type Configuration struct {
-	LogLevel       string
-	AddressHTTP    *address
-	AddressRPC     *address
-	ReportInterval time.Duration
-	PollInterval   time.Duration
-	CryptoKeyPath  string
-	SecretKey      string
-	RateLimit      int
-	ConfigPath     string
+	LogLevel        string
+	AddressHTTP     *address
+	AddressRPC      *address
+	ReportInterval  time.Duration
+	PollInterval    time.Duration
+	CryptoKeyPath   string
+	SecretKey       string
+	KeyID           string
+	Token           string
+	IP              string
+	Interface       string
+	RateLimit       int
+	ConfigPath      string
+	Labels          map[string]string
+	RPCStream       bool
+	QueueDir        string
+	QueueSize       int
+	StatsDAddress   string
+	HTTPTLS         bool
+	HTTPTLSCAPath   string
+	HTTPTLSCertPath string
+	HTTPTLSKeyPath  string
+	RPCTLS          bool
+	RPCTLSCAPath    string
+	RPCTLSCertPath  string
+	RPCTLSKeyPath   string
+	Collectors      map[string]collector.Config
 }
 
 func LoadConfig() *Configuration {
@@ -96,8 +134,34 @@ func (c *Configuration) parseFlags() {
 	})
 	flag.StringVar(&c.LogLevel, "log", "", "Log level")
 	flag.StringVar(&c.SecretKey, "k", "", "Secret key")
+	flag.StringVar(&c.KeyID, "key-id", "", "ID of agent key issued by server, sent with signature")
+	flag.StringVar(&c.Token, "token", "", "API access token with ingest role, sent as bearer token")
+	flag.StringVar(&c.IP, "ip", "", "Agent IP address sent to server, by default local address of connection to server")
+	flag.StringVar(&c.Interface, "interface", "", "Network interface whose address is sent to server, if -ip is not set")
 	flag.IntVar(&c.RateLimit, "l", 0, "Rate limit")
 	flag.StringVar(&c.CryptoKeyPath, "crypto-key", "", "Path to public key")
+	flag.Func("labels", "Labels added to all metrics: name=value,name=value", func(s string) error {
+		var err error
+
+		c.Labels, err = parseLabels(s)
+		if err != nil {
+			return fmt.Errorf("parse labels error: %w", err)
+		}
+
+		return nil
+	})
+	flag.BoolVar(&c.RPCStream, "rpc-stream", false, "Send metrics to RPC server over one long-lived stream")
+	flag.StringVar(&c.QueueDir, "queue-dir", "", "Directory of on-disk queue for unsent metrics, empty disables queue")
+	flag.IntVar(&c.QueueSize, "queue-size", 0, "Max number of batches in on-disk queue")
+	flag.StringVar(&c.StatsDAddress, "statsd", "", "StatsD UDP listen address: host:port, empty disables listener")
+	flag.BoolVar(&c.HTTPTLS, "http-tls", false, "Send metrics over HTTPS, server certificate is verified by system CAs unless -http-tls-ca is set")
+	flag.StringVar(&c.HTTPTLSCAPath, "http-tls-ca", "", "Path to CA certificates to verify HTTP server certificate, enables HTTPS")
+	flag.StringVar(&c.HTTPTLSCertPath, "http-tls-cert", "", "Path to HTTP client TLS certificate, enables HTTPS and mTLS")
+	flag.StringVar(&c.HTTPTLSKeyPath, "http-tls-key", "", "Path to HTTP client TLS private key")
+	flag.BoolVar(&c.RPCTLS, "rpc-tls", false, "Connect to RPC server over TLS, server certificate is verified by system CAs unless -rpc-tls-ca is set")
+	flag.StringVar(&c.RPCTLSCAPath, "rpc-tls-ca", "", "Path to CA certificates to verify RPC server certificate, enables TLS")
+	flag.StringVar(&c.RPCTLSCertPath, "rpc-tls-cert", "", "Path to RPC client TLS certificate, enables TLS and mTLS")
+	flag.StringVar(&c.RPCTLSKeyPath, "rpc-tls-key", "", "Path to RPC client TLS private key")
 	flag.StringVar(&c.ConfigPath, "c", "", "Path to config JSON file")
 	flag.StringVar(&c.ConfigPath, "config", "", "Path to config JSON file")
 
@@ -143,6 +207,22 @@ func (c *Configuration) parseEnvs() {
 		c.SecretKey = value
 	}
 
+	if value := os.Getenv(envKeyID); value != "" {
+		c.KeyID = value
+	}
+
+	if value := os.Getenv(envToken); value != "" {
+		c.Token = value
+	}
+
+	if value := os.Getenv(envIP); value != "" {
+		c.IP = value
+	}
+
+	if value := os.Getenv(envInterface); value != "" {
+		c.Interface = value
+	}
+
 	if value := os.Getenv(envRateLimit); value != "" {
 		c.RateLimit, err = strconv.Atoi(value)
 		if err != nil {
@@ -154,6 +234,73 @@ func (c *Configuration) parseEnvs() {
 		c.CryptoKeyPath = value
 	}
 
+	if value := os.Getenv(envLabels); value != "" {
+		c.Labels, err = parseLabels(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if value := os.Getenv(envRPCStream); value != "" {
+		c.RPCStream, err = strconv.ParseBool(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if value := os.Getenv(envQueueDir); value != "" {
+		c.QueueDir = value
+	}
+
+	if value := os.Getenv(envQueueSize); value != "" {
+		c.QueueSize, err = strconv.Atoi(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if value := os.Getenv(envStatsDAddress); value != "" {
+		c.StatsDAddress = value
+	}
+
+	if value := os.Getenv(envHTTPTLS); value != "" {
+		c.HTTPTLS, err = strconv.ParseBool(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if value := os.Getenv(envHTTPTLSCA); value != "" {
+		c.HTTPTLSCAPath = value
+	}
+
+	if value := os.Getenv(envHTTPTLSCert); value != "" {
+		c.HTTPTLSCertPath = value
+	}
+
+	if value := os.Getenv(envHTTPTLSKey); value != "" {
+		c.HTTPTLSKeyPath = value
+	}
+
+	if value := os.Getenv(envRPCTLS); value != "" {
+		c.RPCTLS, err = strconv.ParseBool(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if value := os.Getenv(envRPCTLSCA); value != "" {
+		c.RPCTLSCAPath = value
+	}
+
+	if value := os.Getenv(envRPCTLSCert); value != "" {
+		c.RPCTLSCertPath = value
+	}
+
+	if value := os.Getenv(envRPCTLSKey); value != "" {
+		c.RPCTLSKeyPath = value
+	}
+
 	if value := os.Getenv(envConfigPath); value != "" {
 		c.ConfigPath = value
 	}
@@ -202,6 +349,22 @@ func (c *Configuration) parseJSONConfig() {
 		c.SecretKey = *parsedConfig.SecretKey
 	}
 
+	if c.KeyID == "" && parsedConfig.KeyID != nil {
+		c.KeyID = *parsedConfig.KeyID
+	}
+
+	if c.Token == "" && parsedConfig.Token != nil {
+		c.Token = *parsedConfig.Token
+	}
+
+	if c.IP == "" && parsedConfig.IP != nil {
+		c.IP = *parsedConfig.IP
+	}
+
+	if c.Interface == "" && parsedConfig.Interface != nil {
+		c.Interface = *parsedConfig.Interface
+	}
+
 	if c.CryptoKeyPath == "" && parsedConfig.CryptoKey != nil {
 		c.CryptoKeyPath = *parsedConfig.CryptoKey
 	}
@@ -209,6 +372,66 @@ func (c *Configuration) parseJSONConfig() {
 	if c.RateLimit <= 0 && parsedConfig.RateLimit != nil {
 		c.RateLimit = *parsedConfig.RateLimit
 	}
+
+	if !c.RPCStream && parsedConfig.RPCStream != nil {
+		c.RPCStream = *parsedConfig.RPCStream
+	}
+
+	if c.QueueDir == "" && parsedConfig.QueueDir != nil {
+		c.QueueDir = *parsedConfig.QueueDir
+	}
+
+	if c.QueueSize <= 0 && parsedConfig.QueueSize != nil {
+		c.QueueSize = *parsedConfig.QueueSize
+	}
+
+	if c.StatsDAddress == "" && parsedConfig.StatsDAddress != nil {
+		c.StatsDAddress = *parsedConfig.StatsDAddress
+	}
+
+	if !c.HTTPTLS && parsedConfig.HTTPTLS != nil {
+		c.HTTPTLS = *parsedConfig.HTTPTLS
+	}
+
+	if c.HTTPTLSCAPath == "" && parsedConfig.HTTPTLSCA != nil {
+		c.HTTPTLSCAPath = *parsedConfig.HTTPTLSCA
+	}
+
+	if c.HTTPTLSCertPath == "" && parsedConfig.HTTPTLSCert != nil {
+		c.HTTPTLSCertPath = *parsedConfig.HTTPTLSCert
+	}
+
+	if c.HTTPTLSKeyPath == "" && parsedConfig.HTTPTLSKey != nil {
+		c.HTTPTLSKeyPath = *parsedConfig.HTTPTLSKey
+	}
+
+	if !c.RPCTLS && parsedConfig.RPCTLS != nil {
+		c.RPCTLS = *parsedConfig.RPCTLS
+	}
+
+	if c.RPCTLSCAPath == "" && parsedConfig.RPCTLSCA != nil {
+		c.RPCTLSCAPath = *parsedConfig.RPCTLSCA
+	}
+
+	if c.RPCTLSCertPath == "" && parsedConfig.RPCTLSCert != nil {
+		c.RPCTLSCertPath = *parsedConfig.RPCTLSCert
+	}
+
+	if c.RPCTLSKeyPath == "" && parsedConfig.RPCTLSKey != nil {
+		c.RPCTLSKeyPath = *parsedConfig.RPCTLSKey
+	}
+
+	if len(parsedConfig.Collectors) > 0 {
+		c.Collectors = parsedConfig.Collectors
+	}
+
+	if len(c.Labels) == 0 && len(parsedConfig.Labels) > 0 {
+		if err = model.Labels(parsedConfig.Labels).Validate(); err != nil {
+			log.Fatal(err)
+		}
+
+		c.Labels = parsedConfig.Labels
+	}
 }
 
 func stringToDurationInSeconds(s string) (time.Duration, error) {
@@ -233,3 +456,26 @@ func parseHostAndPort(s string) (string, int, error) {
 
 	return values[0], port, nil
 }
+
+func parseLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid label format: %s", pair)
+		}
+
+		labels[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	if err := model.Labels(labels).Validate(); err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
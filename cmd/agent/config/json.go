@@ -4,17 +4,38 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/bjlag/go-metrics/internal/agent/collector"
 )
 
 type jsonConfig struct {
-	AddressHTTP    *address       `json:"address,omitempty"`
-	AddressRPC     *address       `json:"address_rpc,omitempty"`
-	ReportInterval *time.Duration `json:"report_interval,omitempty"`
-	PollInterval   *time.Duration `json:"poll_interval,omitempty"`
-	CryptoKey      *string        `json:"crypto_key,omitempty"`
-	LogLevel       *string        `json:"log_level,omitempty"`
-	SecretKey      *string        `json:"key,omitempty"`
-	RateLimit      *int           `json:"rate_limit,omitempty"`
+	AddressHTTP    *address          `json:"address,omitempty"`
+	AddressRPC     *address          `json:"address_rpc,omitempty"`
+	ReportInterval *time.Duration    `json:"report_interval,omitempty"`
+	PollInterval   *time.Duration    `json:"poll_interval,omitempty"`
+	CryptoKey      *string           `json:"crypto_key,omitempty"`
+	LogLevel       *string           `json:"log_level,omitempty"`
+	SecretKey      *string           `json:"key,omitempty"`
+	KeyID          *string           `json:"key_id,omitempty"`
+	Token          *string           `json:"token,omitempty"`
+	IP             *string           `json:"ip,omitempty"`
+	Interface      *string           `json:"interface,omitempty"`
+	RateLimit      *int              `json:"rate_limit,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	RPCStream      *bool             `json:"rpc_stream,omitempty"`
+	QueueDir       *string           `json:"queue_dir,omitempty"`
+	QueueSize      *int              `json:"queue_size,omitempty"`
+	StatsDAddress  *string           `json:"statsd_address,omitempty"`
+	HTTPTLS        *bool             `json:"http_tls,omitempty"`
+	HTTPTLSCA      *string           `json:"http_tls_ca,omitempty"`
+	HTTPTLSCert    *string           `json:"http_tls_cert,omitempty"`
+	HTTPTLSKey     *string           `json:"http_tls_key,omitempty"`
+	RPCTLS         *bool             `json:"rpc_tls,omitempty"`
+	RPCTLSCA       *string           `json:"rpc_tls_ca,omitempty"`
+	RPCTLSCert     *string           `json:"rpc_tls_cert,omitempty"`
+	RPCTLSKey      *string           `json:"rpc_tls_key,omitempty"`
+
+	Collectors map[string]collector.Config `json:"collectors,omitempty"`
 }
 
 func (c *jsonConfig) UnmarshalJSON(b []byte) error {
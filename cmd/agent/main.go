@@ -5,7 +5,6 @@ import (
 	"fmt"
 	logNativ "log"
 	"os/signal"
-	"runtime"
 	"syscall"
 	"time"
 
@@ -18,9 +17,12 @@ import (
 	"github.com/bjlag/go-metrics/internal/agent/client/rpc"
 	"github.com/bjlag/go-metrics/internal/agent/collector"
 	"github.com/bjlag/go-metrics/internal/agent/limiter"
+	"github.com/bjlag/go-metrics/internal/agent/queue"
+	"github.com/bjlag/go-metrics/internal/agent/statsd"
 	"github.com/bjlag/go-metrics/internal/logger"
 	"github.com/bjlag/go-metrics/internal/securety/crypt"
 	"github.com/bjlag/go-metrics/internal/securety/signature"
+	"github.com/bjlag/go-metrics/internal/securety/tlsconfig"
 )
 
 var (
@@ -62,6 +64,10 @@ func main() {
 	log.Info(fmt.Sprintf("Rate limit is %d", cfg.RateLimit))
 	log.Info(fmt.Sprintf("Public key %s", cfg.CryptoKeyPath))
 	log.Info(fmt.Sprintf("JSON config %s", cfg.ConfigPath))
+	log.Info(fmt.Sprintf("Labels %v", cfg.Labels))
+	log.Info(fmt.Sprintf("RPC stream is %t", cfg.RPCStream))
+	log.Info(fmt.Sprintf("Queue dir '%s'", cfg.QueueDir))
+	log.Info(fmt.Sprintf("StatsD address '%s'", cfg.StatsDAddress))
 
 	if err := run(log, cfg); err != nil {
 		log.WithError(err).Error("Error running agent")
@@ -77,27 +83,88 @@ func run(log logger.Logger, cfg *config.Configuration) error {
 		return err
 	}
 
-	signManager := signature.NewSignManager(cfg.SecretKey)
+	signManager := signature.NewSignManager(cfg.SecretKey, signature.WithKeyID(cfg.KeyID))
 	rateLimiter := limiter.NewRateLimiter(cfg.RateLimit)
-	metricCollector := collector.NewMetricCollector(&runtime.MemStats{})
+
+	agentIP, err := agent.ResolveIP(cfg.IP, cfg.Interface)
+	if err != nil {
+		return err
+	}
+
+	collectors, err := collector.NewDefaultRegistry().Build(cfg.Collectors, cfg.PollInterval)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range collectors {
+		log.Info(fmt.Sprintf("Collector '%s' poll interval is %s", c.Collector.Name(), c.PollInterval))
+	}
 
 	var client agent.Client
 
 	if cfg.AddressRPC != nil {
-		client = rpc.NewSender(cfg.AddressRPC.String(), signManager, log)
+		tlsConfig, err := tlsconfig.NewClient(cfg.RPCTLS, cfg.RPCTLSCAPath, cfg.RPCTLSCertPath, cfg.RPCTLSKeyPath)
+		if err != nil {
+			return err
+		}
+
+		opts := []rpc.Option{rpc.WithEncryption(cryptManager), rpc.WithToken(cfg.Token)}
+		if tlsConfig != nil {
+			opts = append(opts, rpc.WithTLS(tlsConfig))
+		}
+		if cfg.RPCStream {
+			opts = append(opts, rpc.WithStream())
+		}
+		if agentIP != nil {
+			opts = append(opts, rpc.WithIP(agentIP))
+		}
+
+		sender, err := rpc.NewSender(cfg.AddressRPC.String(), signManager, log, opts...)
+		if err != nil {
+			return err
+		}
 		defer func() {
-			_ = client.(*rpc.MetricSender).Close()
+			_ = sender.Close()
 		}()
+
+		client = sender
 	}
 
 	if client == nil && cfg.AddressHTTP != nil {
-		client = http.NewSender(cfg.AddressHTTP.Host, cfg.AddressHTTP.Port, signManager, cryptManager, rateLimiter, log)
+		tlsConfig, err := tlsconfig.NewClient(cfg.HTTPTLS, cfg.HTTPTLSCAPath, cfg.HTTPTLSCertPath, cfg.HTTPTLSKeyPath)
+		if err != nil {
+			return err
+		}
+
+		opts := []http.Option{http.WithToken(cfg.Token)}
+		if tlsConfig != nil {
+			opts = append(opts, http.WithTLS(tlsConfig))
+		}
+		if agentIP != nil {
+			opts = append(opts, http.WithIP(agentIP))
+		}
+
+		client, err = http.NewSender(cfg.AddressHTTP.Host, cfg.AddressHTTP.Port, signManager, cryptManager, rateLimiter, log, opts...)
+		if err != nil {
+			return err
+		}
 	}
 
 	if client == nil {
 		return fmt.Errorf("could not create client")
 	}
 
+	if cfg.QueueDir != "" {
+		sendQueue, err := queue.New(cfg.QueueDir, cfg.QueueSize)
+		if err != nil {
+			return err
+		}
+
+		log.WithField("depth", sendQueue.Len()).Info("Queue opened")
+
+		client = queue.NewClient(client, sendQueue, log)
+	}
+
 	pollTicker := time.NewTicker(cfg.PollInterval)
 	defer pollTicker.Stop()
 
@@ -106,20 +173,33 @@ func run(log logger.Logger, cfg *config.Configuration) error {
 
 	g, gCtx := errgroup.WithContext(ctx)
 
+	collectorRunner := collector.NewRunner(collectors, log)
+	g.Go(func() error {
+		collectorRunner.Start(gCtx)
+		log.Info("Stopped collectors")
+		return nil
+	})
+
+	statsdAggregator := statsd.NewAggregator()
+	if cfg.StatsDAddress != "" {
+		statsdServer := statsd.NewServer(cfg.StatsDAddress, statsdAggregator, log)
+		g.Go(func() error {
+			return statsdServer.Start(gCtx)
+		})
+	}
+
 	g.Go(func() error {
 		for {
 			select {
 			case <-gCtx.Done():
-				log.Info("Stopped read stats")
+				log.Info("Stopped poll count")
 				return nil
 			case <-pollTicker.C:
-				metricCollector.ReadStats()
-
 				metrics := []*collector.Metric{
 					collector.NewCounterMetric("PollCount", 1),
 				}
 
-				if err := client.Send(metrics); err != nil {
+				if err := client.Send(withLabels(metrics, cfg.Labels)); err != nil {
 					log.WithError(err).Error("Error in sending poll count")
 				}
 			}
@@ -133,16 +213,12 @@ func run(log logger.Logger, cfg *config.Configuration) error {
 				log.Info("Stopped send metrics")
 				return nil
 			case <-reportTicker.C:
-				metrics, err := metricCollector.Collect()
-				if err != nil {
-					log.WithError(err).Error("Error in getting metrics")
-				}
-
+				metrics := append(collectorRunner.Snapshot(), statsdAggregator.Flush()...)
 				if len(metrics) == 0 {
 					continue
 				}
 
-				if err := client.Send(metrics); err != nil {
+				if err := client.Send(withLabels(metrics, cfg.Labels)); err != nil {
 					log.WithError(err).Error("Error in sending report")
 				}
 			}
@@ -157,3 +233,12 @@ func run(log logger.Logger, cfg *config.Configuration) error {
 
 	return nil
 }
+
+// withLabels добавляет ко всем метрикам метки из конфигурации агента.
+func withLabels(metrics []*collector.Metric, labels map[string]string) []*collector.Metric {
+	for _, m := range metrics {
+		m.WithLabels(labels)
+	}
+
+	return metrics
+}
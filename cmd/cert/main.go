@@ -5,11 +5,42 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"flag"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bjlag/go-metrics/internal/securety/tlsconfig"
 )
 
 func main() {
+	out := flag.String("out", ".", "Directory to write files to")
+	withRSA := flag.Bool("rsa", true, "Generate RSA key pair for body encryption, with -tls only if set explicitly")
+	withTLS := flag.Bool("tls", false, "Generate CA, server and client TLS certificates")
+	hosts := flag.String("hosts", "localhost,127.0.0.1", "Comma separated host names and IPs of server TLS certificate")
+	days := flag.Int("days", 365, "TLS certificates validity in days")
+	flag.Parse()
+
+	// С -tls ключи шифрования не перезаписываются, если их генерация не запрошена явно.
+	if *withTLS && !isFlagSet("rsa") {
+		*withRSA = false
+	}
+
+	if *withRSA {
+		writeRSA(*out)
+	}
+
+	if *withTLS {
+		if err := writeTLS(*out, splitHosts(*hosts), time.Duration(*days)*24*time.Hour); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// writeRSA создает пару ключей RSA для шифрования тела запросов: public.pem и private.pem.
+func writeRSA(out string) {
 	// создаём новый приватный RSA-ключ длиной 4096 бит
 	privateKey, err := rsa.GenerateKey(rand.Reader, 4096)
 	if err != nil {
@@ -30,13 +61,76 @@ func main() {
 		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
 	})
 
-	err = os.WriteFile("public.pem", publicKeyPEM, 0644)
+	err = os.WriteFile(filepath.Join(out, "public.pem"), publicKeyPEM, 0644)
 	if err != nil {
 		panic(err)
 	}
 
-	err = os.WriteFile("private.pem", privateKeyPEM, 0644)
+	err = os.WriteFile(filepath.Join(out, "private.pem"), privateKeyPEM, 0600)
 	if err != nil {
 		panic(err)
 	}
 }
+
+// writeTLS создает CA и подписанные им сертификаты сервера и клиента:
+// ca.crt, ca.key, server.crt, server.key, client.crt и client.key.
+func writeTLS(out string, hosts []string, validFor time.Duration) error {
+	ca, err := tlsconfig.NewCA("go-metrics CA", validFor)
+	if err != nil {
+		return err
+	}
+
+	server, err := ca.Issue("go-metrics server", hosts, tlsconfig.UsageServer, validFor)
+	if err != nil {
+		return err
+	}
+
+	client, err := ca.Issue("go-metrics agent", nil, tlsconfig.UsageClient, validFor)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		name string
+		data []byte
+		perm os.FileMode
+	}{
+		{"ca.crt", ca.CertPEM, 0644},
+		{"ca.key", ca.KeyPEM, 0600},
+		{"server.crt", server.CertPEM, 0644},
+		{"server.key", server.KeyPEM, 0600},
+		{"client.crt", client.CertPEM, 0644},
+		{"client.key", client.KeyPEM, 0600},
+	}
+
+	for _, f := range files {
+		if err = os.WriteFile(filepath.Join(out, f.name), f.data, f.perm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitHosts разбирает список хостов через запятую, пропуская пробелы и пустые значения.
+func splitHosts(s string) []string {
+	var hosts []string
+	for _, host := range strings.Split(s, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts
+}
+
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/bjlag/go-metrics/cmd/server/config"
+	"github.com/bjlag/go-metrics/internal/alert"
+)
+
+func newAlertRules(cfgRules []config.AlertRule) ([]*alert.Rule, error) {
+	rules := make([]*alert.Rule, 0, len(cfgRules))
+	names := make(map[string]struct{}, len(cfgRules))
+
+	for _, r := range cfgRules {
+		if _, ok := names[r.Name]; ok {
+			return nil, fmt.Errorf("duplicate alert rule name '%s'", r.Name)
+		}
+		names[r.Name] = struct{}{}
+
+		rule, err := alert.NewRule(r.Name, r.Expr, r.For)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
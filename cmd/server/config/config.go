@@ -10,6 +10,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/bjlag/go-metrics/internal/securety/ipfilter"
 )
 
 type address struct {
@@ -33,31 +35,76 @@ func (o *address) Set(value string) error {
 }
 
 const (
-	envAddress         = "ADDRESS"
-	envAddressRPC      = "ADDRESS_RPC"
-	envDatabaseDSN     = "DATABASE_DSN"
-	envLogLevel        = "LOG_LEVEL"
-	envStoreInterval   = "STORE_INTERVAL"
-	envFileStoragePath = "FILE_STORAGE_PATH"
-	envRestore         = "RESTORE"
-	envSecretKey       = "KEY"
-	envCryptoKey       = "CRYPTO_KEY"
-	envConfigPath      = "CONFIG"
-	envTrustedSubnet   = "TRUSTED_SUBNET"
+	envAddress          = "ADDRESS"
+	envAddressRPC       = "ADDRESS_RPC"
+	envDatabaseDSN      = "DATABASE_DSN"
+	envLogLevel         = "LOG_LEVEL"
+	envStoreInterval    = "STORE_INTERVAL"
+	envFileStoragePath  = "FILE_STORAGE_PATH"
+	envRestore          = "RESTORE"
+	envSecretKey        = "KEY"
+	envCryptoKey        = "CRYPTO_KEY"
+	envConfigPath       = "CONFIG"
+	envTrustedSubnet    = "TRUSTED_SUBNET"
+	envDeniedSubnets    = "DENIED_SUBNETS"
+	envTrustedProxies   = "TRUSTED_PROXIES"
+	envAlertInterval    = "ALERT_INTERVAL"
+	envHistoryRetention = "HISTORY_RETENTION"
+	envHistorySize      = "HISTORY_SIZE"
+	envKeysFile         = "KEYS_FILE"
+	envTokensFile       = "TOKENS_FILE"
+	envReplayWindow     = "REPLAY_WINDOW"
+	envAllowUnstamped   = "ALLOW_UNSTAMPED"
+	envHTTPTLSCert      = "HTTP_TLS_CERT"
+	envHTTPTLSKey       = "HTTP_TLS_KEY"
+	envHTTPTLSClientCA  = "HTTP_TLS_CLIENT_CA"
+	envRPCTLSCert       = "RPC_TLS_CERT"
+	envRPCTLSKey        = "RPC_TLS_KEY"
+	envRPCTLSClientCA   = "RPC_TLS_CLIENT_CA"
 )
 
+const (
+	defaultAlertInterval    = 10 * time.Second
+	defaultHistoryRetention = time.Hour
+	defaultHistorySize      = 3600
+	defaultReplayWindow     = 5 * time.Minute
+)
+
+// AlertRule описывает правило алертинга из конфигурации.
+type AlertRule struct {
+	Name string
+	Expr string
+	For  time.Duration
+}
+
 type Configuration struct {
-	LogLevel        string
-	AddressHTTP     *address
-	AddressRPC      *address
-	DatabaseDSN     string
-	StoreInterval   time.Duration
-	FileStoragePath string
-	Restore         bool
-	SecretKey       string
-	CryptoKeyPath   string
-	ConfigPath      string
-	TrustedSubnet   *net.IPNet
+	LogLevel         string
+	AddressHTTP      *address
+	AddressRPC       *address
+	DatabaseDSN      string
+	StoreInterval    time.Duration
+	FileStoragePath  string
+	Restore          bool
+	SecretKey        string
+	CryptoKeyPath    string
+	ConfigPath       string
+	TrustedSubnets   []*net.IPNet
+	DeniedSubnets    []*net.IPNet
+	TrustedProxies   []*net.IPNet
+	AlertInterval    time.Duration
+	AlertRules       []AlertRule
+	HistoryRetention time.Duration
+	HistorySize      int
+	KeysFile         string
+	TokensFile       string
+	ReplayWindow     time.Duration
+	AllowUnstamped   bool
+	HTTPTLSCertPath  string
+	HTTPTLSKeyPath   string
+	HTTPTLSClientCA  string
+	RPCTLSCertPath   string
+	RPCTLSKeyPath    string
+	RPCTLSClientCA   string
 }
 
 func LoadConfig() *Configuration {
@@ -73,6 +120,23 @@ func LoadConfig() *Configuration {
 	c.parseEnvs()
 	c.parseJSONConfig()
 
+	if c.AlertInterval <= 0 {
+		c.AlertInterval = defaultAlertInterval
+	}
+
+	if c.HistoryRetention <= 0 {
+		c.HistoryRetention = defaultHistoryRetention
+	}
+
+	if c.HistorySize <= 0 {
+		c.HistorySize = defaultHistorySize
+	}
+
+	// Подписанные запросы без защиты от повторов можно перехватить и отправить снова.
+	if c.ReplayWindow <= 0 && (c.SecretKey != "" || c.KeysFile != "") {
+		c.ReplayWindow = defaultReplayWindow
+	}
+
 	return c
 }
 
@@ -103,17 +167,73 @@ func (c *Configuration) parseFlags() {
 	flag.StringVar(&c.ConfigPath, "c", "", "Path to config JSON file")
 	flag.StringVar(&c.ConfigPath, "config", "", "Path to config JSON file")
 
-	flag.Func("t", "Trusted subnet: 192.168.1.0/24", func(s string) error {
+	flag.Func("t", "Trusted subnets separated by comma: 192.168.1.0/24,fd00::/8", func(s string) error {
+		var err error
+
+		c.TrustedSubnets, err = ipfilter.ParseCIDRs(s)
+		return err
+	})
+
+	flag.Func("denied-subnets", "Denied subnets separated by comma, take precedence over trusted subnets", func(s string) error {
+		var err error
+
+		c.DeniedSubnets, err = ipfilter.ParseCIDRs(s)
+		return err
+	})
+
+	flag.Func("trusted-proxies", "Trusted proxy subnets separated by comma, only they may set X-Forwarded-For and X-Real-IP", func(s string) error {
+		var err error
+
+		c.TrustedProxies, err = ipfilter.ParseCIDRs(s)
+		return err
+	})
+
+	flag.Func("alert-interval", "Alert rules evaluation interval in seconds", func(s string) error {
+		var err error
+
+		c.AlertInterval, err = stringToDurationInSeconds(s)
+		if err != nil {
+			return fmt.Errorf("parse alert interval error: %w", err)
+		}
+
+		return nil
+	})
+
+	flag.Func("history-retention", "Metric history retention in seconds", func(s string) error {
 		var err error
 
-		_, c.TrustedSubnet, err = net.ParseCIDR(s)
+		c.HistoryRetention, err = stringToDurationInSeconds(s)
 		if err != nil {
-			return fmt.Errorf("parse CIDR error: %w", err)
+			return fmt.Errorf("parse history retention error: %w", err)
 		}
 
 		return nil
 	})
 
+	flag.IntVar(&c.HistorySize, "history-size", 0, "Max number of samples kept in memory per metric")
+	flag.StringVar(&c.KeysFile, "keys-file", "", "Path to JSON file with per-agent signing keys, reloaded on SIGHUP")
+	flag.StringVar(&c.TokensFile, "tokens-file", "", "Path to JSON file with API access tokens and roles, reloaded on SIGHUP")
+
+	flag.Func("replay-window", "Max age in seconds of signed requests, 300 by default when signing key is set", func(s string) error {
+		var err error
+
+		c.ReplayWindow, err = stringToDurationInSeconds(s)
+		if err != nil {
+			return fmt.Errorf("parse replay window error: %w", err)
+		}
+
+		return nil
+	})
+
+	flag.BoolVar(&c.AllowUnstamped, "allow-unstamped", false, "Accept signed requests without timestamp and nonce from agents of previous versions")
+
+	flag.StringVar(&c.HTTPTLSCertPath, "http-tls-cert", "", "Path to HTTP server TLS certificate, empty disables HTTPS")
+	flag.StringVar(&c.HTTPTLSKeyPath, "http-tls-key", "", "Path to HTTP server TLS private key")
+	flag.StringVar(&c.HTTPTLSClientCA, "http-tls-client-ca", "", "Path to CA certificates to verify HTTP client certificates, enables mTLS")
+	flag.StringVar(&c.RPCTLSCertPath, "rpc-tls-cert", "", "Path to RPC server TLS certificate, empty disables TLS")
+	flag.StringVar(&c.RPCTLSKeyPath, "rpc-tls-key", "", "Path to RPC server TLS private key")
+	flag.StringVar(&c.RPCTLSClientCA, "rpc-tls-client-ca", "", "Path to CA certificates to verify RPC client certificates, enables mTLS")
+
 	flag.Parse()
 }
 
@@ -176,11 +296,106 @@ func (c *Configuration) parseEnvs() {
 	if value := os.Getenv(envTrustedSubnet); value != "" {
 		var err error
 
-		_, c.TrustedSubnet, err = net.ParseCIDR(value)
+		c.TrustedSubnets, err = ipfilter.ParseCIDRs(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if value := os.Getenv(envDeniedSubnets); value != "" {
+		var err error
+
+		c.DeniedSubnets, err = ipfilter.ParseCIDRs(value)
 		if err != nil {
 			log.Fatal(err)
 		}
 	}
+
+	if value := os.Getenv(envTrustedProxies); value != "" {
+		var err error
+
+		c.TrustedProxies, err = ipfilter.ParseCIDRs(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if value := os.Getenv(envAlertInterval); value != "" {
+		var err error
+
+		c.AlertInterval, err = stringToDurationInSeconds(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if value := os.Getenv(envHistoryRetention); value != "" {
+		var err error
+
+		c.HistoryRetention, err = stringToDurationInSeconds(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if value := os.Getenv(envHistorySize); value != "" {
+		var err error
+
+		c.HistorySize, err = strconv.Atoi(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if value := os.Getenv(envKeysFile); value != "" {
+		c.KeysFile = value
+	}
+
+	if value := os.Getenv(envTokensFile); value != "" {
+		c.TokensFile = value
+	}
+
+	if value := os.Getenv(envReplayWindow); value != "" {
+		var err error
+
+		c.ReplayWindow, err = stringToDurationInSeconds(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if value := os.Getenv(envAllowUnstamped); value != "" {
+		var err error
+
+		c.AllowUnstamped, err = strconv.ParseBool(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if value := os.Getenv(envHTTPTLSCert); value != "" {
+		c.HTTPTLSCertPath = value
+	}
+
+	if value := os.Getenv(envHTTPTLSKey); value != "" {
+		c.HTTPTLSKeyPath = value
+	}
+
+	if value := os.Getenv(envHTTPTLSClientCA); value != "" {
+		c.HTTPTLSClientCA = value
+	}
+
+	if value := os.Getenv(envRPCTLSCert); value != "" {
+		c.RPCTLSCertPath = value
+	}
+
+	if value := os.Getenv(envRPCTLSKey); value != "" {
+		c.RPCTLSKeyPath = value
+	}
+
+	if value := os.Getenv(envRPCTLSClientCA); value != "" {
+		c.RPCTLSClientCA = value
+	}
 }
 
 func (c *Configuration) parseJSONConfig() {
@@ -238,8 +453,72 @@ func (c *Configuration) parseJSONConfig() {
 		c.CryptoKeyPath = *parsedConfig.CryptoKey
 	}
 
-	if c.TrustedSubnet == nil && parsedConfig.TrustedSubnet != nil {
-		c.TrustedSubnet = parsedConfig.TrustedSubnet
+	if len(c.TrustedSubnets) == 0 && len(parsedConfig.TrustedSubnets) > 0 {
+		c.TrustedSubnets = parsedConfig.TrustedSubnets
+	}
+
+	if len(c.DeniedSubnets) == 0 && len(parsedConfig.DeniedSubnets) > 0 {
+		c.DeniedSubnets = parsedConfig.DeniedSubnets
+	}
+
+	if len(c.TrustedProxies) == 0 && len(parsedConfig.TrustedProxies) > 0 {
+		c.TrustedProxies = parsedConfig.TrustedProxies
+	}
+
+	if c.AlertInterval <= 0 && parsedConfig.AlertInterval != nil {
+		c.AlertInterval = *parsedConfig.AlertInterval
+	}
+
+	if len(c.AlertRules) == 0 {
+		c.AlertRules = parsedConfig.AlertRules
+	}
+
+	if c.HistoryRetention <= 0 && parsedConfig.HistoryRetention != nil {
+		c.HistoryRetention = *parsedConfig.HistoryRetention
+	}
+
+	if c.HistorySize <= 0 && parsedConfig.HistorySize != nil {
+		c.HistorySize = *parsedConfig.HistorySize
+	}
+
+	if c.KeysFile == "" && parsedConfig.KeysFile != nil {
+		c.KeysFile = *parsedConfig.KeysFile
+	}
+
+	if c.TokensFile == "" && parsedConfig.TokensFile != nil {
+		c.TokensFile = *parsedConfig.TokensFile
+	}
+
+	if c.ReplayWindow <= 0 && parsedConfig.ReplayWindow != nil {
+		c.ReplayWindow = *parsedConfig.ReplayWindow
+	}
+
+	if !c.AllowUnstamped && parsedConfig.AllowUnstamped != nil {
+		c.AllowUnstamped = *parsedConfig.AllowUnstamped
+	}
+
+	if c.HTTPTLSCertPath == "" && parsedConfig.HTTPTLSCert != nil {
+		c.HTTPTLSCertPath = *parsedConfig.HTTPTLSCert
+	}
+
+	if c.HTTPTLSKeyPath == "" && parsedConfig.HTTPTLSKey != nil {
+		c.HTTPTLSKeyPath = *parsedConfig.HTTPTLSKey
+	}
+
+	if c.HTTPTLSClientCA == "" && parsedConfig.HTTPTLSClientCA != nil {
+		c.HTTPTLSClientCA = *parsedConfig.HTTPTLSClientCA
+	}
+
+	if c.RPCTLSCertPath == "" && parsedConfig.RPCTLSCert != nil {
+		c.RPCTLSCertPath = *parsedConfig.RPCTLSCert
+	}
+
+	if c.RPCTLSKeyPath == "" && parsedConfig.RPCTLSKey != nil {
+		c.RPCTLSKeyPath = *parsedConfig.RPCTLSKey
+	}
+
+	if c.RPCTLSClientCA == "" && parsedConfig.RPCTLSClientCA != nil {
+		c.RPCTLSClientCA = *parsedConfig.RPCTLSClientCA
 	}
 }
 
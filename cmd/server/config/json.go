@@ -4,21 +4,40 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"strings"
 	"time"
+
+	"github.com/bjlag/go-metrics/internal/securety/ipfilter"
 )
 
 type jsonConfig struct {
-	AddressHTTP     *address       `json:"address,omitempty"`
-	AddressRPC      *address       `json:"address_rpc,omitempty"`
-	Restore         *bool          `json:"restore,omitempty"`
-	StoreInterval   *time.Duration `json:"store_interval,omitempty"`
-	StoreFile       *string        `json:"store_file,omitempty"`
-	DatabaseDSN     *string        `json:"database_dsn,omitempty"`
-	CryptoKey       *string        `json:"crypto_key,omitempty"`
-	LogLevel        *string        `json:"log_level,omitempty"`
-	FileStoragePath *string        `json:"file_storage_path,omitempty"`
-	SecretKey       *string        `json:"key,omitempty"`
-	TrustedSubnet   *net.IPNet     `json:"trusted_subnet,omitempty"`
+	AddressHTTP      *address       `json:"address,omitempty"`
+	AddressRPC       *address       `json:"address_rpc,omitempty"`
+	Restore          *bool          `json:"restore,omitempty"`
+	StoreInterval    *time.Duration `json:"store_interval,omitempty"`
+	StoreFile        *string        `json:"store_file,omitempty"`
+	DatabaseDSN      *string        `json:"database_dsn,omitempty"`
+	CryptoKey        *string        `json:"crypto_key,omitempty"`
+	LogLevel         *string        `json:"log_level,omitempty"`
+	FileStoragePath  *string        `json:"file_storage_path,omitempty"`
+	SecretKey        *string        `json:"key,omitempty"`
+	TrustedSubnets   []*net.IPNet   `json:"-"`
+	DeniedSubnets    []*net.IPNet   `json:"-"`
+	TrustedProxies   []*net.IPNet   `json:"-"`
+	AlertInterval    *time.Duration `json:"alert_interval,omitempty"`
+	AlertRules       []AlertRule    `json:"alert_rules,omitempty"`
+	HistoryRetention *time.Duration `json:"history_retention,omitempty"`
+	HistorySize      *int           `json:"history_size,omitempty"`
+	KeysFile         *string        `json:"keys_file,omitempty"`
+	TokensFile       *string        `json:"tokens_file,omitempty"`
+	ReplayWindow     *time.Duration `json:"replay_window,omitempty"`
+	AllowUnstamped   *bool          `json:"allow_unstamped,omitempty"`
+	HTTPTLSCert      *string        `json:"http_tls_cert,omitempty"`
+	HTTPTLSKey       *string        `json:"http_tls_key,omitempty"`
+	HTTPTLSClientCA  *string        `json:"http_tls_client_ca,omitempty"`
+	RPCTLSCert       *string        `json:"rpc_tls_cert,omitempty"`
+	RPCTLSKey        *string        `json:"rpc_tls_key,omitempty"`
+	RPCTLSClientCA   *string        `json:"rpc_tls_client_ca,omitempty"`
 }
 
 func (c *jsonConfig) UnmarshalJSON(b []byte) error {
@@ -26,10 +45,20 @@ func (c *jsonConfig) UnmarshalJSON(b []byte) error {
 
 	aliasValue := &struct {
 		*alias
-		AddressHTTP   *string `json:"address,omitempty"`
-		AddressRPC    *string `json:"address_rpc,omitempty"`
-		StoreInterval *string `json:"store_interval,omitempty"`
-		TrustedSubnet *string `json:"trusted_subnet,omitempty"`
+		AddressHTTP      *string         `json:"address,omitempty"`
+		AddressRPC       *string         `json:"address_rpc,omitempty"`
+		StoreInterval    *string         `json:"store_interval,omitempty"`
+		TrustedSubnet    json.RawMessage `json:"trusted_subnet,omitempty"`
+		DeniedSubnets    []string        `json:"denied_subnets,omitempty"`
+		TrustedProxies   []string        `json:"trusted_proxies,omitempty"`
+		AlertInterval    *string         `json:"alert_interval,omitempty"`
+		HistoryRetention *string         `json:"history_retention,omitempty"`
+		ReplayWindow     *string         `json:"replay_window,omitempty"`
+		AlertRules       []struct {
+			Name string  `json:"name"`
+			Expr string  `json:"expr"`
+			For  *string `json:"for,omitempty"`
+		} `json:"alert_rules,omitempty"`
 	}{
 		alias: (*alias)(c),
 	}
@@ -66,14 +95,109 @@ func (c *jsonConfig) UnmarshalJSON(b []byte) error {
 		c.StoreInterval = &interval
 	}
 
-	if aliasValue.TrustedSubnet != nil && *aliasValue.TrustedSubnet != "" {
-		_, ipNet, err := net.ParseCIDR(*aliasValue.TrustedSubnet)
+	c.TrustedSubnets, err = parseTrustedSubnet(aliasValue.TrustedSubnet)
+	if err != nil {
+		return fmt.Errorf("parse trusted_subnet error: %w", err)
+	}
+
+	c.DeniedSubnets, err = parseSubnets(aliasValue.DeniedSubnets)
+	if err != nil {
+		return fmt.Errorf("parse denied_subnets error: %w", err)
+	}
+
+	c.TrustedProxies, err = parseSubnets(aliasValue.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("parse trusted_proxies error: %w", err)
+	}
+
+	if aliasValue.AlertInterval != nil && *aliasValue.AlertInterval != "" {
+		interval, err := time.ParseDuration(*aliasValue.AlertInterval)
+		if err != nil {
+			return fmt.Errorf("parse alert_interval error: %w", err)
+		}
+
+		c.AlertInterval = &interval
+	}
+
+	if aliasValue.HistoryRetention != nil && *aliasValue.HistoryRetention != "" {
+		retention, err := time.ParseDuration(*aliasValue.HistoryRetention)
+		if err != nil {
+			return fmt.Errorf("parse history_retention error: %w", err)
+		}
+
+		c.HistoryRetention = &retention
+	}
+
+	if aliasValue.ReplayWindow != nil && *aliasValue.ReplayWindow != "" {
+		window, err := time.ParseDuration(*aliasValue.ReplayWindow)
 		if err != nil {
-			return fmt.Errorf("parse CIDR error: %w", err)
+			return fmt.Errorf("parse replay_window error: %w", err)
 		}
 
-		c.TrustedSubnet = ipNet
+		c.ReplayWindow = &window
+	}
+
+	for _, rule := range aliasValue.AlertRules {
+		r := AlertRule{
+			Name: rule.Name,
+			Expr: rule.Expr,
+		}
+
+		if rule.For != nil && *rule.For != "" {
+			holdFor, err := time.ParseDuration(*rule.For)
+			if err != nil {
+				return fmt.Errorf("parse alert rule '%s' for error: %w", rule.Name, err)
+			}
+
+			r.For = holdFor
+		}
+
+		c.AlertRules = append(c.AlertRules, r)
 	}
 
 	return nil
 }
+
+// parseTrustedSubnet разбирает trusted_subnet. Как и остальные списки подсетей, он задается массивом,
+// а строка с подсетями через запятую принимается для совместимости с конфигурациями предыдущих версий.
+func parseTrustedSubnet(raw json.RawMessage) ([]*net.IPNet, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	if raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, err
+		}
+
+		return ipfilter.ParseCIDRs(s)
+	}
+
+	var values []string
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+
+	return parseSubnets(values)
+}
+
+// parseSubnets разбирает массив подсетей, каждый элемент которого содержит одну подсеть или один адрес.
+func parseSubnets(values []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+
+	for _, value := range values {
+		if strings.Contains(value, ",") {
+			return nil, fmt.Errorf("'%s' must contain exactly one subnet", value)
+		}
+
+		parsed, err := ipfilter.ParseCIDRs(value)
+		if err != nil {
+			return nil, err
+		}
+
+		nets = append(nets, parsed...)
+	}
+
+	return nets, nil
+}
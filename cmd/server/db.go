@@ -40,22 +40,72 @@ func initDB(dsn string, log logger.Logger) *sqlx.DB {
 func initSchema(db *sqlx.DB) error {
 	var schema = `
 		CREATE TABLE IF NOT EXISTS gauge_metrics (
-		    id varchar(100) PRIMARY KEY NOT NULL,
+		    id varchar(100) NOT NULL,
+		    labels text NOT NULL DEFAULT '',
 		    value double precision NOT NULL
 		);
 		
+		ALTER TABLE gauge_metrics ADD COLUMN IF NOT EXISTS labels text NOT NULL DEFAULT '';
+		ALTER TABLE gauge_metrics DROP CONSTRAINT IF EXISTS gauge_metrics_pkey;
+		CREATE UNIQUE INDEX IF NOT EXISTS gauge_metrics_series_idx ON gauge_metrics (id, labels);
+		
 		COMMENT ON TABLE gauge_metrics IS 'Метрики типа gauge';
 		COMMENT ON COLUMN gauge_metrics.id IS 'ID метрики';
+		COMMENT ON COLUMN gauge_metrics.labels IS 'Метки метрики в каноническом виде';
 		COMMENT ON COLUMN gauge_metrics.value IS 'Значение метрики';
 		
 		CREATE TABLE IF NOT EXISTS counter_metrics (
-		    id varchar(100) PRIMARY KEY NOT NULL,
+		    id varchar(100) NOT NULL,
+		    labels text NOT NULL DEFAULT '',
 		    value bigint NOT NULL
 		);
 		
+		ALTER TABLE counter_metrics ADD COLUMN IF NOT EXISTS labels text NOT NULL DEFAULT '';
+		ALTER TABLE counter_metrics DROP CONSTRAINT IF EXISTS counter_metrics_pkey;
+		CREATE UNIQUE INDEX IF NOT EXISTS counter_metrics_series_idx ON counter_metrics (id, labels);
+		
 		COMMENT ON TABLE counter_metrics IS 'Метрики типа counter';
 		COMMENT ON COLUMN counter_metrics.id IS 'ID метрики';
+		COMMENT ON COLUMN counter_metrics.labels IS 'Метки метрики в каноническом виде';
 		COMMENT ON COLUMN counter_metrics.value IS 'Значение метрики';
+		
+		CREATE TABLE IF NOT EXISTS histogram_metrics (
+		    id varchar(100) NOT NULL,
+		    labels text NOT NULL DEFAULT '',
+		    bounds double precision[] NOT NULL,
+		    counts bigint[] NOT NULL,
+		    sum double precision NOT NULL,
+		    count bigint NOT NULL
+		);
+		
+		CREATE UNIQUE INDEX IF NOT EXISTS histogram_metrics_series_idx ON histogram_metrics (id, labels);
+		
+		COMMENT ON TABLE histogram_metrics IS 'Метрики типа histogram';
+		COMMENT ON COLUMN histogram_metrics.id IS 'ID метрики';
+		COMMENT ON COLUMN histogram_metrics.labels IS 'Метки метрики в каноническом виде';
+		COMMENT ON COLUMN histogram_metrics.bounds IS 'Верхние границы бакетов';
+		COMMENT ON COLUMN histogram_metrics.counts IS 'Количество наблюдений в бакетах, последний бакет +Inf';
+		COMMENT ON COLUMN histogram_metrics.sum IS 'Сумма наблюдений';
+		COMMENT ON COLUMN histogram_metrics.count IS 'Количество наблюдений';
+		
+		CREATE TABLE IF NOT EXISTS metric_samples (
+		    kind varchar(20) NOT NULL,
+		    id varchar(100) NOT NULL,
+		    labels text NOT NULL DEFAULT '',
+		    ts timestamptz NOT NULL DEFAULT now(),
+		    value double precision NOT NULL
+		);
+		
+		ALTER TABLE metric_samples ADD COLUMN IF NOT EXISTS labels text NOT NULL DEFAULT '';
+		DROP INDEX IF EXISTS metric_samples_series_idx;
+		CREATE INDEX IF NOT EXISTS metric_samples_labels_series_idx ON metric_samples (kind, id, labels, ts);
+		
+		COMMENT ON TABLE metric_samples IS 'История значений метрик';
+		COMMENT ON COLUMN metric_samples.kind IS 'Тип метрики';
+		COMMENT ON COLUMN metric_samples.id IS 'ID метрики';
+		COMMENT ON COLUMN metric_samples.labels IS 'Метки метрики в каноническом виде';
+		COMMENT ON COLUMN metric_samples.ts IS 'Время записи значения';
+		COMMENT ON COLUMN metric_samples.value IS 'Значение метрики, для counter накопленное';
 	`
 
 	_, err := db.Exec(schema)
@@ -2,8 +2,8 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
-	"net"
 	"net/http"
 	"net/http/pprof"
 	"time"
@@ -14,11 +14,17 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/bjlag/go-metrics/internal/alert"
 	"github.com/bjlag/go-metrics/internal/backup"
 	asyncBackup "github.com/bjlag/go-metrics/internal/backup/async"
 	syncBackup "github.com/bjlag/go-metrics/internal/backup/sync"
+	"github.com/bjlag/go-metrics/internal/http/handler/alerts"
+	deleteBatch "github.com/bjlag/go-metrics/internal/http/handler/delete/batch"
+	deleteMetric "github.com/bjlag/go-metrics/internal/http/handler/delete/metric"
 	"github.com/bjlag/go-metrics/internal/http/handler/list"
 	"github.com/bjlag/go-metrics/internal/http/handler/ping"
+	"github.com/bjlag/go-metrics/internal/http/handler/prometheus"
+	queryHandler "github.com/bjlag/go-metrics/internal/http/handler/query"
 	updateBatch "github.com/bjlag/go-metrics/internal/http/handler/update/batch"
 	updateCounter "github.com/bjlag/go-metrics/internal/http/handler/update/counter"
 	updateGauge "github.com/bjlag/go-metrics/internal/http/handler/update/gauge"
@@ -30,8 +36,11 @@ import (
 	valueUnknown "github.com/bjlag/go-metrics/internal/http/handler/value/unknown"
 	middleware2 "github.com/bjlag/go-metrics/internal/http/middleware"
 	"github.com/bjlag/go-metrics/internal/logger"
+	"github.com/bjlag/go-metrics/internal/query"
 	"github.com/bjlag/go-metrics/internal/renderer"
+	"github.com/bjlag/go-metrics/internal/securety/auth"
 	"github.com/bjlag/go-metrics/internal/securety/crypt"
+	"github.com/bjlag/go-metrics/internal/securety/ipfilter"
 	"github.com/bjlag/go-metrics/internal/securety/signature"
 	"github.com/bjlag/go-metrics/internal/storage"
 )
@@ -41,15 +50,19 @@ const (
 )
 
 type Server struct {
-	addr          string
-	htmlRenderer  *renderer.HTMLRenderer
-	repo          storage.Repository
-	db            *sqlx.DB
-	backup        backup.Creator
-	singManager   *signature.SignManager
-	cryptManager  *crypt.DecryptManager
-	trustedSubnet *net.IPNet
-	log           logger.Logger
+	addr         string
+	htmlRenderer *renderer.HTMLRenderer
+	repo         storage.Repository
+	db           *sqlx.DB
+	backup       backup.Creator
+	alertEngine  *alert.Engine
+	queryEngine  *query.Engine
+	singManager  *signature.SignManager
+	cryptManager *crypt.DecryptManager
+	tokens       *auth.TokenStore
+	ipFilter     *ipfilter.Filter
+	tlsConfig    *tls.Config
+	log          logger.Logger
 }
 
 func NewServer(
@@ -58,35 +71,49 @@ func NewServer(
 	repo storage.Repository,
 	db *sqlx.DB,
 	backup backup.Creator,
+	alertEngine *alert.Engine,
+	queryEngine *query.Engine,
 	singManager *signature.SignManager,
 	cryptManager *crypt.DecryptManager,
-	trustedSubnet *net.IPNet,
+	tokens *auth.TokenStore,
+	ipFilter *ipfilter.Filter,
+	tlsConfig *tls.Config,
 	log logger.Logger,
 ) *Server {
 	return &Server{
-		addr:          addr,
-		htmlRenderer:  htmlRenderer,
-		repo:          repo,
-		db:            db,
-		backup:        backup,
-		singManager:   singManager,
-		cryptManager:  cryptManager,
-		trustedSubnet: trustedSubnet,
-		log:           log,
+		addr:         addr,
+		htmlRenderer: htmlRenderer,
+		repo:         repo,
+		db:           db,
+		backup:       backup,
+		alertEngine:  alertEngine,
+		queryEngine:  queryEngine,
+		singManager:  singManager,
+		cryptManager: cryptManager,
+		tokens:       tokens,
+		ipFilter:     ipFilter,
+		tlsConfig:    tlsConfig,
+		log:          log,
 	}
 }
 
 func (s *Server) Start(ctx context.Context) error {
 	httpServer := &http.Server{
-		Addr:    s.addr,
-		Handler: s.router(),
+		Addr:      s.addr,
+		Handler:   s.router(),
+		TLSConfig: s.tlsConfig,
 	}
 
-	s.log.Info("Starting HTTP server")
-
 	g, gCtx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
+		if s.tlsConfig != nil {
+			s.log.Info("Starting HTTPS server")
+			// Сертификат сервера уже загружен в TLSConfig, поэтому пути к файлам не передаются.
+			return httpServer.ListenAndServeTLS("", "")
+		}
+
+		s.log.Info("Starting HTTP server")
 		return httpServer.ListenAndServe()
 	})
 
@@ -128,17 +155,26 @@ func (s *Server) router() http.Handler {
 
 	r.Use(
 		middleware2.LogMiddleware(s.log),
-		middleware2.CheckRealIPMiddleware(s.trustedSubnet, s.log),
-		middleware2.GzipMiddleware(s.log),
-		middleware2.DecryptMiddleware(s.cryptManager, s.log),
+		middleware2.CheckRealIPMiddleware(s.ipFilter, s.log),
 	)
 
+	ingest := middleware2.AuthMiddleware(s.tokens, auth.RoleIngest, s.log)
+	read := middleware2.AuthMiddleware(s.tokens, auth.RoleRead, s.log)
+	admin := middleware2.AuthMiddleware(s.tokens, auth.RoleAdmin, s.log)
+	// Тело запроса распаковывается и расшифровывается только после проверки токена.
+	gzip := middleware2.GzipMiddleware(s.log)
+	decrypt := middleware2.DecryptMiddleware(s.cryptManager, s.log)
+	// Все изменяющие запросы должны быть подписаны, если задан ключ подписи.
+	signed := middleware2.SignatureMiddleware(s.singManager, s.log)
+
 	r.Route("/", func(r chi.Router) {
-		r.With(middleware2.HeaderResponseMiddleware("Content-Type", "text/html")).
-			Get("/", list.NewHandler(s.htmlRenderer, s.repo, s.log).Handle)
+		r.With(read, gzip, decrypt, middleware2.HeaderResponseMiddleware("Content-Type", "text/html")).
+			Get("/", list.NewHandler(s.htmlRenderer, s.repo, s.alertEngine, s.log).Handle)
 	})
 
 	r.Route("/update", func(r chi.Router) {
+		r.Use(ingest, gzip, decrypt, signed)
+
 		jsonContentType := middleware2.HeaderResponseMiddleware("Content-Type", "application/json")
 		textContentType := middleware2.HeaderResponseMiddleware("Content-Type", "text/plain", "charset=utf-8")
 
@@ -149,12 +185,12 @@ func (s *Server) router() http.Handler {
 	})
 
 	r.Route("/updates", func(r chi.Router) {
+		r.Use(ingest, gzip, decrypt, signed)
+
 		jsonContentType := middleware2.HeaderResponseMiddleware("Content-Type", "application/json")
-		validateSignRequest := middleware2.SignatureMiddleware(s.singManager, s.log)
 
 		r.
 			With(jsonContentType).
-			With(validateSignRequest).
 			Post("/", updateBatch.NewHandler(s.repo, s.backup, s.log).Handle)
 	})
 
@@ -162,18 +198,51 @@ func (s *Server) router() http.Handler {
 		jsonContentType := middleware2.HeaderResponseMiddleware("Content-Type", "application/json")
 		textContentType := middleware2.HeaderResponseMiddleware("Content-Type", "text/plain", "charset=utf-8")
 
-		r.With(jsonContentType).Post("/", valueGaneral.NewHandler(s.repo, s.log).Handle)
-		r.With(textContentType).Get("/gauge/{name}", valueGauge.NewHandler(s.repo, s.log).Handle)
-		r.With(textContentType).Get("/counter/{name}", valueCaunter.NewHandler(s.repo, s.log).Handle)
-		r.With(textContentType).Get("/{kind}/{name}", valueUnknown.NewHandler(s.log).Handle)
+		r.With(read, gzip, decrypt, jsonContentType).Post("/", valueGaneral.NewHandler(s.repo, s.log).Handle)
+		r.With(read, gzip, decrypt, textContentType).Get("/gauge/{name}", valueGauge.NewHandler(s.repo, s.log).Handle)
+		r.With(read, gzip, decrypt, textContentType).Get("/counter/{name}", valueCaunter.NewHandler(s.repo, s.log).Handle)
+		r.With(read, gzip, decrypt, textContentType).Get("/{kind}/{name}", valueUnknown.NewHandler(s.log).Handle)
+		r.With(admin, gzip, decrypt, signed, textContentType).Delete("/{kind}/{name}", deleteMetric.NewHandler(s.repo, s.backup, s.log).Handle)
+	})
+
+	r.Route("/deletes", func(r chi.Router) {
+		r.Use(admin, gzip, decrypt, signed)
+
+		jsonContentType := middleware2.HeaderResponseMiddleware("Content-Type", "application/json")
+
+		r.
+			With(jsonContentType).
+			Post("/", deleteBatch.NewHandler(s.repo, s.backup, s.log).Handle)
+	})
+
+	r.Route("/metrics", func(r chi.Router) {
+		r.Use(read, gzip, decrypt)
+
+		r.Get("/", prometheus.NewHandler(s.repo, s.log).Handle)
+	})
+
+	r.Route("/alerts", func(r chi.Router) {
+		r.Use(read, gzip, decrypt)
+
+		r.With(middleware2.HeaderResponseMiddleware("Content-Type", "application/json")).
+			Get("/", alerts.NewHandler(s.alertEngine, s.log).Handle)
+	})
+
+	r.Route("/query", func(r chi.Router) {
+		r.Use(read, gzip, decrypt)
+
+		r.With(middleware2.HeaderResponseMiddleware("Content-Type", "application/json")).
+			Get("/", queryHandler.NewHandler(s.queryEngine, s.log).Handle)
 	})
 
 	r.Route("/ping", func(r chi.Router) {
+		r.Use(gzip, decrypt)
+
 		r.Get("/", ping.NewHandler(s.db, s.log).Handle)
 	})
 
 	r.Route("/debug/pprof", func(r chi.Router) {
-		r.Use(chiMiddleware.NoCache)
+		r.Use(admin, gzip, decrypt, chiMiddleware.NoCache)
 
 		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 			if r.RequestURI[len(r.RequestURI)-1:] != "/" {
@@ -197,6 +266,8 @@ func (s *Server) router() http.Handler {
 	})
 
 	r.Route("/docs", func(r chi.Router) {
+		r.Use(read, gzip, decrypt)
+
 		r.Get("/*", httpSwagger.Handler())
 	})
 
@@ -6,6 +6,7 @@ import (
 	nativLog "log"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -14,22 +15,32 @@ import (
 	"github.com/bjlag/go-metrics/cmd/server/http"
 	"github.com/bjlag/go-metrics/cmd/server/rpc"
 	_ "github.com/bjlag/go-metrics/docs"
+	"github.com/bjlag/go-metrics/internal/alert"
 	"github.com/bjlag/go-metrics/internal/backup"
 	asyncBackup "github.com/bjlag/go-metrics/internal/backup/async"
 	syncBackup "github.com/bjlag/go-metrics/internal/backup/sync"
 	"github.com/bjlag/go-metrics/internal/logger"
+	"github.com/bjlag/go-metrics/internal/query"
 	"github.com/bjlag/go-metrics/internal/renderer"
+	"github.com/bjlag/go-metrics/internal/rpc/handler/deletes"
+	"github.com/bjlag/go-metrics/internal/rpc/handler/metrics"
+	rpcQuery "github.com/bjlag/go-metrics/internal/rpc/handler/query"
 	"github.com/bjlag/go-metrics/internal/rpc/handler/updates"
+	"github.com/bjlag/go-metrics/internal/securety/auth"
 	"github.com/bjlag/go-metrics/internal/securety/crypt"
+	"github.com/bjlag/go-metrics/internal/securety/ipfilter"
 	"github.com/bjlag/go-metrics/internal/securety/signature"
+	"github.com/bjlag/go-metrics/internal/securety/tlsconfig"
 	"github.com/bjlag/go-metrics/internal/storage"
 	"github.com/bjlag/go-metrics/internal/storage/file"
 	"github.com/bjlag/go-metrics/internal/storage/memory"
 	"github.com/bjlag/go-metrics/internal/storage/pg"
+	"github.com/bjlag/go-metrics/internal/storage/watch"
 )
 
 const (
-	tmplPath = "web/tmpl/list.html"
+	tmplPath          = "web/tmpl/list.html"
+	retentionInterval = time.Minute
 )
 
 var (
@@ -42,6 +53,11 @@ var (
 //	@version		1.0
 //	@description	Сервис сбора метрик и алертинга
 
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				Токен доступа в виде "Bearer <token>" (если на сервере задан файл токенов)
+
 func main() {
 	cfg := config.LoadConfig()
 
@@ -66,6 +82,9 @@ func main() {
 	log.Info(fmt.Sprintf("Restore metrics %v", cfg.Restore))
 	log.Info(fmt.Sprintf("Private key %s", cfg.CryptoKeyPath))
 	log.Info(fmt.Sprintf("JSON config %s", cfg.ConfigPath))
+	log.Info(fmt.Sprintf("Trusted subnets %v, denied subnets %v, trusted proxies %v", cfg.TrustedSubnets, cfg.DeniedSubnets, cfg.TrustedProxies))
+	log.Info(fmt.Sprintf("History retention %s, size %d", cfg.HistoryRetention, cfg.HistorySize))
+	log.Info(fmt.Sprintf("Alert rules %d, evaluation interval %s", len(cfg.AlertRules), cfg.AlertInterval))
 
 	if err := run(log, cfg); err != nil {
 		log.WithError(err).Error("Error running server")
@@ -81,9 +100,14 @@ func run(log logger.Logger, cfg *config.Configuration) error {
 
 	var repo storage.Repository
 	if db != nil {
-		repo = pg.NewStorage(db, log)
+		pgStorage := pg.NewStorage(db, log, pg.WithRetention(cfg.HistoryRetention))
+		pgStorage.StartRetention(ctx, retentionInterval)
+		repo = pgStorage
 	} else {
-		repo = memory.NewStorage()
+		repo = memory.NewStorage(
+			memory.WithRetention(cfg.HistoryRetention),
+			memory.WithHistorySize(cfg.HistorySize),
+		)
 	}
 
 	backupStore, err := file.NewStorage(cfg.FileStoragePath)
@@ -101,6 +125,9 @@ func run(log logger.Logger, cfg *config.Configuration) error {
 		log.Info("Backup loaded")
 	}
 
+	watchHub := watch.NewHub()
+	repo = watch.NewRepository(repo, watchHub)
+
 	var (
 		backupCreator      backup.Creator
 		asyncBackupCreator *asyncBackup.Backup
@@ -119,7 +146,60 @@ func run(log logger.Logger, cfg *config.Configuration) error {
 		return err
 	}
 
-	signManager := signature.NewSignManager(cfg.SecretKey)
+	httpTLSConfig, err := tlsconfig.NewServer(cfg.HTTPTLSCertPath, cfg.HTTPTLSKeyPath, cfg.HTTPTLSClientCA)
+	if err != nil {
+		return err
+	}
+
+	rpcTLSConfig, err := tlsconfig.NewServer(cfg.RPCTLSCertPath, cfg.RPCTLSKeyPath, cfg.RPCTLSClientCA)
+	if err != nil {
+		return err
+	}
+
+	alertRules, err := newAlertRules(cfg.AlertRules)
+	if err != nil {
+		return err
+	}
+
+	alertEngine := alert.NewEngine(repo, alertRules, cfg.AlertInterval, log)
+	alertEngine.Start(ctx)
+
+	queryEngine := query.NewEngine(repo)
+
+	signOpts := []signature.Option{
+		signature.WithReplayGuard(signature.NewReplayGuard(cfg.ReplayWindow, signature.WithAllowUnstamped(cfg.AllowUnstamped))),
+	}
+
+	if cfg.KeysFile != "" {
+		keys, err := signature.LoadKeyStore(cfg.KeysFile)
+		if err != nil {
+			return err
+		}
+
+		log.Info(fmt.Sprintf("Loaded %d agent keys", keys.Len()))
+		reloadOnSignal(ctx, "keys", keys, log)
+		signOpts = append(signOpts, signature.WithKeyStore(keys))
+	}
+
+	signManager := signature.NewSignManager(cfg.SecretKey, signOpts...)
+
+	ipFilter := ipfilter.New(
+		ipfilter.WithAllowed(cfg.TrustedSubnets),
+		ipfilter.WithDenied(cfg.DeniedSubnets),
+		ipfilter.WithTrustedProxies(cfg.TrustedProxies),
+	)
+
+	var tokens *auth.TokenStore
+	if cfg.TokensFile != "" {
+		tokens, err = auth.LoadTokenStore(cfg.TokensFile)
+		if err != nil {
+			return err
+		}
+
+		log.Info(fmt.Sprintf("Loaded %d API tokens", tokens.Len()))
+		reloadOnSignal(ctx, "tokens", tokens, log)
+	}
+
 	htmlRenderer := renderer.NewHTMLRenderer(tmplPath)
 
 	serverHTTP := http.NewServer(
@@ -128,14 +208,26 @@ func run(log logger.Logger, cfg *config.Configuration) error {
 		repo,
 		db,
 		backupCreator,
+		alertEngine,
+		queryEngine,
 		signManager,
 		cryptManager,
-		cfg.TrustedSubnet,
+		tokens,
+		ipFilter,
+		httpTLSConfig,
 		log,
 	)
 
-	serverRPC := rpc.NewServer(cfg.AddressRPC.String(), cfg.TrustedSubnet, signManager, log)
-	serverRPC.AddMethod(rpc.UpdatesMethodName, updates.NewHandler(repo, backupCreator, log).Updates)
+	serverRPC := rpc.NewServer(cfg.AddressRPC.String(), ipFilter, signManager, cryptManager, tokens, rpcTLSConfig, log)
+	updatesHandler := updates.NewHandler(repo, backupCreator, log)
+	serverRPC.AddMethod(rpc.UpdatesMethodName, updatesHandler.Updates)
+	serverRPC.AddMethod(rpc.StreamUpdatesMethodName, updatesHandler.StreamUpdates)
+	serverRPC.AddMethod(rpc.DeleteMethodName, deletes.NewHandler(repo, backupCreator, log).Delete)
+	metricsHandler := metrics.NewHandler(repo, watchHub, log)
+	serverRPC.AddMethod(rpc.GetValueMethodName, metricsHandler.GetValue)
+	serverRPC.AddMethod(rpc.ListMetricsMethodName, metricsHandler.ListMetrics)
+	serverRPC.AddMethod(rpc.WatchMethodName, metricsHandler.Watch)
+	serverRPC.AddMethod(rpc.QueryMethodName, rpcQuery.NewHandler(queryEngine, log).Query)
 
 	g, gCtx := errgroup.WithContext(ctx)
 	g.Go(func() error {
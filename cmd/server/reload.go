@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/bjlag/go-metrics/internal/logger"
+)
+
+// reloader хранилище секретов, которое умеет перечитывать свой файл, например ключи агентов.
+type reloader interface {
+	Reload() error
+	Len() int
+}
+
+// reloadOnSignal перечитывает файл хранилища store при получении SIGHUP, пока не завершится ctx.
+// Так секреты отзываются и ротируются без перезапуска сервера. Параметр name используется в логах.
+func reloadOnSignal(ctx context.Context, name string, store reloader, log logger.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(hup)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				if err := store.Reload(); err != nil {
+					log.WithError(err).Error(fmt.Sprintf("Failed to reload %s, previous %s are kept", name, name))
+					continue
+				}
+
+				log.Info(fmt.Sprintf("%s reloaded, %d active", name, store.Len()))
+			}
+		}
+	}()
+}
@@ -17,9 +17,18 @@ func restoreData(ctx context.Context, fileStorage *file.Storage, memStorage stor
 	for _, value := range data {
 		switch value.MType {
 		case model.TypeCounter:
-			memStorage.AddCounter(ctx, value.ID, *value.Delta)
+			memStorage.AddCounter(ctx, model.SeriesKey(value.ID, value.Labels), *value.Delta)
 		case model.TypeGauge:
-			memStorage.SetGauge(ctx, value.ID, *value.Value)
+			memStorage.SetGauge(ctx, model.SeriesKey(value.ID, value.Labels), *value.Value)
+		case model.TypeHistogram:
+			err = memStorage.AddHistograms(ctx, []storage.Histogram{{
+				ID:     value.ID,
+				Labels: value.Labels,
+				Value:  *value.Histogram,
+			}})
+			if err != nil {
+				return err
+			}
 		}
 	}
 
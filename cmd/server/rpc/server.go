@@ -2,41 +2,66 @@ package rpc
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
 
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
 
 	"github.com/bjlag/go-metrics/internal/generated/rpc"
 	"github.com/bjlag/go-metrics/internal/logger"
 	"github.com/bjlag/go-metrics/internal/rpc/interceptor"
+	"github.com/bjlag/go-metrics/internal/securety/auth"
+	"github.com/bjlag/go-metrics/internal/securety/crypt"
+	"github.com/bjlag/go-metrics/internal/securety/ipfilter"
 	"github.com/bjlag/go-metrics/internal/securety/signature"
 )
 
 const (
-	UpdatesMethodName = "updates"
+	UpdatesMethodName       = "updates"
+	StreamUpdatesMethodName = "stream_updates"
+	DeleteMethodName        = "delete"
+	GetValueMethodName      = "get_value"
+	ListMetricsMethodName   = "list_metrics"
+	WatchMethodName         = "watch"
+	QueryMethodName         = "query"
 )
 
 type Server struct {
 	rpc.UnimplementedMetricServiceServer
 
-	methods       map[string]any
-	addr          string
-	trustedSubnet *net.IPNet
-	singManager   *signature.SignManager
-	log           logger.Logger
+	methods      map[string]any
+	addr         string
+	ipFilter     *ipfilter.Filter
+	singManager  *signature.SignManager
+	cryptManager *crypt.DecryptManager
+	tokens       *auth.TokenStore
+	tlsConfig    *tls.Config
+	log          logger.Logger
 }
 
-func NewServer(addr string, trustedSubnet *net.IPNet, singManager *signature.SignManager, log logger.Logger) *Server {
+func NewServer(
+	addr string,
+	ipFilter *ipfilter.Filter,
+	singManager *signature.SignManager,
+	cryptManager *crypt.DecryptManager,
+	tokens *auth.TokenStore,
+	tlsConfig *tls.Config,
+	log logger.Logger,
+) *Server {
 	return &Server{
 		methods: make(map[string]any),
 
-		addr:          addr,
-		trustedSubnet: trustedSubnet,
-		singManager:   singManager,
-		log:           log,
+		addr:         addr,
+		ipFilter:     ipFilter,
+		singManager:  singManager,
+		cryptManager: cryptManager,
+		tokens:       tokens,
+		tlsConfig:    tlsConfig,
+		log:          log,
 	}
 }
 
@@ -50,16 +75,36 @@ func (s *Server) Start(ctx context.Context) error {
 		return err
 	}
 
-	grpcServer := grpc.NewServer(
+	var opts []grpc.ServerOption
+	if s.tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.tlsConfig)))
+	}
+
+	opts = append(opts,
 		grpc.ChainUnaryInterceptor(
 			interceptor.LoggerServerInterceptor(s.log),
-			interceptor.CheckRealIPServerMiddleware(s.trustedSubnet),
+			interceptor.CheckRealIPServerMiddleware(s.ipFilter),
+			interceptor.AuthServerInterceptor(s.tokens),
+			interceptor.DecryptServerInterceptor(s.cryptManager),
 			interceptor.CheckSignatureServerInterceptor(s.singManager),
 		),
+		grpc.ChainStreamInterceptor(
+			interceptor.LoggerStreamServerInterceptor(s.log),
+			interceptor.CheckRealIPStreamServerInterceptor(s.ipFilter),
+			interceptor.AuthStreamServerInterceptor(s.tokens),
+			interceptor.DecryptStreamServerInterceptor(s.cryptManager),
+			interceptor.CheckSignatureStreamServerInterceptor(s.singManager),
+		),
 	)
+
+	grpcServer := grpc.NewServer(opts...)
 	rpc.RegisterMetricServiceServer(grpcServer, s)
 
-	s.log.Info("Starting gRPC server")
+	if s.tlsConfig != nil {
+		s.log.Info("Starting gRPC server with TLS")
+	} else {
+		s.log.Info("Starting gRPC server")
+	}
 
 	g, gCtx := errgroup.WithContext(ctx)
 
@@ -91,3 +136,57 @@ func (s *Server) Updates(ctx context.Context, in *rpc.UpdatesIn) (*rpc.UpdatesOu
 
 	return method.(func(context.Context, *rpc.UpdatesIn) (*rpc.UpdatesOut, error))(ctx, in)
 }
+
+func (s *Server) StreamUpdates(stream rpc.MetricService_StreamUpdatesServer) error {
+	method, ok := s.methods[StreamUpdatesMethodName]
+	if !ok {
+		return status.Errorf(codes.NotFound, "unknown method: %s", StreamUpdatesMethodName)
+	}
+
+	return method.(func(rpc.MetricService_StreamUpdatesServer) error)(stream)
+}
+
+func (s *Server) Delete(ctx context.Context, in *rpc.DeleteIn) (*rpc.DeleteOut, error) {
+	method, ok := s.methods[DeleteMethodName]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown method: %s", DeleteMethodName)
+	}
+
+	return method.(func(context.Context, *rpc.DeleteIn) (*rpc.DeleteOut, error))(ctx, in)
+}
+
+func (s *Server) GetValue(ctx context.Context, in *rpc.GetValueIn) (*rpc.GetValueOut, error) {
+	method, ok := s.methods[GetValueMethodName]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown method: %s", GetValueMethodName)
+	}
+
+	return method.(func(context.Context, *rpc.GetValueIn) (*rpc.GetValueOut, error))(ctx, in)
+}
+
+func (s *Server) ListMetrics(ctx context.Context, in *rpc.ListMetricsIn) (*rpc.ListMetricsOut, error) {
+	method, ok := s.methods[ListMetricsMethodName]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown method: %s", ListMetricsMethodName)
+	}
+
+	return method.(func(context.Context, *rpc.ListMetricsIn) (*rpc.ListMetricsOut, error))(ctx, in)
+}
+
+func (s *Server) Watch(in *rpc.WatchIn, stream rpc.MetricService_WatchServer) error {
+	method, ok := s.methods[WatchMethodName]
+	if !ok {
+		return status.Errorf(codes.NotFound, "unknown method: %s", WatchMethodName)
+	}
+
+	return method.(func(*rpc.WatchIn, rpc.MetricService_WatchServer) error)(in, stream)
+}
+
+func (s *Server) Query(ctx context.Context, in *rpc.QueryIn) (*rpc.QueryOut, error) {
+	method, ok := s.methods[QueryMethodName]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown method: %s", QueryMethodName)
+	}
+
+	return method.(func(context.Context, *rpc.QueryIn) (*rpc.QueryOut, error))(ctx, in)
+}
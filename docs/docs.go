@@ -15,6 +15,119 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/alerts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получить состояние алертов.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/alert.Alert"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка"
+                    }
+                }
+            }
+        },
+        "/deletes/": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Удалить набор метрик.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Подпись запроса (если включена проверка подписи)",
+                        "name": "HashSHA256",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Время подписи в секундах Unix (если включена защита от повторов)",
+                        "name": "X-Sign-Timestamp",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Одноразовое значение запроса (если включена защита от повторов)",
+                        "name": "X-Sign-Nonce",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор ключа агента (если агенту выдан свой ключ)",
+                        "name": "X-Key-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "value",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ValueIn"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Метрики удалены"
+                    },
+                    "400": {
+                        "description": "Некорректный запрос"
+                    },
+                    "500": {
+                        "description": "Ошибка"
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Выгрузить метрики в формате Prometheus.",
+                "responses": {
+                    "200": {
+                        "description": "Метрики в текстовом формате Prometheus 0.0.4",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка"
+                    }
+                }
+            }
+        },
         "/ping": {
             "get": {
                 "summary": "Проверяем соединение с базой данных.",
@@ -28,8 +141,84 @@ const docTemplate = `{
                 }
             }
         },
+        "/query/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Вычислить rate, increase, min, max или avg по истории значений метрики за окно.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "rate",
+                        "description": "Функция: rate, increase, min, max или avg",
+                        "name": "func",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "counter",
+                        "description": "Тип метрики: gauge или counter",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "PollCount",
+                        "description": "Название метрики",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "5m",
+                        "description": "Окно, например 5m",
+                        "name": "window",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "host=localhost",
+                        "description": "Метки метрики: name=value,name=value",
+                        "name": "labels",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.QueryOut"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос"
+                    },
+                    "404": {
+                        "description": "Метрика не найдена или в окне недостаточно значений"
+                    },
+                    "500": {
+                        "description": "Ошибка"
+                    }
+                }
+            }
+        },
         "/update/": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -38,6 +227,30 @@ const docTemplate = `{
                 ],
                 "summary": "Обновить метрику.",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Подпись запроса (если включена проверка подписи)",
+                        "name": "HashSHA256",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Время подписи в секундах Unix (если включена защита от повторов)",
+                        "name": "X-Sign-Timestamp",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Одноразовое значение запроса (если включена защита от повторов)",
+                        "name": "X-Sign-Nonce",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор ключа агента (если агенту выдан свой ключ)",
+                        "name": "X-Key-ID",
+                        "in": "header"
+                    },
                     {
                         "description": "Request body",
                         "name": "value",
@@ -69,8 +282,37 @@ const docTemplate = `{
         },
         "/update/counter/{name}/{value}": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "summary": "Обновление метрики типа Counter.",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Подпись запроса (если включена проверка подписи)",
+                        "name": "HashSHA256",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Время подписи в секундах Unix (если включена защита от повторов)",
+                        "name": "X-Sign-Timestamp",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Одноразовое значение запроса (если включена защита от повторов)",
+                        "name": "X-Sign-Nonce",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор ключа агента (если агенту выдан свой ключ)",
+                        "name": "X-Key-ID",
+                        "in": "header"
+                    },
                     {
                         "type": "string",
                         "example": "PollCount",
@@ -106,8 +348,37 @@ const docTemplate = `{
         },
         "/update/gauge/{name}/{value}": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "summary": "Обновление метрики типа Gauge.",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Подпись запроса (если включена проверка подписи)",
+                        "name": "HashSHA256",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Время подписи в секундах Unix (если включена защита от повторов)",
+                        "name": "X-Sign-Timestamp",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Одноразовое значение запроса (если включена защита от повторов)",
+                        "name": "X-Sign-Nonce",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор ключа агента (если агенту выдан свой ключ)",
+                        "name": "X-Key-ID",
+                        "in": "header"
+                    },
                     {
                         "type": "string",
                         "example": "Sys",
@@ -143,6 +414,11 @@ const docTemplate = `{
         },
         "/updates/": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -154,6 +430,24 @@ const docTemplate = `{
                         "name": "HashSHA256",
                         "in": "header"
                     },
+                    {
+                        "type": "string",
+                        "description": "Время подписи в секундах Unix (если включена защита от повторов)",
+                        "name": "X-Sign-Timestamp",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Одноразовое значение запроса (если включена защита от повторов)",
+                        "name": "X-Sign-Nonce",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор ключа агента (если агенту выдан свой ключ)",
+                        "name": "X-Key-ID",
+                        "in": "header"
+                    },
                     {
                         "description": "Request body",
                         "name": "value",
@@ -185,6 +479,11 @@ const docTemplate = `{
         },
         "/value/": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -224,6 +523,11 @@ const docTemplate = `{
         },
         "/value/counter/{name}": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "text/plain"
                 ],
@@ -259,6 +563,11 @@ const docTemplate = `{
         },
         "/value/gauge/{name}": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "text/plain"
                 ],
@@ -291,9 +600,220 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/value/{kind}/{name}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "summary": "Удалить метрику вместе с историей ее значений.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Подпись запроса (если включена проверка подписи)",
+                        "name": "HashSHA256",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Время подписи в секундах Unix (если включена защита от повторов)",
+                        "name": "X-Sign-Timestamp",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Одноразовое значение запроса (если включена защита от повторов)",
+                        "name": "X-Sign-Nonce",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор ключа агента (если агенту выдан свой ключ)",
+                        "name": "X-Key-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "example": "gauge",
+                        "description": "Тип метрики: gauge, counter или histogram",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "Sys",
+                        "description": "Название метрики",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Метрика удалена"
+                    },
+                    "400": {
+                        "description": "Неизвестный тип метрики или некорректное название"
+                    },
+                    "404": {
+                        "description": "Метрика не найдена"
+                    },
+                    "500": {
+                        "description": "Ошибка"
+                    }
+                }
+            }
         }
     },
     "definitions": {
+        "alert.Alert": {
+            "type": "object",
+            "properties": {
+                "active_at": {
+                    "description": "Когда условие начало выполняться",
+                    "type": "string"
+                },
+                "eval_at": {
+                    "description": "Время последней проверки",
+                    "type": "string"
+                },
+                "expr": {
+                    "description": "Выражение правила",
+                    "type": "string"
+                },
+                "fired_at": {
+                    "description": "Когда алерт сработал",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Название правила",
+                    "type": "string"
+                },
+                "resolved_at": {
+                    "description": "Когда алерт перестал быть активным",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "Состояние алерта",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/alert.State"
+                        }
+                    ]
+                },
+                "value": {
+                    "description": "Значение выражения на момент последней проверки",
+                    "type": "number"
+                }
+            }
+        },
+        "alert.State": {
+            "type": "string",
+            "enum": [
+                "inactive",
+                "pending",
+                "firing",
+                "resolved"
+            ],
+            "x-enum-varnames": [
+                "StateInactive",
+                "StatePending",
+                "StateFiring",
+                "StateResolved"
+            ]
+        },
+        "model.Histogram": {
+            "type": "object",
+            "properties": {
+                "bounds": {
+                    "description": "Верхние границы бакетов",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    },
+                    "example": [
+                        0.1,
+                        0.5,
+                        1
+                    ]
+                },
+                "count": {
+                    "description": "Количество наблюдений",
+                    "type": "integer",
+                    "example": 9
+                },
+                "counts": {
+                    "description": "Количество наблюдений в бакетах, последний бакет +Inf",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        3,
+                        5,
+                        1,
+                        0
+                    ]
+                },
+                "sum": {
+                    "description": "Сумма наблюдений",
+                    "type": "number",
+                    "example": 2.7
+                }
+            }
+        },
+        "model.Labels": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "model.QueryOut": {
+            "type": "object",
+            "properties": {
+                "func": {
+                    "description": "Функция: rate, increase, min, max или avg",
+                    "type": "string",
+                    "example": "rate"
+                },
+                "id": {
+                    "description": "Имя метрики",
+                    "type": "string",
+                    "example": "PollCount"
+                },
+                "labels": {
+                    "description": "Метки метрики",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Labels"
+                        }
+                    ]
+                },
+                "samples": {
+                    "description": "Количество значений в окне",
+                    "type": "integer",
+                    "example": 30
+                },
+                "type": {
+                    "description": "Тип метрики: gauge или counter",
+                    "type": "string",
+                    "example": "counter"
+                },
+                "value": {
+                    "description": "Вычисленное значение",
+                    "type": "number",
+                    "example": 0.5
+                },
+                "window": {
+                    "description": "Окно, за которое вычислено значение",
+                    "type": "string",
+                    "example": "5m0s"
+                }
+            }
+        },
         "model.UpdateIn": {
             "type": "object",
             "properties": {
@@ -302,13 +822,29 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1
                 },
+                "histogram": {
+                    "description": "Значение метрики в случае передачи histogram",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Histogram"
+                        }
+                    ]
+                },
                 "id": {
                     "description": "Имя метрики",
                     "type": "string",
                     "example": "Sys"
                 },
+                "labels": {
+                    "description": "Метки метрики, входят в идентичность серии",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Labels"
+                        }
+                    ]
+                },
                 "type": {
-                    "description": "Параметр, принимающий значение gauge или counter",
+                    "description": "Параметр, принимающий значение gauge, counter или histogram",
                     "type": "string",
                     "example": "gauge"
                 },
@@ -326,12 +862,28 @@ const docTemplate = `{
                     "description": "Значение метрики в случае передачи counter",
                     "type": "integer"
                 },
+                "histogram": {
+                    "description": "Значение метрики в случае передачи histogram",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Histogram"
+                        }
+                    ]
+                },
                 "id": {
                     "description": "Имя метрики",
                     "type": "string"
                 },
+                "labels": {
+                    "description": "Метки метрики",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Labels"
+                        }
+                    ]
+                },
                 "type": {
-                    "description": "Параметр, принимающий значение gauge или counter",
+                    "description": "Параметр, принимающий значение gauge, counter или histogram",
                     "type": "string"
                 },
                 "value": {
@@ -348,8 +900,16 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Sys"
                 },
+                "labels": {
+                    "description": "Метки метрики, входят в идентичность серии",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Labels"
+                        }
+                    ]
+                },
                 "type": {
-                    "description": "Параметр, принимающий значение gauge или counter",
+                    "description": "Параметр, принимающий значение gauge, counter или histogram",
                     "type": "string",
                     "example": "gauge"
                 }
@@ -362,12 +922,28 @@ const docTemplate = `{
                     "description": "Значение метрики в случае передачи counter",
                     "type": "integer"
                 },
+                "histogram": {
+                    "description": "Значение метрики в случае передачи histogram",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Histogram"
+                        }
+                    ]
+                },
                 "id": {
                     "description": "Имя метрики",
                     "type": "string"
                 },
+                "labels": {
+                    "description": "Метки метрики",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Labels"
+                        }
+                    ]
+                },
                 "type": {
-                    "description": "Параметр, принимающий значение gauge или counter",
+                    "description": "Параметр, принимающий значение gauge, counter или histogram",
                     "type": "string"
                 },
                 "value": {
@@ -376,6 +952,14 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Токен доступа в виде \"Bearer \u003ctoken\u003e\" (если на сервере задан файл токенов)",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
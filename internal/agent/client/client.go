@@ -1,6 +1,13 @@
 package client
 
-import "github.com/bjlag/go-metrics/internal/agent/collector"
+import (
+	"errors"
+
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+)
+
+// ErrRejected сервер отклонил набор метрик. Повторная отправка того же набора не имеет смысла.
+var ErrRejected = errors.New("metrics rejected by server")
 
 type Client interface {
 	Send(metrics []*collector.Metric) error
@@ -3,9 +3,12 @@ package http
 import (
 	"bytes"
 	"compress/gzip"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -19,8 +22,8 @@ import (
 )
 
 const (
-	baseURLTemplate = "http://%s:%d"
-	urlTemplate     = "%s/updates/"
+	baseURLTemplate = "%s://%s:%d"
+	updatesPath     = "/updates/"
 
 	timeout          = 100 * time.Millisecond
 	maxRetries       = 3
@@ -31,13 +34,14 @@ const (
 // MetricSender обслуживает HTTP запросы для отправки метрик на сервер.
 // Для отправки HTTP запросов используется HTTP клиент [go resty].
 //
-// Запросы могут быть подписаны. Подпись передается через заголовок HashSHA256.
+// Запросы могут быть подписаны. Подписываются метод, путь и тело запроса, подпись передается через заголовок HashSHA256,
+// время подписи и одноразовое значение для защиты от повторов через заголовки X-Sign-Timestamp и X-Sign-Nonce.
 // Есть rate limiter для ограничения количества одновременных запросов.
 //
 // [go resty]: https://github.com/go-resty/resty
 type MetricSender struct {
 	client   *resty.Client
-	clientIP net.IP
+	clientIP *clientIP.AgentIP
 	sign     *signature.SignManager
 	crypt    *crypt.EncryptManager
 	limiter  *limiter.RateLimiter
@@ -45,32 +49,89 @@ type MetricSender struct {
 	log      log
 }
 
+// Option настраивает клиент.
+type Option func(o *options)
+
+type options struct {
+	tls   *tls.Config
+	token string
+	ip    net.IP
+}
+
+// WithTLS включает HTTPS с настройками config, см. пакет tlsconfig. Без этой опции запросы отправляются по HTTP.
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) {
+		o.tls = config
+	}
+}
+
+// WithToken передает токен доступа в заголовке `Authorization: Bearer <token>`. Пустой токен не передается.
+func WithToken(token string) Option {
+	return func(o *options) {
+		o.token = token
+	}
+}
+
+// WithIP передавать серверу в заголовке X-Real-IP адрес агента ip. Без этой опции передается локальный адрес
+// подключения к серверу, см. [clientIP.AgentIP].
+func WithIP(ip net.IP) Option {
+	return func(o *options) {
+		o.ip = ip
+	}
+}
+
 // NewSender создает HTTP клиент.
-func NewSender(host string, port int, sign *signature.SignManager, crypt *crypt.EncryptManager, limiter *limiter.RateLimiter, log log) *MetricSender {
+func NewSender(
+	host string,
+	port int,
+	sign *signature.SignManager,
+	crypt *crypt.EncryptManager,
+	limiter *limiter.RateLimiter,
+	log log,
+	opts ...Option,
+) (*MetricSender, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	client := resty.New()
 	client.SetTimeout(timeout)
 	client.SetRetryCount(maxRetries)
 	client.SetRetryWaitTime(retryWaitTime)
 	client.SetRetryMaxWaitTime(retryMaxWaitTime)
 
+	scheme := "http"
+	if o.tls != nil {
+		scheme = "https"
+		client.SetTLSClientConfig(o.tls)
+	}
+
+	if o.token != "" {
+		client.SetAuthToken(o.token)
+	}
+
 	return &MetricSender{
 		client:   client,
-		clientIP: clientIP.GetOutboundIP(),
+		clientIP: clientIP.NewAgentIP(o.ip, net.JoinHostPort(host, strconv.Itoa(port))),
 		sign:     sign,
 		crypt:    crypt,
 		limiter:  limiter,
-		baseURL:  fmt.Sprintf(baseURLTemplate, host, port),
+		baseURL:  fmt.Sprintf(baseURLTemplate, scheme, host, port),
 		log:      log,
-	}
+	}, nil
 }
 
 // Send отправляет набор метрик в рамках одного запроса.
+// Если сервер ответил кодом 400, то есть отклонил сам набор метрик, возвращается ошибка [clientIP.ErrRejected].
+// При остальных кодах ошибок, например 401, 403 или 429, возвращается обычная ошибка, и отправку можно повторить.
 func (s MetricSender) Send(metrics []*collector.Metric) error {
 	req := make([]model.UpdateIn, 0, len(metrics))
 	for _, m := range metrics {
 		in := model.UpdateIn{
-			ID:    m.Name(),
-			MType: m.Kind(),
+			ID:     m.Name(),
+			MType:  m.Kind(),
+			Labels: m.Labels(),
 		}
 
 		switch m.Kind() {
@@ -86,6 +147,12 @@ func (s MetricSender) Send(metrics []*collector.Metric) error {
 				return err
 			}
 			in.Delta = &value
+		case collector.Histogram:
+			value, err := m.HistogramValue()
+			if err != nil {
+				return err
+			}
+			in.Histogram = &value
 		default:
 			continue
 		}
@@ -108,16 +175,31 @@ func (s MetricSender) Send(metrics []*collector.Metric) error {
 		return err
 	}
 
-	url := fmt.Sprintf(urlTemplate, s.baseURL)
+	url := s.baseURL + updatesPath
 	request := s.client.R().
 		SetHeader("Content-Type", "application/json").
 		SetHeader("Content-Encoding", "gzip").
 		SetHeader("Accept-Encoding", "gzip").
-		SetHeader("X-Real-IP", s.clientIP.String()).
 		SetBody(compressed)
 
+	if ip := s.clientIP.IP(); ip != nil {
+		request = request.SetHeader("X-Real-IP", ip.String())
+	}
+
+	if scheme := s.crypt.Scheme(); scheme != "" {
+		request = request.SetHeader(crypt.HeaderScheme, scheme)
+	}
+
 	if s.sign.Enable() {
-		request = request.SetHeader("HashSHA256", s.sign.Sing(jsonb))
+		stamp := signature.NewStamp()
+		request = request.
+			SetHeader("HashSHA256", s.sign.Sing(signature.RequestPayload(http.MethodPost, updatesPath, stamp, jsonb))).
+			SetHeader(signature.HeaderTimestamp, stamp.TimestampString()).
+			SetHeader(signature.HeaderNonce, stamp.Nonce)
+
+		if keyID := s.sign.KeyID(); keyID != "" {
+			request = request.SetHeader(signature.HeaderKeyID, keyID)
+		}
 	}
 
 	s.limiter.Acquire()
@@ -133,6 +215,14 @@ func (s MetricSender) Send(metrics []*collector.Metric) error {
 		WithField("status", response.StatusCode()).
 		Info("Sent HTTP request")
 
+	if response.StatusCode() == http.StatusBadRequest {
+		return fmt.Errorf("%w: status %d", clientIP.ErrRejected, response.StatusCode())
+	}
+
+	if response.IsError() {
+		return fmt.Errorf("server responded with status %d", response.StatusCode())
+	}
+
 	return nil
 }
 
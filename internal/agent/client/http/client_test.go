@@ -4,16 +4,23 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	agent "github.com/bjlag/go-metrics/internal/agent/client"
 	client "github.com/bjlag/go-metrics/internal/agent/client/http"
 	"github.com/bjlag/go-metrics/internal/agent/client/http/mock"
 	"github.com/bjlag/go-metrics/internal/agent/collector"
@@ -21,6 +28,7 @@ import (
 	"github.com/bjlag/go-metrics/internal/model"
 	"github.com/bjlag/go-metrics/internal/securety/crypt"
 	"github.com/bjlag/go-metrics/internal/securety/signature"
+	"github.com/bjlag/go-metrics/internal/securety/tlsconfig"
 )
 
 func TestMetricSender_Send(t *testing.T) {
@@ -29,12 +37,13 @@ func TestMetricSender_Send(t *testing.T) {
 	}
 
 	tests := []struct {
-		name       string
-		args       args
-		server     *httptest.Server
-		log        func(ctrl *gomock.Controller) *mock.MockLogger
-		wantStatus int
-		wantErr    bool
+		name         string
+		args         args
+		server       *httptest.Server
+		log          func(ctrl *gomock.Controller) *mock.MockLogger
+		wantStatus   int
+		wantErr      bool
+		wantRejected bool
 	}{
 		{
 			name: "success",
@@ -101,6 +110,87 @@ func TestMetricSender_Send(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "server error",
+			args: args{
+				metric: []*collector.Metric{collector.NewMetric("counter", "counter_name", 1)},
+			},
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			})),
+			log: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLog := mock.NewMockLogger(ctrl)
+				mockLog.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(mockLog).AnyTimes()
+				mockLog.EXPECT().Info(gomock.Any())
+				return mockLog
+			},
+			wantErr: true,
+		},
+		{
+			name: "rejected",
+			args: args{
+				metric: []*collector.Metric{collector.NewMetric("counter", "counter_name", 1)},
+			},
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			})),
+			log: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLog := mock.NewMockLogger(ctrl)
+				mockLog.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(mockLog).AnyTimes()
+				mockLog.EXPECT().Info(gomock.Any())
+				return mockLog
+			},
+			wantErr:      true,
+			wantRejected: true,
+		},
+		{
+			name: "unauthorized is retried",
+			args: args{
+				metric: []*collector.Metric{collector.NewMetric("counter", "counter_name", 1)},
+			},
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			})),
+			log: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLog := mock.NewMockLogger(ctrl)
+				mockLog.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(mockLog).AnyTimes()
+				mockLog.EXPECT().Info(gomock.Any())
+				return mockLog
+			},
+			wantErr: true,
+		},
+		{
+			name: "forbidden is retried",
+			args: args{
+				metric: []*collector.Metric{collector.NewMetric("counter", "counter_name", 1)},
+			},
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			})),
+			log: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLog := mock.NewMockLogger(ctrl)
+				mockLog.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(mockLog).AnyTimes()
+				mockLog.EXPECT().Info(gomock.Any())
+				return mockLog
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many requests is retried",
+			args: args{
+				metric: []*collector.Metric{collector.NewMetric("counter", "counter_name", 1)},
+			},
+			server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			})),
+			log: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLog := mock.NewMockLogger(ctrl)
+				mockLog.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(mockLog).AnyTimes()
+				mockLog.EXPECT().Info(gomock.Any())
+				return mockLog
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -114,7 +204,7 @@ func TestMetricSender_Send(t *testing.T) {
 
 			encryptManager, _ := crypt.NewEncryptManager("")
 
-			c := client.NewSender(
+			c, err := client.NewSender(
 				parts[0],
 				port,
 				signature.NewSignManager("secretKey"),
@@ -122,17 +212,82 @@ func TestMetricSender_Send(t *testing.T) {
 				limiter.NewRateLimiter(1),
 				tt.log(ctrl),
 			)
+			require.NoError(t, err)
 
-			err := c.Send(tt.args.metric)
+			err = c.Send(tt.args.metric)
 
 			if tt.wantErr {
 				assert.Error(t, err)
+				assert.Equal(t, tt.wantRejected, errors.Is(err, agent.ErrRejected))
 				return
 			}
+
+			assert.NoError(t, err)
 		})
 	}
 }
 
+func TestMetricSender_Send_MutualTLS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dir := t.TempDir()
+
+	ca, err := tlsconfig.NewCA("Test CA", time.Hour)
+	require.NoError(t, err)
+	serverCert, err := ca.Issue("server", []string{"127.0.0.1"}, tlsconfig.UsageServer, time.Hour)
+	require.NoError(t, err)
+	clientCert, err := ca.Issue("agent", nil, tlsconfig.UsageClient, time.Hour)
+	require.NoError(t, err)
+
+	files := map[string][]byte{
+		"ca.crt":     ca.CertPEM,
+		"server.crt": serverCert.CertPEM,
+		"server.key": serverCert.KeyPEM,
+		"client.crt": clientCert.CertPEM,
+		"client.key": clientCert.KeyPEM,
+	}
+	for name, data := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+	}
+
+	serverTLS, err := tlsconfig.NewServer(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.crt"))
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NotNil(t, r.TLS)
+		require.Len(t, r.TLS.PeerCertificates, 1)
+		assert.Equal(t, "agent", r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	server.TLS = serverTLS
+	server.StartTLS()
+	defer server.Close()
+
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	port, _ := strconv.Atoi(portStr)
+
+	clientTLS, err := tlsconfig.NewClient(false, filepath.Join(dir, "ca.crt"), filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"))
+	require.NoError(t, err)
+
+	mockLog := mock.NewMockLogger(ctrl)
+	mockLog.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(mockLog).AnyTimes()
+	mockLog.EXPECT().Info(gomock.Any())
+
+	encryptManager, _ := crypt.NewEncryptManager("")
+
+	c, err := client.NewSender(
+		host,
+		port,
+		signature.NewSignManager(""),
+		encryptManager,
+		limiter.NewRateLimiter(1),
+		mockLog,
+		client.WithTLS(clientTLS),
+	)
+	require.NoError(t, err)
+
+	assert.NoError(t, c.Send([]*collector.Metric{collector.NewMetric("counter", "counter_name", 1)}))
+}
+
 func decompress(r io.Reader) []byte {
 	zr, _ := gzip.NewReader(r)
 
@@ -141,3 +296,49 @@ func decompress(r io.Reader) []byte {
 
 	return zb.Bytes()
 }
+
+func TestMetricSender_Send_RealIP(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []client.Option
+		wantIP string
+	}{
+		{
+			name:   "local address of connection to server",
+			wantIP: "127.0.0.1",
+		},
+		{
+			name:   "explicit address",
+			opts:   []client.Option{client.WithIP(net.ParseIP("192.168.1.10"))},
+			wantIP: "192.168.1.10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			var realIP string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				realIP = r.Header.Get("X-Real-IP")
+			}))
+			defer server.Close()
+
+			host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+			require.NoError(t, err)
+			port, _ := strconv.Atoi(portStr)
+
+			mockLog := mock.NewMockLogger(ctrl)
+			mockLog.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(mockLog).AnyTimes()
+			mockLog.EXPECT().Info(gomock.Any())
+
+			encryptManager, _ := crypt.NewEncryptManager("")
+
+			c, err := client.NewSender(host, port, signature.NewSignManager(""), encryptManager, limiter.NewRateLimiter(1), mockLog, tt.opts...)
+			require.NoError(t, err)
+
+			require.NoError(t, c.Send([]*collector.Metric{collector.NewMetric("counter", "counter_name", 1)}))
+			assert.Equal(t, tt.wantIP, realIP)
+		})
+	}
+}
@@ -1,15 +1,118 @@
 package client
 
-import "net"
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
 
-func GetOutboundIP() net.IP {
-	conn, err := net.Dial("udp", "8.8.8.8:80")
+// ErrNoAddress ошибка, если у сетевого интерфейса нет подходящего IP адреса.
+var ErrNoAddress = errors.New("no suitable IP address")
+
+// LocalIP возвращает локальный адрес, с которого агент подключается к серверу addr вида host:port.
+// Для этого подключается UDP сокет: пакеты при этом не отправляются, ядро только выбирает маршрут до сервера,
+// поэтому доступ в интернет не нужен.
+func LocalIP(addr string) (net.IP, error) {
+	conn, err := net.Dial("udp", addr)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to determine local address for '%s': %w", addr, err)
 	}
 	defer func() {
 		_ = conn.Close()
 	}()
 
-	return conn.LocalAddr().(*net.UDPAddr).IP
+	udpAddr, ok := conn.LocalAddr().(*net.UDPAddr)
+	if !ok || udpAddr.IP == nil {
+		return nil, fmt.Errorf("failed to determine local address for '%s': %w", addr, ErrNoAddress)
+	}
+
+	return udpAddr.IP, nil
+}
+
+// AgentIP адрес агента, который передается серверу.
+//
+// Явно заданный адрес возвращается как есть. Иначе адрес определяется по подключению к серверу (см. [LocalIP])
+// при первом обращении и запоминается. Пока определить адрес не удается, например имя сервера еще не резолвится,
+// возвращается nil, и адрес серверу не передается: ошибка определения адреса не мешает отправке метрик.
+type AgentIP struct {
+	addr string
+
+	lock sync.Mutex
+	ip   net.IP
+}
+
+// NewAgentIP создает адрес агента. Если ip не задан, адрес определяется по подключению к серверу addr вида host:port.
+func NewAgentIP(ip net.IP, addr string) *AgentIP {
+	return &AgentIP{
+		addr: addr,
+		ip:   ip,
+	}
+}
+
+// IP возвращает адрес агента или nil, если его не удалось определить.
+func (a *AgentIP) IP() net.IP {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.ip == nil {
+		a.ip, _ = LocalIP(a.addr)
+	}
+
+	return a.ip
+}
+
+// InterfaceIP возвращает адрес сетевого интерфейса name. Адрес IPv4 предпочтительнее IPv6,
+// link-local адреса не используются.
+func InterfaceIP(name string) (net.IP, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interface '%s': %w", name, err)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get addresses of interface '%s': %w", name, err)
+	}
+
+	var ip6 net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+
+		if ip4 := ipNet.IP.To4(); ip4 != nil {
+			return ip4, nil
+		}
+
+		if ip6 == nil {
+			ip6 = ipNet.IP
+		}
+	}
+
+	if ip6 == nil {
+		return nil, fmt.Errorf("interface '%s': %w", name, ErrNoAddress)
+	}
+
+	return ip6, nil
+}
+
+// ResolveIP возвращает адрес агента из конфигурации: явно заданный адрес ip или адрес интерфейса iface.
+// Если ни то ни другое не задано, возвращает nil: тогда адрес определяется по подключению к серверу, см. [LocalIP].
+func ResolveIP(ip, iface string) (net.IP, error) {
+	if ip != "" {
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			return nil, fmt.Errorf("invalid IP address '%s'", ip)
+		}
+
+		return parsed, nil
+	}
+
+	if iface != "" {
+		return InterfaceIP(iface)
+	}
+
+	return nil, nil
 }
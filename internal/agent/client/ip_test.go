@@ -0,0 +1,66 @@
+package client_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/agent/client"
+)
+
+func TestLocalIP(t *testing.T) {
+	ip, err := client.LocalIP("127.0.0.1:8080")
+	require.NoError(t, err)
+	assert.True(t, ip.IsLoopback())
+
+	_, err = client.LocalIP("invalid")
+	assert.Error(t, err)
+}
+
+func TestAgentIP_IP(t *testing.T) {
+	ip := client.NewAgentIP(net.ParseIP("192.168.1.10"), "invalid")
+	assert.Equal(t, "192.168.1.10", ip.IP().String())
+
+	ip = client.NewAgentIP(nil, "127.0.0.1:8080")
+	assert.True(t, ip.IP().IsLoopback())
+
+	ip = client.NewAgentIP(nil, "invalid")
+	assert.Nil(t, ip.IP())
+}
+
+func TestResolveIP(t *testing.T) {
+	ip, err := client.ResolveIP("192.168.1.10", "")
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.1.10", ip.String())
+
+	ip, err = client.ResolveIP("", "")
+	require.NoError(t, err)
+	assert.Nil(t, ip)
+
+	_, err = client.ResolveIP("localhost", "")
+	assert.Error(t, err)
+
+	_, err = client.ResolveIP("", "no-such-interface")
+	assert.Error(t, err)
+}
+
+func TestInterfaceIP(t *testing.T) {
+	ifaces, err := net.Interfaces()
+	require.NoError(t, err)
+
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 {
+			continue
+		}
+
+		ip, err := client.InterfaceIP(iface.Name)
+		require.NoError(t, err)
+		assert.True(t, ip.IsLoopback())
+
+		return
+	}
+
+	t.Skip("no loopback interface")
+}
@@ -2,57 +2,170 @@ package rpc
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
 	"net"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
 	clientIP "github.com/bjlag/go-metrics/internal/agent/client"
 	"github.com/bjlag/go-metrics/internal/agent/collector"
 	"github.com/bjlag/go-metrics/internal/generated/rpc"
 	"github.com/bjlag/go-metrics/internal/logger"
 	"github.com/bjlag/go-metrics/internal/rpc/interceptor"
+	"github.com/bjlag/go-metrics/internal/securety/crypt"
 	"github.com/bjlag/go-metrics/internal/securety/signature"
 )
 
-const timeout = 200 * time.Millisecond
+const (
+	timeout = 200 * time.Millisecond
+	// closeStreamTimeout сколько ждать итогового подтверждения сервера при закрытии потока.
+	closeStreamTimeout = time.Second
+)
 
+// MetricSender отправляет метрики на gRPC сервер.
+//
+// По умолчанию каждый набор метрик отправляется отдельным вызовом Updates.
+// В потоковом режиме (см. [WithStream]) наборы отправляются батчами в один долгоживущий поток StreamUpdates,
+// который открывается при первой отправке и переоткрывается после ошибки. Батч считается доставленным
+// только после подтверждения сервера.
 type MetricSender struct {
 	conn     *grpc.ClientConn
 	client   rpc.MetricServiceClient
 	clientIP net.IP
+	agentIP  *clientIP.AgentIP
 	sign     *signature.SignManager
+	encrypt  *crypt.EncryptManager
+	tls      *tls.Config
+	token    string
+	log      logger.Logger
+
+	streaming bool
+	lock      sync.Mutex
+	stream    rpc.MetricService_StreamUpdatesClient
+	cancel    context.CancelFunc
+	acks      chan *rpc.StreamAck
+	done      chan struct{}
+	sent      int64
+}
+
+// Option настраивает клиент.
+type Option func(s *MetricSender)
+
+// WithStream включает отправку метрик через поток StreamUpdates.
+func WithStream() Option {
+	return func(s *MetricSender) {
+		s.streaming = true
+	}
 }
 
-// NewSender создает RPC клиент.
-func NewSender(addr string, sign *signature.SignManager, log logger.Logger) *MetricSender {
+// WithEncryption включает шифрование батчей метрик публичным ключом сервера.
+func WithEncryption(encrypt *crypt.EncryptManager) Option {
+	return func(s *MetricSender) {
+		s.encrypt = encrypt
+	}
+}
+
+// WithTLS включает TLS с настройками config, см. пакет tlsconfig. Без этой опции соединение не шифруется.
+func WithTLS(config *tls.Config) Option {
+	return func(s *MetricSender) {
+		s.tls = config
+	}
+}
+
+// WithToken передает токен доступа в метаданных каждого вызова. Пустой токен не передается.
+func WithToken(token string) Option {
+	return func(s *MetricSender) {
+		s.token = token
+	}
+}
+
+// WithIP передавать серверу адрес агента ip. Без этой опции передается локальный адрес подключения к серверу,
+// см. [clientIP.AgentIP].
+func WithIP(ip net.IP) Option {
+	return func(s *MetricSender) {
+		s.clientIP = ip
+	}
+}
+
+// NewSender создает RPC клиент. Возвращает ошибку, если не удалось создать соединение.
+func NewSender(addr string, sign *signature.SignManager, log logger.Logger, opts ...Option) (*MetricSender, error) {
+	s := &MetricSender{
+		sign:    sign,
+		encrypt: &crypt.EncryptManager{},
+		log:     log,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.agentIP = clientIP.NewAgentIP(s.clientIP, addr)
+
+	transportCredentials := insecure.NewCredentials()
+	if s.tls != nil {
+		transportCredentials = credentials.NewTLS(s.tls)
+	}
+
 	conn, err := grpc.NewClient(
 		addr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(transportCredentials),
 		grpc.WithChainUnaryInterceptor(
 			interceptor.LoggerClientInterceptor(log),
-			interceptor.RealIPClientInterceptor,
+			interceptor.RealIPClientInterceptor(s.agentIP.IP),
+			interceptor.TokenClientInterceptor(s.token),
 			interceptor.SignatureClientInterceptor(sign),
+			interceptor.EncryptClientInterceptor(s.encrypt),
+		),
+		grpc.WithChainStreamInterceptor(
+			interceptor.LoggerStreamClientInterceptor(log),
+			interceptor.RealIPStreamClientInterceptor(s.agentIP.IP),
+			interceptor.TokenStreamClientInterceptor(s.token),
+			interceptor.SignatureStreamClientInterceptor(sign),
+			interceptor.EncryptStreamClientInterceptor(s.encrypt),
 		),
 	)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
 	}
 
-	return &MetricSender{
-		conn:     conn,
-		client:   rpc.NewMetricServiceClient(conn),
-		clientIP: clientIP.GetOutboundIP(),
-		sign:     sign,
-	}
+	s.conn = conn
+	s.client = rpc.NewMetricServiceClient(conn)
+
+	return s, nil
 }
 
-func (s MetricSender) Close() error {
+// Close закрывает поток, если он открыт, и соединение с сервером.
+func (s *MetricSender) Close() error {
+	s.lock.Lock()
+	stream, cancel, done := s.stream, s.cancel, s.done
+	s.stream = nil
+	s.lock.Unlock()
+
+	if stream != nil {
+		_ = stream.CloseSend()
+
+		select {
+		case <-done:
+		case <-time.After(closeStreamTimeout):
+			s.log.Info("Timeout waiting for stream acknowledgement")
+		}
+
+		cancel()
+	}
+
 	return s.conn.Close()
 }
 
-func (s MetricSender) Send(metrics []*collector.Metric) error {
+func (s *MetricSender) Send(metrics []*collector.Metric) error {
 	inMetrics := make([]*rpc.Metric, 0, len(metrics))
 
 	for _, m := range metrics {
@@ -61,8 +174,9 @@ func (s MetricSender) Send(metrics []*collector.Metric) error {
 		}
 
 		inMetric := &rpc.Metric{
-			Id:   m.Name(),
-			Type: m.Kind(),
+			Id:     m.Name(),
+			Type:   m.Kind(),
+			Labels: m.Labels(),
 		}
 
 		switch m.Kind() {
@@ -78,6 +192,17 @@ func (s MetricSender) Send(metrics []*collector.Metric) error {
 				return err
 			}
 			inMetric.Delta = &value
+		case collector.Histogram:
+			value, err := m.HistogramValue()
+			if err != nil {
+				return err
+			}
+			inMetric.Histogram = &rpc.Histogram{
+				Bounds: value.Bounds,
+				Counts: value.Counts,
+				Sum:    value.Sum,
+				Count:  value.Count,
+			}
 		default:
 			continue
 		}
@@ -85,13 +210,119 @@ func (s MetricSender) Send(metrics []*collector.Metric) error {
 		inMetrics = append(inMetrics, inMetric)
 	}
 
+	if s.streaming {
+		return s.sendToStream(&rpc.UpdatesIn{Metrics: inMetrics})
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	_, err := s.client.Updates(ctx, &rpc.UpdatesIn{Metrics: inMetrics})
 	if err != nil {
-		return err
+		return wrapError(err)
 	}
 
 	return nil
 }
+
+// Функция wrapError помечает ошибкой [clientIP.ErrRejected] ответ сервера о некорректном наборе метрик,
+// повторять отправку которого бессмысленно. Ошибки авторизации и подписи остаются обычными, отправку можно повторить.
+func wrapError(err error) error {
+	if status.Code(err) == codes.InvalidArgument {
+		return fmt.Errorf("%w: %w", clientIP.ErrRejected, err)
+	}
+
+	return err
+}
+
+// Метод sendToStream отправляет батч в поток, при необходимости открывая его, и ждет подтверждения сервера.
+// На отправку и подтверждение отводится timeout. Если батч не подтвержден, поток закрывается,
+// следующая отправка откроет новый.
+func (s *MetricSender) sendToStream(in *rpc.UpdatesIn) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.stream == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		stream, err := s.client.StreamUpdates(ctx)
+		if err != nil {
+			cancel()
+			return fmt.Errorf("failed to open stream: %w", err)
+		}
+
+		s.stream = stream
+		s.cancel = cancel
+		s.acks = make(chan *rpc.StreamAck, 1)
+		s.done = make(chan struct{})
+		s.sent = 0
+
+		go s.receiveAcks(stream, cancel, s.acks, s.done)
+	}
+
+	// Отмена контекста потока прерывает Send, если сервер перестал читать поток.
+	timer := time.AfterFunc(timeout, s.cancel)
+	defer timer.Stop()
+
+	err := s.stream.Send(in)
+	if err != nil {
+		s.closeStream()
+		return fmt.Errorf("failed to send to stream: %w", err)
+	}
+
+	s.sent++
+
+	for {
+		select {
+		case ack := <-s.acks:
+			if ack.Batches >= s.sent {
+				return nil
+			}
+		case <-s.done:
+			s.closeStream()
+			return errors.New("stream closed before acknowledgement")
+		}
+	}
+}
+
+// Метод closeStream закрывает текущий поток. Вызывается под блокировкой.
+func (s *MetricSender) closeStream() {
+	s.cancel()
+	s.stream = nil
+}
+
+// Метод receiveAcks читает подтверждения сервера, пока поток не будет закрыт.
+// Подтверждения содержат счетчики с начала потока, поэтому в acks хранится только последнее из них.
+func (s *MetricSender) receiveAcks(
+	stream rpc.MetricService_StreamUpdatesClient,
+	cancel context.CancelFunc,
+	acks chan *rpc.StreamAck,
+	done chan struct{},
+) {
+	for {
+		ack, err := stream.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.log.WithError(err).Error("Stream closed with error")
+			}
+
+			// Отправитель ждет подтверждения под блокировкой, поэтому о закрытии потока сообщаем до ее захвата.
+			close(done)
+
+			s.lock.Lock()
+			if s.stream == stream {
+				cancel()
+				s.stream = nil
+			}
+			s.lock.Unlock()
+
+			return
+		}
+
+		select {
+		case <-acks:
+		default:
+		}
+		acks <- ack
+	}
+}
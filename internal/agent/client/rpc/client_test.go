@@ -0,0 +1,116 @@
+package rpc_test
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/bjlag/go-metrics/internal/agent/client/rpc"
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+	rpcServer "github.com/bjlag/go-metrics/internal/generated/rpc"
+	mockLogger "github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/securety/signature"
+)
+
+type fakeServer struct {
+	rpcServer.UnimplementedMetricServiceServer
+	ack bool
+}
+
+func (s *fakeServer) StreamUpdates(stream rpcServer.MetricService_StreamUpdatesServer) error {
+	ack := &rpcServer.StreamAck{}
+
+	for {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.Send(ack)
+		}
+		if err != nil {
+			return err
+		}
+
+		ack.Batches++
+		ack.Metrics += int64(len(in.Metrics))
+
+		if s.ack {
+			if err = stream.Send(ack); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func startServer(t *testing.T, srv rpcServer.MetricServiceServer) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	rpcServer.RegisterMetricServiceServer(server, srv)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func TestMetricSender_Send_Stream(t *testing.T) {
+	tests := []struct {
+		name    string
+		ack     bool
+		wantErr bool
+	}{
+		{
+			name: "acknowledged",
+			ack:  true,
+		},
+		{
+			name:    "not acknowledged",
+			ack:     false,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			log := mockLogger.NewMockLogger(ctrl)
+			log.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(log).AnyTimes()
+			log.EXPECT().WithError(gomock.Any()).Return(log).AnyTimes()
+			log.EXPECT().Info(gomock.Any()).AnyTimes()
+			log.EXPECT().Error(gomock.Any()).AnyTimes()
+
+			addr := startServer(t, &fakeServer{ack: tt.ack})
+
+			sender, err := rpc.NewSender(addr, signature.NewSignManager(""), log,
+				rpc.WithStream(),
+				rpc.WithIP(net.ParseIP("127.0.0.1")),
+			)
+			require.NoError(t, err)
+			defer func() {
+				_ = sender.Close()
+			}()
+
+			metrics := []*collector.Metric{collector.NewCounterMetric("PollCount", 1)}
+
+			for i := 0; i < 2; i++ {
+				err = sender.Send(metrics)
+				if tt.wantErr {
+					assert.Error(t, err)
+					continue
+				}
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package collector
+
+import "github.com/bjlag/go-metrics/internal/logger"
+
+type log interface {
+	WithField(key string, value interface{}) logger.Logger
+	WithError(err error) logger.Logger
+	Error(msg string)
+}
@@ -0,0 +1,97 @@
+package collector
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/shirou/gopsutil/cpu"
+)
+
+// CPUName название сборщика метрик процессора.
+const CPUName = "cpu"
+
+// CPUTimesFunc возвращает накопленное время работы каждого логического ядра.
+type CPUTimesFunc func() ([]cpu.TimesStat, error)
+
+// CPUCollector собирает загрузку каждого логического ядра в процентах: CPUutilization1..N.
+//
+// Загрузка считается по приращению времени работы ядер между двумя опросами, поэтому первый опрос
+// только запоминает начальные значения и метрик не возвращает. Сборщик опрашивается [Runner]
+// в своей горутине, параллельно со сбором статистики рантайма.
+type CPUCollector struct {
+	times CPUTimesFunc
+
+	lock sync.Mutex
+	prev []cpu.TimesStat
+}
+
+// NewCPUCollector создает сборщик метрик процессора. Функция times возвращает время работы ядер,
+// если она не задана, время читается из системы.
+func NewCPUCollector(times CPUTimesFunc) *CPUCollector {
+	if times == nil {
+		times = func() ([]cpu.TimesStat, error) {
+			return cpu.Times(true)
+		}
+	}
+
+	return &CPUCollector{
+		times: times,
+	}
+}
+
+func (c *CPUCollector) Name() string {
+	return CPUName
+}
+
+func (c *CPUCollector) Collect() ([]*Metric, error) {
+	current, err := c.times()
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	prev := c.prev
+	c.prev = current
+
+	// Количество ядер могло измениться, например, при горячем подключении. Начинаем отсчет заново.
+	if len(prev) != len(current) {
+		return nil, nil
+	}
+
+	metrics := make([]*Metric, 0, len(current))
+	for i := range current {
+		metrics = append(metrics, NewGaugeMetric(
+			fmt.Sprintf("CPUutilization%d", i+1),
+			utilization(prev[i], current[i]),
+		))
+	}
+
+	return metrics, nil
+}
+
+// Функция utilization возвращает загрузку ядра в процентах за период между замерами prev и current.
+func utilization(prev, current cpu.TimesStat) float64 {
+	busy := busyTime(current) - busyTime(prev)
+	total := busy + (idleTime(current) - idleTime(prev))
+
+	if total <= 0 || busy <= 0 {
+		return 0
+	}
+
+	if busy >= total {
+		return 100
+	}
+
+	return busy / total * 100
+}
+
+// Время Guest и GuestNice уже учтено в User и Nice, поэтому отдельно не суммируется.
+func busyTime(t cpu.TimesStat) float64 {
+	return t.User + t.System + t.Nice + t.Irq + t.Softirq + t.Steal
+}
+
+func idleTime(t cpu.TimesStat) float64 {
+	return t.Idle + t.Iowait
+}
@@ -0,0 +1,48 @@
+package collector_test
+
+import (
+	"testing"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+)
+
+func TestCPUCollector_Collect(t *testing.T) {
+	samples := [][]cpu.TimesStat{
+		{
+			{CPU: "cpu0", User: 10, System: 10, Idle: 80},
+			{CPU: "cpu1", User: 50, Idle: 50},
+		},
+		{
+			{CPU: "cpu0", User: 40, System: 20, Idle: 140},
+			{CPU: "cpu1", User: 50, Idle: 150},
+		},
+	}
+
+	c := collector.NewCPUCollector(func() ([]cpu.TimesStat, error) {
+		sample := samples[0]
+		samples = samples[1:]
+		return sample, nil
+	})
+
+	metrics, err := c.Collect()
+	require.NoError(t, err)
+	assert.Empty(t, metrics)
+
+	metrics, err = c.Collect()
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	assert.Equal(t, "CPUutilization1", metrics[0].Name())
+	value, err := metrics[0].GaugeValue()
+	require.NoError(t, err)
+	assert.InDelta(t, 40, value, 1e-9)
+
+	assert.Equal(t, "CPUutilization2", metrics[1].Name())
+	value, err = metrics[1].GaugeValue()
+	require.NoError(t, err)
+	assert.InDelta(t, 0, value, 1e-9)
+}
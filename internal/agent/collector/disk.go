@@ -0,0 +1,135 @@
+package collector
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/shirou/gopsutil/disk"
+)
+
+// DiskName название сборщика метрик дисков.
+const DiskName = "disk"
+
+// DiskProvider источник статистики дисков.
+type DiskProvider interface {
+	Partitions() ([]disk.PartitionStat, error)
+	Usage(path string) (*disk.UsageStat, error)
+	IOCounters() (map[string]disk.IOCountersStat, error)
+}
+
+// DiskOptions параметры сборщика метрик дисков.
+type DiskOptions struct {
+	// Mounts точки монтирования, по которым собирается заполненность. Пустой список означает все.
+	Mounts []string `json:"mounts"`
+	// Devices устройства, по которым собираются счетчики ввода-вывода. Пустой список означает все.
+	Devices []string `json:"devices"`
+}
+
+// DiskCollector собирает заполненность каждой точки монтирования (метка mount)
+// и накопленные счетчики ввода-вывода каждого устройства (метка device).
+// Точки монтирования, заполненность которых прочитать не удалось (например, нет прав), пропускаются.
+// Если не удалось прочитать счетчики ввода-вывода (например, в контейнере без /proc/diskstats),
+// сборщик возвращает только заполненность без ошибки.
+type DiskCollector struct {
+	provider DiskProvider
+	options  DiskOptions
+}
+
+// NewDiskCollector создает сборщик метрик дисков. Если provider не задан, статистика читается через gopsutil.
+func NewDiskCollector(provider DiskProvider, options DiskOptions) *DiskCollector {
+	if provider == nil {
+		provider = gopsutilDisk{}
+	}
+
+	return &DiskCollector{
+		provider: provider,
+		options:  options,
+	}
+}
+
+func newDiskCollector(options json.RawMessage) (Collector, error) {
+	var opts DiskOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	return NewDiskCollector(nil, opts), nil
+}
+
+func (c *DiskCollector) Name() string {
+	return DiskName
+}
+
+func (c *DiskCollector) Collect() ([]*Metric, error) {
+	partitions, err := c.provider.Partitions()
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics []*Metric
+
+	for _, p := range partitions {
+		if !contains(c.options.Mounts, p.Mountpoint) {
+			continue
+		}
+
+		usage, err := c.provider.Usage(p.Mountpoint)
+		if err != nil {
+			continue
+		}
+
+		labels := map[string]string{"mount": p.Mountpoint}
+		metrics = append(metrics,
+			NewGaugeMetric("DiskTotal", usage.Total).WithLabels(labels),
+			NewGaugeMetric("DiskFree", usage.Free).WithLabels(labels),
+			NewGaugeMetric("DiskUsed", usage.Used).WithLabels(labels),
+			NewGaugeMetric("DiskUsedPercent", usage.UsedPercent).WithLabels(labels),
+		)
+	}
+
+	counters, err := c.provider.IOCounters()
+	if err != nil {
+		return metrics, nil
+	}
+
+	for _, name := range sortedKeys(counters) {
+		if !contains(c.options.Devices, name) {
+			continue
+		}
+
+		io := counters[name]
+		labels := map[string]string{"device": name}
+		metrics = append(metrics,
+			NewGaugeMetric("DiskReadBytes", io.ReadBytes).WithLabels(labels),
+			NewGaugeMetric("DiskWriteBytes", io.WriteBytes).WithLabels(labels),
+			NewGaugeMetric("DiskReads", io.ReadCount).WithLabels(labels),
+			NewGaugeMetric("DiskWrites", io.WriteCount).WithLabels(labels),
+		)
+	}
+
+	return metrics, nil
+}
+
+type gopsutilDisk struct{}
+
+func (gopsutilDisk) Partitions() ([]disk.PartitionStat, error) {
+	return disk.Partitions(false)
+}
+
+func (gopsutilDisk) Usage(path string) (*disk.UsageStat, error) {
+	return disk.Usage(path)
+}
+
+func (gopsutilDisk) IOCounters() (map[string]disk.IOCountersStat, error) {
+	return disk.IOCounters()
+}
+
+func sortedKeys(counters map[string]disk.IOCountersStat) []string {
+	keys := make([]string, 0, len(counters))
+	for key := range counters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// FDsName название сборщика метрик файловых дескрипторов.
+const FDsName = "fds"
+
+const fileNrPath = "/proc/sys/fs/file-nr"
+
+// FDsFunc возвращает количество открытых файловых дескрипторов в системе и их максимальное количество.
+type FDsFunc func() (open uint64, max uint64, err error)
+
+// FDsCollector собирает количество открытых файловых дескрипторов в системе и их лимит.
+// По умолчанию значения читаются из /proc/sys/fs/file-nr, поэтому сборщик работает только в Linux.
+type FDsCollector struct {
+	fds FDsFunc
+}
+
+// NewFDsCollector создает сборщик метрик файловых дескрипторов.
+func NewFDsCollector(fds FDsFunc) *FDsCollector {
+	if fds == nil {
+		fds = readFileNr
+	}
+
+	return &FDsCollector{
+		fds: fds,
+	}
+}
+
+func (c *FDsCollector) Name() string {
+	return FDsName
+}
+
+func (c *FDsCollector) Collect() ([]*Metric, error) {
+	open, max, err := c.fds()
+	if err != nil {
+		return nil, err
+	}
+
+	return []*Metric{
+		NewGaugeMetric("OpenFDs", open),
+		NewGaugeMetric("MaxFDs", max),
+	}, nil
+}
+
+// Функция readFileNr читает file-nr: количество выделенных дескрипторов, свободных среди них и максимум.
+func readFileNr() (uint64, uint64, error) {
+	data, err := os.ReadFile(fileNrPath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) != 3 {
+		return 0, 0, fmt.Errorf("unexpected format of %s", fileNrPath)
+	}
+
+	values := make([]uint64, len(fields))
+	for i, f := range fields {
+		values[i], err = strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("unexpected format of %s: %w", fileNrPath, err)
+		}
+	}
+
+	if values[1] > values[0] {
+		return 0, values[2], nil
+	}
+
+	return values[0] - values[1], values[2], nil
+}
@@ -0,0 +1,154 @@
+package collector_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/load"
+	"github.com/shirou/gopsutil/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+)
+
+type fakeDisk struct {
+	failMount string
+	failIO    bool
+}
+
+func (fakeDisk) Partitions() ([]disk.PartitionStat, error) {
+	return []disk.PartitionStat{
+		{Device: "/dev/sda1", Mountpoint: "/"},
+		{Device: "/dev/sdb1", Mountpoint: "/data"},
+	}, nil
+}
+
+func (d fakeDisk) Usage(path string) (*disk.UsageStat, error) {
+	if path == d.failMount {
+		return nil, errors.New("permission denied")
+	}
+
+	return &disk.UsageStat{Path: path, Total: 100, Free: 40, Used: 60, UsedPercent: 60}, nil
+}
+
+func (d fakeDisk) IOCounters() (map[string]disk.IOCountersStat, error) {
+	if d.failIO {
+		return nil, errors.New("no such file or directory")
+	}
+
+	return map[string]disk.IOCountersStat{
+		"sdb": {Name: "sdb", ReadBytes: 3, WriteBytes: 4, ReadCount: 1, WriteCount: 2},
+		"sda": {Name: "sda", ReadBytes: 30, WriteBytes: 40, ReadCount: 10, WriteCount: 20},
+	}, nil
+}
+
+func TestDiskCollector_Collect(t *testing.T) {
+	c := collector.NewDiskCollector(fakeDisk{}, collector.DiskOptions{Mounts: []string{"/data"}})
+
+	metrics, err := c.Collect()
+	require.NoError(t, err)
+	require.Len(t, metrics, 12)
+
+	assert.Equal(t, collector.NewGaugeMetric("DiskTotal", uint64(100)).WithLabels(map[string]string{"mount": "/data"}), metrics[0])
+	assert.Equal(t, collector.NewGaugeMetric("DiskUsedPercent", float64(60)).WithLabels(map[string]string{"mount": "/data"}), metrics[3])
+	assert.Equal(t, collector.NewGaugeMetric("DiskReadBytes", uint64(30)).WithLabels(map[string]string{"device": "sda"}), metrics[4])
+	assert.Equal(t, collector.NewGaugeMetric("DiskWrites", uint64(2)).WithLabels(map[string]string{"device": "sdb"}), metrics[11])
+}
+
+func TestDiskCollector_Collect_SkipsFailedMount(t *testing.T) {
+	c := collector.NewDiskCollector(fakeDisk{failMount: "/"}, collector.DiskOptions{Devices: []string{"sda"}})
+
+	metrics, err := c.Collect()
+	require.NoError(t, err)
+	require.Len(t, metrics, 8)
+
+	assert.Equal(t, collector.NewGaugeMetric("DiskTotal", uint64(100)).WithLabels(map[string]string{"mount": "/data"}), metrics[0])
+	assert.Equal(t, collector.NewGaugeMetric("DiskReadBytes", uint64(30)).WithLabels(map[string]string{"device": "sda"}), metrics[4])
+}
+
+func TestDiskCollector_Collect_SkipsFailedIOCounters(t *testing.T) {
+	c := collector.NewDiskCollector(fakeDisk{failIO: true}, collector.DiskOptions{Mounts: []string{"/data"}})
+
+	metrics, err := c.Collect()
+	require.NoError(t, err)
+	require.Len(t, metrics, 4)
+
+	assert.Equal(t, collector.NewGaugeMetric("DiskTotal", uint64(100)).WithLabels(map[string]string{"mount": "/data"}), metrics[0])
+}
+
+func TestNetCollector_Collect(t *testing.T) {
+	c := collector.NewNetCollector(func() ([]net.IOCountersStat, error) {
+		return []net.IOCountersStat{
+			{Name: "lo", BytesSent: 1},
+			{Name: "eth0", BytesSent: 10, BytesRecv: 20, PacketsSent: 1, PacketsRecv: 2, Errin: 3, Errout: 4},
+		}, nil
+	}, collector.NetOptions{Interfaces: []string{"eth0"}})
+
+	metrics, err := c.Collect()
+	require.NoError(t, err)
+
+	labels := map[string]string{"interface": "eth0"}
+	assert.Equal(t, []*collector.Metric{
+		collector.NewGaugeMetric("NetBytesSent", uint64(10)).WithLabels(labels),
+		collector.NewGaugeMetric("NetBytesRecv", uint64(20)).WithLabels(labels),
+		collector.NewGaugeMetric("NetPacketsSent", uint64(1)).WithLabels(labels),
+		collector.NewGaugeMetric("NetPacketsRecv", uint64(2)).WithLabels(labels),
+		collector.NewGaugeMetric("NetErrIn", uint64(3)).WithLabels(labels),
+		collector.NewGaugeMetric("NetErrOut", uint64(4)).WithLabels(labels),
+	}, metrics)
+}
+
+func TestLoadCollector_Collect(t *testing.T) {
+	c := collector.NewLoadCollector(func() (*load.AvgStat, error) {
+		return &load.AvgStat{Load1: 1.5, Load5: 1, Load15: 0.5}, nil
+	})
+
+	metrics, err := c.Collect()
+	require.NoError(t, err)
+
+	assert.Equal(t, []*collector.Metric{
+		collector.NewGaugeMetric("Load1", 1.5),
+		collector.NewGaugeMetric("Load5", float64(1)),
+		collector.NewGaugeMetric("Load15", 0.5),
+	}, metrics)
+
+	_, err = collector.NewLoadCollector(func() (*load.AvgStat, error) {
+		return nil, errors.New("not supported")
+	}).Collect()
+	assert.Error(t, err)
+}
+
+func TestFDsCollector_Collect(t *testing.T) {
+	c := collector.NewFDsCollector(func() (uint64, uint64, error) {
+		return 128, 1024, nil
+	})
+
+	metrics, err := c.Collect()
+	require.NoError(t, err)
+
+	assert.Equal(t, []*collector.Metric{
+		collector.NewGaugeMetric("OpenFDs", uint64(128)),
+		collector.NewGaugeMetric("MaxFDs", uint64(1024)),
+	}, metrics)
+}
+
+func TestDefaultRegistry_HostCollectorsDisabled(t *testing.T) {
+	collectors, err := collector.NewDefaultRegistry().Build(nil, time.Second)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(collectors))
+	for _, c := range collectors {
+		names = append(names, c.Collector.Name())
+	}
+	assert.Equal(t, []string{collector.RuntimeName, collector.MemoryName, collector.CPUName}, names)
+
+	var configs map[string]collector.Config
+	require.NoError(t, json.Unmarshal([]byte(`{"disk": {"enabled": true, "options": {"unknown": 1}}}`), &configs))
+
+	_, err = collector.NewDefaultRegistry().Build(configs, time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,44 @@
+package collector
+
+import (
+	"github.com/shirou/gopsutil/load"
+)
+
+// LoadName название сборщика средней загрузки системы.
+const LoadName = "load"
+
+// LoadAvgFunc возвращает среднюю загрузку системы.
+type LoadAvgFunc func() (*load.AvgStat, error)
+
+// LoadCollector собирает среднюю загрузку системы за 1, 5 и 15 минут.
+type LoadCollector struct {
+	avg LoadAvgFunc
+}
+
+// NewLoadCollector создает сборщик средней загрузки. Если avg не задана, загрузка читается через gopsutil.
+func NewLoadCollector(avg LoadAvgFunc) *LoadCollector {
+	if avg == nil {
+		avg = load.Avg
+	}
+
+	return &LoadCollector{
+		avg: avg,
+	}
+}
+
+func (c *LoadCollector) Name() string {
+	return LoadName
+}
+
+func (c *LoadCollector) Collect() ([]*Metric, error) {
+	avg, err := c.avg()
+	if err != nil {
+		return nil, err
+	}
+
+	return []*Metric{
+		NewGaugeMetric("Load1", avg.Load1),
+		NewGaugeMetric("Load5", avg.Load5),
+		NewGaugeMetric("Load15", avg.Load15),
+	}, nil
+}
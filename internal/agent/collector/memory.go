@@ -0,0 +1,32 @@
+package collector
+
+import (
+	"github.com/shirou/gopsutil/mem"
+)
+
+// MemoryName название сборщика метрик памяти хоста.
+const MemoryName = "memory"
+
+// MemoryCollector собирает метрики виртуальной памяти хоста.
+type MemoryCollector struct{}
+
+// NewMemoryCollector создает сборщик метрик памяти.
+func NewMemoryCollector() *MemoryCollector {
+	return &MemoryCollector{}
+}
+
+func (c *MemoryCollector) Name() string {
+	return MemoryName
+}
+
+func (c *MemoryCollector) Collect() ([]*Metric, error) {
+	memStat, err := mem.VirtualMemory()
+	if err != nil {
+		return nil, err
+	}
+
+	return []*Metric{
+		NewGaugeMetric("FreeMemory", memStat.Free),
+		NewGaugeMetric("TotalMemory", memStat.Total),
+	}, nil
+}
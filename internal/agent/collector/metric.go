@@ -3,11 +3,14 @@ package collector
 import (
 	"fmt"
 	"strconv"
+
+	"github.com/bjlag/go-metrics/internal/model"
 )
 
 const (
-	Gauge   = "gauge"
-	Counter = "counter"
+	Gauge     = "gauge"
+	Counter   = "counter"
+	Histogram = "histogram"
 )
 
 // Metric представление метрики.
@@ -18,6 +21,8 @@ type Metric struct {
 	name string
 	// value значение метрики.
 	value interface{}
+	// labels метки метрики.
+	labels map[string]string
 }
 
 // NewMetric создает метрику.
@@ -39,6 +44,29 @@ func NewGaugeMetric(name string, value interface{}) *Metric {
 	return NewMetric(Gauge, name, value)
 }
 
+// WithLabels добавляет метрике метки. Уже заданные метки с теми же названиями перезаписываются.
+func (m *Metric) WithLabels(labels map[string]string) *Metric {
+	if len(labels) == 0 {
+		return m
+	}
+
+	merged := make(map[string]string, len(m.labels)+len(labels))
+	for name, value := range m.labels {
+		merged[name] = value
+	}
+	for name, value := range labels {
+		merged[name] = value
+	}
+	m.labels = merged
+
+	return m
+}
+
+// NewHistogramMetric создает метрику типа [Histogram].
+func NewHistogramMetric(name string, value model.Histogram) *Metric {
+	return NewMetric(Histogram, name, value)
+}
+
 // Kind возвращает тип метрики.
 func (m Metric) Kind() string {
 	return m.mType
@@ -49,6 +77,11 @@ func (m Metric) Name() string {
 	return m.name
 }
 
+// Labels возвращает метки метрики.
+func (m Metric) Labels() map[string]string {
+	return m.labels
+}
+
 // Value возвращает значение метрики.
 func (m Metric) Value() interface{} {
 	return m.value
@@ -101,3 +134,18 @@ func (m Metric) CounterValue() (int64, error) {
 		return 0, fmt.Errorf("unknow type value: %s", v)
 	}
 }
+
+// HistogramValue возвращает значение метрики типа [Histogram].
+func (m Metric) HistogramValue() (model.Histogram, error) {
+	switch v := m.value.(type) {
+	case model.Histogram:
+		return v, nil
+	case *model.Histogram:
+		if v == nil {
+			return model.Histogram{}, fmt.Errorf("histogram value is nil")
+		}
+		return *v, nil
+	default:
+		return model.Histogram{}, fmt.Errorf("unknow type value: %s", v)
+	}
+}
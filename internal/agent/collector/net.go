@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"encoding/json"
+
+	"github.com/shirou/gopsutil/net"
+)
+
+// NetName название сборщика метрик сетевых интерфейсов.
+const NetName = "net"
+
+// NetIOCountersFunc возвращает накопленные счетчики каждого сетевого интерфейса.
+type NetIOCountersFunc func() ([]net.IOCountersStat, error)
+
+// NetOptions параметры сборщика метрик сетевых интерфейсов.
+type NetOptions struct {
+	// Interfaces интерфейсы, по которым собираются метрики. Пустой список означает все.
+	Interfaces []string `json:"interfaces"`
+}
+
+// NetCollector собирает накопленные счетчики байт, пакетов и ошибок каждого сетевого интерфейса (метка interface).
+type NetCollector struct {
+	counters NetIOCountersFunc
+	options  NetOptions
+}
+
+// NewNetCollector создает сборщик метрик сетевых интерфейсов.
+// Если counters не задана, счетчики читаются через gopsutil.
+func NewNetCollector(counters NetIOCountersFunc, options NetOptions) *NetCollector {
+	if counters == nil {
+		counters = func() ([]net.IOCountersStat, error) {
+			return net.IOCounters(true)
+		}
+	}
+
+	return &NetCollector{
+		counters: counters,
+		options:  options,
+	}
+}
+
+func newNetCollector(options json.RawMessage) (Collector, error) {
+	var opts NetOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	return NewNetCollector(nil, opts), nil
+}
+
+func (c *NetCollector) Name() string {
+	return NetName
+}
+
+func (c *NetCollector) Collect() ([]*Metric, error) {
+	counters, err := c.counters()
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics []*Metric
+
+	for _, io := range counters {
+		if !contains(c.options.Interfaces, io.Name) {
+			continue
+		}
+
+		labels := map[string]string{"interface": io.Name}
+		metrics = append(metrics,
+			NewGaugeMetric("NetBytesSent", io.BytesSent).WithLabels(labels),
+			NewGaugeMetric("NetBytesRecv", io.BytesRecv).WithLabels(labels),
+			NewGaugeMetric("NetPacketsSent", io.PacketsSent).WithLabels(labels),
+			NewGaugeMetric("NetPacketsRecv", io.PacketsRecv).WithLabels(labels),
+			NewGaugeMetric("NetErrIn", io.Errin).WithLabels(labels),
+			NewGaugeMetric("NetErrOut", io.Errout).WithLabels(labels),
+		)
+	}
+
+	return metrics, nil
+}
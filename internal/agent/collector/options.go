@@ -0,0 +1,40 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Функция decodeOptions разбирает параметры сборщика из конфигурации агента в v.
+// Незнакомые параметры считаются ошибкой, чтобы опечатки в конфигурации не проходили незамеченными.
+func decodeOptions(options json.RawMessage, v any) error {
+	if len(options) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(options))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+
+	return nil
+}
+
+// Функция contains возвращает true, если фильтр пустой или value в нем есть.
+func contains(filter []string, value string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+
+	for _, f := range filter {
+		if f == value {
+			return true
+		}
+	}
+
+	return false
+}
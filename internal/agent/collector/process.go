@@ -0,0 +1,328 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+// ProcessName название сборщика метрик процессов.
+const ProcessName = "process"
+
+// ProcessStat статистика процесса.
+type ProcessStat struct {
+	// CreateTime время запуска процесса в миллисекундах от начала эпохи. Вместе с PID однозначно определяет процесс.
+	CreateTime int64
+	// RSS размер резидентной памяти в байтах.
+	RSS uint64
+	// CPUTime суммарное процессорное время в секундах.
+	CPUTime float64
+	// Threads количество потоков.
+	Threads int32
+	// FDs количество открытых файловых дескрипторов, nil, если его не удалось прочитать.
+	FDs *int32
+}
+
+// ProcessProvider источник статистики процессов.
+type ProcessProvider interface {
+	// Names возвращает названия всех запущенных процессов по их PID.
+	Names() (map[int32]string, error)
+	// Stat возвращает статистику процесса. Если процесс уже завершился, возвращает ошибку.
+	Stat(pid int32) (ProcessStat, error)
+}
+
+// ProcessOptions параметры сборщика метрик процессов.
+type ProcessOptions struct {
+	// Names шаблоны названий процессов в формате [path.Match], например "nginx*".
+	Names []string `json:"names"`
+	// PIDFiles файлы, в которых записан PID процесса.
+	PIDFiles []string `json:"pid_files"`
+	// PIDLabel добавляет к метрикам метку pid. Каждый перезапуск процесса при этом порождает новые серии.
+	PIDLabel bool `json:"pid_label"`
+}
+
+// ProcessCollector собирает метрики выбранных процессов: ProcessRSS, ProcessCPUPercent, ProcessThreads и ProcessOpenFDs.
+// Метрики помечаются меткой process (название процесса), значения процессов с одинаковым названием суммируются.
+// Если включен [ProcessOptions.PIDLabel], метрики отдаются по каждому процессу отдельно с меткой pid.
+// ProcessOpenFDs не отдается, если дескрипторы не удалось прочитать ни у одного из процессов.
+//
+// Процессы выбираются заново при каждом опросе, поэтому запущенные между опросами процессы появляются в метриках,
+// а завершившиеся пропадают. Загрузка процессора считается за период между опросами,
+// для нового процесса — за все время его работы.
+type ProcessCollector struct {
+	provider ProcessProvider
+	options  ProcessOptions
+	now      func() time.Time
+
+	lock sync.Mutex
+	prev map[int32]processSample
+}
+
+type processTotals struct {
+	labels  map[string]string
+	rss     uint64
+	cpu     float64
+	threads int32
+	fds     *int32
+}
+
+type processSample struct {
+	createTime int64
+	cpuTime    float64
+	at         time.Time
+}
+
+// NewProcessCollector создает сборщик метрик процессов. Если provider не задан, статистика читается через gopsutil.
+func NewProcessCollector(provider ProcessProvider, options ProcessOptions, now func() time.Time) (*ProcessCollector, error) {
+	if len(options.Names) == 0 && len(options.PIDFiles) == 0 {
+		return nil, fmt.Errorf("process names or pid files must be specified")
+	}
+
+	for _, pattern := range options.Names {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid process name pattern %q: %w", pattern, err)
+		}
+	}
+
+	if provider == nil {
+		provider = gopsutilProcess{}
+	}
+
+	if now == nil {
+		now = time.Now
+	}
+
+	return &ProcessCollector{
+		provider: provider,
+		options:  options,
+		now:      now,
+		prev:     make(map[int32]processSample),
+	}, nil
+}
+
+func newProcessCollector(options json.RawMessage) (Collector, error) {
+	var opts ProcessOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	return NewProcessCollector(nil, opts, nil)
+}
+
+func (c *ProcessCollector) Name() string {
+	return ProcessName
+}
+
+func (c *ProcessCollector) Collect() ([]*Metric, error) {
+	names, err := c.provider.Names()
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	current := make(map[int32]processSample)
+
+	var keys []string
+	totals := make(map[string]*processTotals)
+
+	for _, pid := range c.selectPIDs(names) {
+		stat, err := c.provider.Stat(pid)
+		if err != nil {
+			// Процесс завершился между получением списка и чтением статистики.
+			continue
+		}
+
+		sample := processSample{
+			createTime: stat.CreateTime,
+			cpuTime:    stat.CPUTime,
+			at:         now,
+		}
+		current[pid] = sample
+
+		key := names[pid]
+		labels := map[string]string{"process": names[pid]}
+		if c.options.PIDLabel {
+			key += "/" + strconv.Itoa(int(pid))
+			labels["pid"] = strconv.Itoa(int(pid))
+		}
+
+		t, ok := totals[key]
+		if !ok {
+			t = &processTotals{labels: labels}
+			totals[key] = t
+			keys = append(keys, key)
+		}
+
+		t.rss += stat.RSS
+		t.cpu += c.cpuPercent(pid, sample)
+		t.threads += stat.Threads
+		if stat.FDs != nil {
+			fds := *stat.FDs
+			if t.fds != nil {
+				fds += *t.fds
+			}
+			t.fds = &fds
+		}
+	}
+
+	c.prev = current
+
+	metrics := make([]*Metric, 0, len(keys)*4)
+	for _, key := range keys {
+		t := totals[key]
+		metrics = append(metrics,
+			NewGaugeMetric("ProcessRSS", t.rss).WithLabels(t.labels),
+			NewGaugeMetric("ProcessCPUPercent", t.cpu).WithLabels(t.labels),
+			NewGaugeMetric("ProcessThreads", t.threads).WithLabels(t.labels),
+		)
+
+		if t.fds != nil {
+			metrics = append(metrics, NewGaugeMetric("ProcessOpenFDs", *t.fds).WithLabels(t.labels))
+		}
+	}
+
+	return metrics, nil
+}
+
+// Метод selectPIDs возвращает отсортированные PID процессов, подходящих под шаблоны названий или записанных в PID файлах.
+func (c *ProcessCollector) selectPIDs(names map[int32]string) []int32 {
+	selected := make(map[int32]struct{})
+
+	for pid, name := range names {
+		for _, pattern := range c.options.Names {
+			if ok, _ := path.Match(pattern, name); ok {
+				selected[pid] = struct{}{}
+				break
+			}
+		}
+	}
+
+	for _, file := range c.options.PIDFiles {
+		pid, err := readPIDFile(file)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := names[pid]; ok {
+			selected[pid] = struct{}{}
+		}
+	}
+
+	pids := make([]int32, 0, len(selected))
+	for pid := range selected {
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool {
+		return pids[i] < pids[j]
+	})
+
+	return pids
+}
+
+// Метод cpuPercent возвращает загрузку процессора процессом в процентах одного ядра.
+// Если PID процесса переиспользован, процесс считается новым.
+func (c *ProcessCollector) cpuPercent(pid int32, sample processSample) float64 {
+	prev, ok := c.prev[pid]
+	if !ok || prev.createTime != sample.createTime {
+		prev = processSample{at: time.UnixMilli(sample.createTime)}
+	}
+
+	elapsed := sample.at.Sub(prev.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+
+	percent := (sample.cpuTime - prev.cpuTime) / elapsed * 100
+	if percent < 0 {
+		return 0
+	}
+
+	return percent
+}
+
+func readPIDFile(file string) (int32, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+
+	pid, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid pid file %s: %w", file, err)
+	}
+
+	return int32(pid), nil
+}
+
+type gopsutilProcess struct{}
+
+func (gopsutilProcess) Names() (map[int32]string, error) {
+	processes, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int32]string, len(processes))
+	for _, p := range processes {
+		name, err := p.Name()
+		if err != nil {
+			continue
+		}
+
+		names[p.Pid] = name
+	}
+
+	return names, nil
+}
+
+func (gopsutilProcess) Stat(pid int32) (ProcessStat, error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return ProcessStat{}, err
+	}
+
+	createTime, err := p.CreateTime()
+	if err != nil {
+		return ProcessStat{}, err
+	}
+
+	memory, err := p.MemoryInfo()
+	if err != nil {
+		return ProcessStat{}, err
+	}
+
+	times, err := p.Times()
+	if err != nil {
+		return ProcessStat{}, err
+	}
+
+	threads, err := p.NumThreads()
+	if err != nil {
+		return ProcessStat{}, err
+	}
+
+	stat := ProcessStat{
+		CreateTime: createTime,
+		RSS:        memory.RSS,
+		CPUTime:    times.User + times.System,
+		Threads:    threads,
+	}
+
+	// Дескрипторы чужих процессов могут быть недоступны без прав, остальные метрики при этом собираются.
+	if fds, err := p.NumFDs(); err == nil {
+		stat.FDs = &fds
+	}
+
+	return stat, nil
+}
@@ -0,0 +1,150 @@
+package collector_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+)
+
+type fakeProcesses struct {
+	names map[int32]string
+	stats map[int32]collector.ProcessStat
+}
+
+func (p *fakeProcesses) Names() (map[int32]string, error) {
+	return p.names, nil
+}
+
+func (p *fakeProcesses) Stat(pid int32) (collector.ProcessStat, error) {
+	stat, ok := p.stats[pid]
+	if !ok {
+		return collector.ProcessStat{}, errors.New("process not found")
+	}
+
+	return stat, nil
+}
+
+func TestProcessCollector_Collect(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(10 * time.Second)
+
+	pidFile := filepath.Join(t.TempDir(), "server.pid")
+	require.NoError(t, os.WriteFile(pidFile, []byte("300\n"), 0o600))
+
+	provider := &fakeProcesses{
+		names: map[int32]string{100: "nginx", 101: "nginx-worker", 200: "bash", 300: "server"},
+		stats: map[int32]collector.ProcessStat{
+			100: {CreateTime: start.UnixMilli(), RSS: 1024, CPUTime: 5, Threads: 2, FDs: fds(10)},
+			300: {CreateTime: start.UnixMilli(), RSS: 2048, CPUTime: 1, Threads: 8, FDs: fds(20)},
+		},
+	}
+
+	c, err := collector.NewProcessCollector(provider, collector.ProcessOptions{
+		Names:    []string{"nginx*"},
+		PIDFiles: []string{pidFile},
+	}, func() time.Time {
+		return now
+	})
+	require.NoError(t, err)
+
+	// Процесс 101 завершился между получением списка и чтением статистики.
+	metrics, err := c.Collect()
+	require.NoError(t, err)
+	require.Len(t, metrics, 8)
+
+	nginx := map[string]string{"process": "nginx"}
+	assert.Equal(t, collector.NewGaugeMetric("ProcessRSS", uint64(1024)).WithLabels(nginx), metrics[0])
+	assert.Equal(t, collector.NewGaugeMetric("ProcessCPUPercent", float64(50)).WithLabels(nginx), metrics[1])
+	assert.Equal(t, collector.NewGaugeMetric("ProcessThreads", int32(2)).WithLabels(nginx), metrics[2])
+	assert.Equal(t, collector.NewGaugeMetric("ProcessOpenFDs", int32(10)).WithLabels(nginx), metrics[3])
+	assert.Equal(t, map[string]string{"process": "server"}, metrics[4].Labels())
+
+	// Процесс 100 отработал секунду из двух, процесс 300 завершился, PID 100 не переиспользован.
+	now = now.Add(2 * time.Second)
+	stat := provider.stats[100]
+	stat.CPUTime = 6
+	provider.stats[100] = stat
+	delete(provider.stats, 300)
+	delete(provider.names, 300)
+
+	metrics, err = c.Collect()
+	require.NoError(t, err)
+	require.Len(t, metrics, 4)
+	assert.Equal(t, collector.NewGaugeMetric("ProcessCPUPercent", float64(50)).WithLabels(nginx), metrics[1])
+}
+
+func TestProcessCollector_Collect_SameName(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(10 * time.Second)
+
+	provider := &fakeProcesses{
+		names: map[int32]string{100: "nginx", 101: "nginx"},
+		stats: map[int32]collector.ProcessStat{
+			100: {CreateTime: start.UnixMilli(), RSS: 1024, CPUTime: 5, Threads: 2, FDs: fds(10)},
+			101: {CreateTime: start.UnixMilli(), RSS: 512, CPUTime: 1, Threads: 1},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		options collector.ProcessOptions
+		want    []*collector.Metric
+	}{
+		{
+			name:    "summed by name",
+			options: collector.ProcessOptions{Names: []string{"nginx"}},
+			want: []*collector.Metric{
+				collector.NewGaugeMetric("ProcessRSS", uint64(1536)).WithLabels(map[string]string{"process": "nginx"}),
+				collector.NewGaugeMetric("ProcessCPUPercent", float64(60)).WithLabels(map[string]string{"process": "nginx"}),
+				collector.NewGaugeMetric("ProcessThreads", int32(3)).WithLabels(map[string]string{"process": "nginx"}),
+				collector.NewGaugeMetric("ProcessOpenFDs", int32(10)).WithLabels(map[string]string{"process": "nginx"}),
+			},
+		},
+		{
+			name:    "pid label",
+			options: collector.ProcessOptions{Names: []string{"nginx"}, PIDLabel: true},
+			want: []*collector.Metric{
+				collector.NewGaugeMetric("ProcessRSS", uint64(1024)).WithLabels(map[string]string{"process": "nginx", "pid": "100"}),
+				collector.NewGaugeMetric("ProcessCPUPercent", float64(50)).WithLabels(map[string]string{"process": "nginx", "pid": "100"}),
+				collector.NewGaugeMetric("ProcessThreads", int32(2)).WithLabels(map[string]string{"process": "nginx", "pid": "100"}),
+				collector.NewGaugeMetric("ProcessOpenFDs", int32(10)).WithLabels(map[string]string{"process": "nginx", "pid": "100"}),
+				// Дескрипторы процесса 101 прочитать не удалось, поэтому метрика пропускается.
+				collector.NewGaugeMetric("ProcessRSS", uint64(512)).WithLabels(map[string]string{"process": "nginx", "pid": "101"}),
+				collector.NewGaugeMetric("ProcessCPUPercent", float64(10)).WithLabels(map[string]string{"process": "nginx", "pid": "101"}),
+				collector.NewGaugeMetric("ProcessThreads", int32(1)).WithLabels(map[string]string{"process": "nginx", "pid": "101"}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := collector.NewProcessCollector(provider, tt.options, func() time.Time {
+				return now
+			})
+			require.NoError(t, err)
+
+			metrics, err := c.Collect()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, metrics)
+		})
+	}
+}
+
+func TestNewProcessCollector_Errors(t *testing.T) {
+	_, err := collector.NewProcessCollector(nil, collector.ProcessOptions{}, nil)
+	assert.Error(t, err)
+
+	_, err = collector.NewProcessCollector(nil, collector.ProcessOptions{Names: []string{"["}}, nil)
+	assert.Error(t, err)
+}
+
+func fds(n int32) *int32 {
+	return &n
+}
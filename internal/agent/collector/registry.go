@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Factory создает сборщик по параметрам options из конфигурации агента. Если параметры не заданы, options пустой.
+type Factory func(options json.RawMessage) (Collector, error)
+
+// Config настройки сборщика в конфигурации агента.
+type Config struct {
+	// Enabled включает или выключает сборщик. Если не задан, сборщик включен, если он зарегистрирован включенным.
+	Enabled *bool `json:"enabled,omitempty"`
+	// PollInterval интервал опроса сборщика. Если не задан, используется интервал опроса агента.
+	PollInterval time.Duration `json:"poll_interval,omitempty"`
+	// Options параметры сборщика, их разбирает фабрика сборщика.
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+func (c *Config) UnmarshalJSON(b []byte) error {
+	type alias Config
+
+	aliasValue := &struct {
+		*alias
+		PollInterval *string `json:"poll_interval,omitempty"`
+	}{
+		alias: (*alias)(c),
+	}
+
+	err := json.Unmarshal(b, &aliasValue)
+	if err != nil {
+		return err
+	}
+
+	if aliasValue.PollInterval != nil {
+		c.PollInterval, err = time.ParseDuration(*aliasValue.PollInterval)
+		if err != nil {
+			return fmt.Errorf("parse poll_interval error: %w", err)
+		}
+
+		if c.PollInterval <= 0 {
+			return fmt.Errorf("poll_interval must be positive")
+		}
+	}
+
+	return nil
+}
+
+// Scheduled сборщик вместе с интервалом его опроса.
+type Scheduled struct {
+	Collector    Collector
+	PollInterval time.Duration
+}
+
+// Registry реестр сборщиков метрик агента. Порядок регистрации сохраняется в порядке отправки метрик.
+type Registry struct {
+	entries []registration
+}
+
+type registration struct {
+	name     string
+	factory  Factory
+	disabled bool
+}
+
+// RegisterOption настраивает регистрацию сборщика.
+type RegisterOption func(r *registration)
+
+// Disabled регистрирует сборщик выключенным, его нужно явно включить в конфигурации агента.
+func Disabled() RegisterOption {
+	return func(r *registration) {
+		r.disabled = true
+	}
+}
+
+// NewRegistry создает пустой реестр.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// NewDefaultRegistry создает реестр со встроенными сборщиками агента.
+// Сборщики runtime, memory и cpu включены, сборщики метрик хоста disk, net, load, fds и process выключены.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	r.Register(RuntimeName, func(_ json.RawMessage) (Collector, error) {
+		return NewRuntimeCollector(nil), nil
+	})
+	r.Register(MemoryName, func(_ json.RawMessage) (Collector, error) {
+		return NewMemoryCollector(), nil
+	})
+	r.Register(CPUName, func(_ json.RawMessage) (Collector, error) {
+		return NewCPUCollector(nil), nil
+	})
+	r.Register(DiskName, newDiskCollector, Disabled())
+	r.Register(NetName, newNetCollector, Disabled())
+	r.Register(LoadName, func(_ json.RawMessage) (Collector, error) {
+		return NewLoadCollector(nil), nil
+	}, Disabled())
+	r.Register(FDsName, func(_ json.RawMessage) (Collector, error) {
+		return NewFDsCollector(nil), nil
+	}, Disabled())
+	r.Register(ProcessName, newProcessCollector, Disabled())
+
+	return r
+}
+
+// Register регистрирует фабрику сборщика под названием name. Повторная регистрация заменяет фабрику.
+func (r *Registry) Register(name string, factory Factory, opts ...RegisterOption) {
+	entry := registration{
+		name:    name,
+		factory: factory,
+	}
+
+	for _, opt := range opts {
+		opt(&entry)
+	}
+
+	for i := range r.entries {
+		if r.entries[i].name == name {
+			r.entries[i] = entry
+			return
+		}
+	}
+
+	r.entries = append(r.entries, entry)
+}
+
+// Build создает включенные сборщики по настройкам configs. Ключ configs — название сборщика.
+// Если у сборщика не задан интервал опроса, используется pollInterval.
+// Возвращает ошибку, если в configs есть незарегистрированный сборщик или фабрика вернула ошибку.
+func (r *Registry) Build(configs map[string]Config, pollInterval time.Duration) ([]Scheduled, error) {
+	for name := range configs {
+		if !r.has(name) {
+			return nil, fmt.Errorf("unknown collector: %s", name)
+		}
+	}
+
+	var collectors []Scheduled
+
+	for _, entry := range r.entries {
+		cfg := configs[entry.name]
+
+		enabled := !entry.disabled
+		if cfg.Enabled != nil {
+			enabled = *cfg.Enabled
+		}
+
+		if !enabled {
+			continue
+		}
+
+		c, err := entry.factory(cfg.Options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create collector %s: %w", entry.name, err)
+		}
+
+		interval := cfg.PollInterval
+		if interval <= 0 {
+			interval = pollInterval
+		}
+
+		collectors = append(collectors, Scheduled{
+			Collector:    c,
+			PollInterval: interval,
+		})
+	}
+
+	return collectors, nil
+}
+
+func (r *Registry) has(name string) bool {
+	for _, entry := range r.entries {
+		if entry.name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,71 @@
+package collector_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+)
+
+type fakeCollector struct {
+	name    string
+	options string
+}
+
+func (c fakeCollector) Name() string {
+	return c.name
+}
+
+func (c fakeCollector) Collect() ([]*collector.Metric, error) {
+	return []*collector.Metric{collector.NewGaugeMetric(c.name, 1)}, nil
+}
+
+func fakeFactory(name string) collector.Factory {
+	return func(options json.RawMessage) (collector.Collector, error) {
+		return fakeCollector{name: name, options: string(options)}, nil
+	}
+}
+
+func TestRegistry_Build(t *testing.T) {
+	registry := collector.NewRegistry()
+	registry.Register("first", fakeFactory("first"))
+	registry.Register("second", fakeFactory("second"), collector.Disabled())
+	registry.Register("third", fakeFactory("third"))
+
+	var configs map[string]collector.Config
+	err := json.Unmarshal([]byte(`{
+		"second": {"enabled": true, "poll_interval": "5s", "options": {"path": "/tmp"}},
+		"third": {"enabled": false}
+	}`), &configs)
+	require.NoError(t, err)
+
+	collectors, err := registry.Build(configs, time.Second)
+	require.NoError(t, err)
+	require.Len(t, collectors, 2)
+
+	assert.Equal(t, "first", collectors[0].Collector.Name())
+	assert.Equal(t, time.Second, collectors[0].PollInterval)
+
+	assert.Equal(t, "second", collectors[1].Collector.Name())
+	assert.Equal(t, 5*time.Second, collectors[1].PollInterval)
+	assert.JSONEq(t, `{"path": "/tmp"}`, collectors[1].Collector.(fakeCollector).options)
+}
+
+func TestRegistry_Build_Errors(t *testing.T) {
+	registry := collector.NewRegistry()
+	registry.Register("broken", func(_ json.RawMessage) (collector.Collector, error) {
+		return nil, errors.New("invalid options")
+	}, collector.Disabled())
+
+	_, err := registry.Build(map[string]collector.Config{"unknown": {}}, time.Second)
+	assert.Error(t, err)
+
+	enabled := true
+	_, err = registry.Build(map[string]collector.Config{"broken": {Enabled: &enabled}}, time.Second)
+	assert.Error(t, err)
+}
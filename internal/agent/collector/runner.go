@@ -0,0 +1,87 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Runner опрашивает сборщики, каждый со своим интервалом, и хранит последние снятые ими значения.
+type Runner struct {
+	collectors []Scheduled
+	log        log
+
+	lock   sync.RWMutex
+	latest [][]*Metric
+}
+
+// NewRunner создает планировщик опроса сборщиков.
+func NewRunner(collectors []Scheduled, log log) *Runner {
+	return &Runner{
+		collectors: collectors,
+		log:        log,
+		latest:     make([][]*Metric, len(collectors)),
+	}
+}
+
+// Start запускает опрос сборщиков. Каждый сборщик опрашивается сразу и затем через свой интервал,
+// пока не будет отменен контекст. Метод блокируется до завершения опроса всех сборщиков.
+func (r *Runner) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for i, s := range r.collectors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.run(ctx, i, s)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// Snapshot возвращает копии последних снятых значений всех сборщиков в порядке их регистрации.
+func (r *Runner) Snapshot() []*Metric {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var metrics []*Metric
+	for _, batch := range r.latest {
+		for _, m := range batch {
+			clone := *m
+			metrics = append(metrics, &clone)
+		}
+	}
+
+	return metrics
+}
+
+func (r *Runner) run(ctx context.Context, i int, s Scheduled) {
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.poll(i, s.Collector)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Метод poll опрашивает сборщик. При ошибке сохраняются значения предыдущего опроса.
+func (r *Runner) poll(i int, c Collector) {
+	metrics, err := c.Collect()
+	if err != nil {
+		r.log.WithField("collector", c.Name()).
+			WithError(err).
+			Error("Failed to collect metrics")
+		return
+	}
+
+	r.lock.Lock()
+	r.latest[i] = metrics
+	r.lock.Unlock()
+}
@@ -0,0 +1,51 @@
+package collector_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+	"github.com/bjlag/go-metrics/internal/mock"
+)
+
+func TestRunner_Start(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runner := collector.NewRunner([]collector.Scheduled{
+		{Collector: fakeCollector{name: "first"}, PollInterval: time.Millisecond},
+		{Collector: fakeCollector{name: "second"}, PollInterval: time.Hour},
+	}, mock.NewMockLogger(gomock.NewController(t)))
+
+	done := make(chan struct{})
+	go func() {
+		runner.Start(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(runner.Snapshot()) == 2
+	}, time.Second, time.Millisecond)
+
+	select {
+	case <-done:
+		t.Fatal("Start returned before the context was canceled")
+	default:
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Start did not wait for the pollers to stop")
+	}
+
+	metrics := runner.Snapshot()
+	assert.Equal(t, "first", metrics[0].Name())
+	assert.Equal(t, "second", metrics[1].Name())
+}
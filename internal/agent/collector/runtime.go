@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"math/rand"
+	"runtime"
+)
+
+// RuntimeName название сборщика метрик рантайма Go.
+const RuntimeName = "runtime"
+
+// RuntimeCollector собирает метрики рантайма Go из [runtime.MemStats].
+type RuntimeCollector struct {
+	read func(rtm *runtime.MemStats)
+}
+
+// NewRuntimeCollector создает сборщик метрик рантайма. Функция read заполняет статистику,
+// если она не задана, используется [runtime.ReadMemStats].
+func NewRuntimeCollector(read func(rtm *runtime.MemStats)) *RuntimeCollector {
+	if read == nil {
+		read = runtime.ReadMemStats
+	}
+
+	return &RuntimeCollector{
+		read: read,
+	}
+}
+
+func (c *RuntimeCollector) Name() string {
+	return RuntimeName
+}
+
+func (c *RuntimeCollector) Collect() ([]*Metric, error) {
+	rtm := &runtime.MemStats{}
+	c.read(rtm)
+
+	return []*Metric{
+		NewGaugeMetric("Alloc", rtm.Alloc),
+		NewGaugeMetric("TotalAlloc", rtm.TotalAlloc),
+		NewGaugeMetric("BuckHashSys", rtm.BuckHashSys),
+		NewGaugeMetric("Frees", rtm.Frees),
+		NewGaugeMetric("GCCPUFraction", rtm.GCCPUFraction),
+		NewGaugeMetric("GCSys", rtm.GCSys),
+		NewGaugeMetric("HeapAlloc", rtm.HeapAlloc),
+		NewGaugeMetric("HeapIdle", rtm.HeapIdle),
+		NewGaugeMetric("HeapInuse", rtm.HeapInuse),
+		NewGaugeMetric("HeapObjects", rtm.HeapObjects),
+		NewGaugeMetric("HeapReleased", rtm.HeapReleased),
+		NewGaugeMetric("HeapSys", rtm.HeapSys),
+		NewGaugeMetric("LastGC", rtm.LastGC),
+		NewGaugeMetric("Lookups", rtm.Lookups),
+		NewGaugeMetric("MCacheInuse", rtm.MCacheInuse),
+		NewGaugeMetric("MCacheSys", rtm.MCacheSys),
+		NewGaugeMetric("MSpanInuse", rtm.MSpanInuse),
+		NewGaugeMetric("MSpanSys", rtm.MSpanSys),
+		NewGaugeMetric("Mallocs", rtm.Mallocs),
+		NewGaugeMetric("NextGC", rtm.NextGC),
+		NewGaugeMetric("NumForcedGC", rtm.NumForcedGC),
+		NewGaugeMetric("NumGC", rtm.NumGC),
+		NewGaugeMetric("OtherSys", rtm.OtherSys),
+		NewGaugeMetric("PauseTotalNs", rtm.PauseTotalNs),
+		NewGaugeMetric("StackInuse", rtm.StackInuse),
+		NewGaugeMetric("StackSys", rtm.StackSys),
+		NewGaugeMetric("Sys", rtm.Sys),
+		NewGaugeMetric("RandomValue", getRandomFloat(1, 100)),
+	}, nil
+}
+
+func getRandomFloat(min, max float64) float64 {
+	return min + rand.Float64()*(max-min)
+}
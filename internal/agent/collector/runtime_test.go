@@ -9,8 +9,8 @@ import (
 	"github.com/bjlag/go-metrics/internal/agent/collector"
 )
 
-func TestMetricCollector_Collect(t *testing.T) {
-	rtm := &runtime.MemStats{
+func TestRuntimeCollector_Collect(t *testing.T) {
+	rtm := runtime.MemStats{
 		Alloc:         1,
 		TotalAlloc:    2,
 		BuckHashSys:   3,
@@ -40,7 +40,9 @@ func TestMetricCollector_Collect(t *testing.T) {
 		Sys:           27,
 	}
 
-	c := collector.NewMetricCollector(rtm)
+	c := collector.NewRuntimeCollector(func(stats *runtime.MemStats) {
+		*stats = rtm
+	})
 	metrics, err := c.Collect()
 	assert.NoError(t, err)
 
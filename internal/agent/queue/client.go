@@ -0,0 +1,109 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bjlag/go-metrics/internal/agent/client"
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+)
+
+// Client декоратор клиента, который сохраняет в очередь батчи, не отправленные из-за недоступности сервера.
+//
+// Перед отправкой нового батча клиент отправляет накопленные в очереди батчи в порядке их поступления.
+// Пока очередь не опустеет, новые батчи тоже ставятся в очередь, чтобы не нарушить порядок.
+// Батчи, отклоненные сервером (см. [client.ErrRejected]), в очередь не попадают и из нее удаляются.
+//
+// Отправленный батч удаляется из очереди, даже если не удалось удалить его файл (см. [Queue.Pop]),
+// поэтому до перезапуска агента батч повторно не отправляется. После перезапуска такой батч будет отправлен еще раз,
+// и значения счетчиков из него будут учтены дважды.
+type Client struct {
+	next  client.Client
+	queue *Queue
+	log   log
+	lock  sync.Mutex
+}
+
+// NewClient создает декоратор клиента next.
+func NewClient(next client.Client, queue *Queue, log log) *Client {
+	return &Client{
+		next:  next,
+		queue: queue,
+		log:   log,
+	}
+}
+
+// Send отправляет батч. Если сервер недоступен, батч сохраняется в очередь, и ошибка не возвращается.
+func (c *Client) Send(metrics []*collector.Metric) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	err := c.replay()
+	if err != nil {
+		return c.spool(metrics, err)
+	}
+
+	err = c.next.Send(metrics)
+	if err == nil || errors.Is(err, client.ErrRejected) {
+		return err
+	}
+
+	return c.spool(metrics, err)
+}
+
+// Метод replay отправляет батчи из очереди, пока она не опустеет или не случится ошибка отправки.
+func (c *Client) replay() error {
+	replayed := 0
+
+	for {
+		metrics, ok, err := c.queue.Peek()
+		if !ok {
+			break
+		}
+
+		if err == nil {
+			err = c.next.Send(metrics)
+			if err != nil && !errors.Is(err, client.ErrRejected) {
+				return err
+			}
+		}
+
+		if err != nil {
+			c.log.WithError(err).Error("Dropped batch from queue")
+		} else {
+			replayed++
+		}
+
+		err = c.queue.Pop()
+		if err != nil {
+			c.log.WithError(err).Error("Failed to remove batch from queue")
+		}
+	}
+
+	if replayed > 0 {
+		c.log.WithField("replayed", replayed).
+			WithField("depth", c.queue.Len()).
+			Info("Queue replayed")
+	}
+
+	return nil
+}
+
+// Метод spool сохраняет батч в очередь. Причина, по которой батч не отправлен, попадает в лог.
+func (c *Client) spool(metrics []*collector.Metric, cause error) error {
+	dropped, err := c.queue.Push(metrics)
+	if err != nil {
+		return fmt.Errorf("failed to queue batch: %w, send error: %w", err, cause)
+	}
+
+	if dropped > 0 {
+		c.log.WithField("dropped", dropped).Error("Queue is full, oldest batches dropped")
+	}
+
+	c.log.WithError(cause).
+		WithField("depth", c.queue.Len()).
+		Info("Server unavailable, batch queued")
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package queue_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/agent/client"
+	httpClient "github.com/bjlag/go-metrics/internal/agent/client/http"
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+	"github.com/bjlag/go-metrics/internal/agent/limiter"
+	"github.com/bjlag/go-metrics/internal/agent/queue"
+	"github.com/bjlag/go-metrics/internal/http/middleware"
+	mockLogger "github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/securety/crypt"
+	"github.com/bjlag/go-metrics/internal/securety/signature"
+)
+
+type fakeClient struct {
+	err    error
+	sent   []int64
+	onSend func()
+}
+
+func (c *fakeClient) Send(metrics []*collector.Metric) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	delta, _ := metrics[0].CounterValue()
+	c.sent = append(c.sent, delta)
+
+	if c.onSend != nil {
+		c.onSend()
+	}
+
+	return nil
+}
+
+func TestClient_Send(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	log := mockLogger.NewMockLogger(ctrl)
+	log.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(log).AnyTimes()
+	log.EXPECT().WithError(gomock.Any()).Return(log).AnyTimes()
+	log.EXPECT().Info(gomock.Any()).AnyTimes()
+	log.EXPECT().Error(gomock.Any()).AnyTimes()
+
+	q, err := queue.New(t.TempDir(), 10)
+	require.NoError(t, err)
+
+	next := &fakeClient{err: errors.New("connection refused")}
+	c := queue.NewClient(next, q, log)
+
+	batch := func(delta int64) []*collector.Metric {
+		return []*collector.Metric{collector.NewCounterMetric("PollCount", delta)}
+	}
+
+	require.NoError(t, c.Send(batch(1)))
+	require.NoError(t, c.Send(batch(2)))
+	assert.Equal(t, 2, q.Len())
+
+	next.err = nil
+	require.NoError(t, c.Send(batch(3)))
+
+	assert.Equal(t, []int64{1, 2, 3}, next.sent)
+	assert.Equal(t, 0, q.Len())
+
+	next.err = fmt.Errorf("%w: status 400", client.ErrRejected)
+	err = c.Send(batch(4))
+	assert.ErrorIs(t, err, client.ErrRejected)
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestClient_Send_PopFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	log := mockLogger.NewMockLogger(ctrl)
+	log.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(log).AnyTimes()
+	log.EXPECT().WithError(gomock.Any()).Return(log).AnyTimes()
+	log.EXPECT().Info(gomock.Any()).AnyTimes()
+	log.EXPECT().Error(gomock.Any()).AnyTimes()
+
+	dir := t.TempDir()
+	q, err := queue.New(dir, 10)
+	require.NoError(t, err)
+
+	next := &fakeClient{err: errors.New("connection refused")}
+	c := queue.NewClient(next, q, log)
+
+	batch := func(delta int64) []*collector.Metric {
+		return []*collector.Metric{collector.NewCounterMetric("PollCount", delta)}
+	}
+
+	require.NoError(t, c.Send(batch(1)))
+	require.NoError(t, c.Send(batch(2)))
+
+	// после отправки первого батча его файл нельзя удалить: на его месте непустая директория
+	next.err = nil
+	next.onSend = func() {
+		next.onSend = nil
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+
+		path := filepath.Join(dir, entries[0].Name())
+		require.NoError(t, os.Remove(path))
+		require.NoError(t, os.MkdirAll(filepath.Join(path, "busy"), 0o700))
+	}
+
+	require.NoError(t, c.Send(batch(3)))
+	require.NoError(t, c.Send(batch(4)))
+
+	assert.Equal(t, []int64{1, 2, 3, 4}, next.sent)
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestClient_Send_SignatureFailureQueued(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	log := mockLogger.NewMockLogger(ctrl)
+	log.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(log).AnyTimes()
+	log.EXPECT().WithError(gomock.Any()).Return(log).AnyTimes()
+	log.EXPECT().Info(gomock.Any()).AnyTimes()
+	log.EXPECT().Error(gomock.Any()).AnyTimes()
+
+	// сервер проверяет подпись другим ключом
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(middleware.SignatureMiddleware(signature.NewSignManager("server key"), log)(ok))
+	defer server.Close()
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	encryptManager, err := crypt.NewEncryptManager("")
+	require.NoError(t, err)
+
+	sender, err := httpClient.NewSender(
+		host,
+		portNumber,
+		signature.NewSignManager("agent key"),
+		encryptManager,
+		limiter.NewRateLimiter(1),
+		log,
+	)
+	require.NoError(t, err)
+
+	q, err := queue.New(t.TempDir(), 10)
+	require.NoError(t, err)
+
+	c := queue.NewClient(sender, q, log)
+
+	require.NoError(t, c.Send([]*collector.Metric{collector.NewCounterMetric("PollCount", int64(1))}))
+	assert.Equal(t, 1, q.Len())
+}
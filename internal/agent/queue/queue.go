@@ -0,0 +1,252 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+	"github.com/bjlag/go-metrics/internal/model"
+)
+
+// DefaultMaxSize максимальное количество батчей в очереди, если размер не задан.
+const DefaultMaxSize = 1000
+
+const fileExt = ".json"
+
+// Queue ограниченная очередь батчей метрик на диске.
+//
+// Каждый батч хранится в отдельном файле, в имени которого порядковый номер батча,
+// поэтому порядок батчей сохраняется после перезапуска агента.
+// При переполнении очереди удаляются самые старые батчи.
+type Queue struct {
+	dir     string
+	maxSize int
+
+	lock sync.Mutex
+	seqs []uint64
+	next uint64
+}
+
+// New открывает очередь в директории dir, создавая директорию при необходимости.
+// Батчи, оставшиеся в директории с прошлого запуска, остаются в очереди.
+func New(dir string, maxSize int) (*Queue, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create queue dir: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue dir: %w", err)
+	}
+
+	q := &Queue{
+		dir:     dir,
+		maxSize: maxSize,
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, fileExt) {
+			continue
+		}
+
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, fileExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		q.seqs = append(q.seqs, seq)
+	}
+
+	sort.Slice(q.seqs, func(i, j int) bool {
+		return q.seqs[i] < q.seqs[j]
+	})
+
+	if len(q.seqs) > 0 {
+		q.next = q.seqs[len(q.seqs)-1] + 1
+	}
+
+	return q, nil
+}
+
+// Len возвращает количество батчей в очереди.
+func (q *Queue) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return len(q.seqs)
+}
+
+// Push добавляет батч в конец очереди. Возвращает количество старых батчей, удаленных из-за переполнения.
+func (q *Queue) Push(metrics []*collector.Metric) (int, error) {
+	records := make([]record, 0, len(metrics))
+	for _, m := range metrics {
+		if m == nil {
+			continue
+		}
+
+		r, err := newRecord(m)
+		if err != nil {
+			return 0, err
+		}
+
+		records = append(records, r)
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	seq := q.next
+
+	// Батч сначала пишется во временный файл, чтобы при сбое в очереди не оказался частично записанный файл.
+	tmp := filepath.Join(q.dir, strconv.FormatUint(seq, 10)+".tmp")
+	err = os.WriteFile(tmp, data, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write batch: %w", err)
+	}
+
+	err = os.Rename(tmp, q.path(seq))
+	if err != nil {
+		_ = os.Remove(tmp)
+		return 0, fmt.Errorf("failed to write batch: %w", err)
+	}
+
+	q.next++
+	q.seqs = append(q.seqs, seq)
+
+	dropped := 0
+	for len(q.seqs) > q.maxSize {
+		_ = os.Remove(q.path(q.seqs[0]))
+		q.seqs = q.seqs[1:]
+		dropped++
+	}
+
+	return dropped, nil
+}
+
+// Peek возвращает первый батч очереди, не удаляя его. Если очередь пуста, возвращает false.
+func (q *Queue) Peek() ([]*collector.Metric, bool, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.seqs) == 0 {
+		return nil, false, nil
+	}
+
+	data, err := os.ReadFile(q.path(q.seqs[0]))
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to read batch: %w", err)
+	}
+
+	var records []record
+	err = json.Unmarshal(data, &records)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to unmarshal batch: %w", err)
+	}
+
+	metrics := make([]*collector.Metric, 0, len(records))
+	for _, r := range records {
+		metrics = append(metrics, r.metric())
+	}
+
+	return metrics, true, nil
+}
+
+// Pop удаляет первый батч очереди.
+//
+// Батч удаляется из очереди, даже если не удалось удалить его файл, чтобы уже отправленный батч не был отправлен
+// повторно. В этом случае возвращается ошибка, а файл будет снова прочитан в очередь только после перезапуска агента.
+func (q *Queue) Pop() error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.seqs) == 0 {
+		return nil
+	}
+
+	seq := q.seqs[0]
+	q.seqs = q.seqs[1:]
+
+	err := os.Remove(q.path(seq))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove batch: %w", err)
+	}
+
+	return nil
+}
+
+func (q *Queue) path(seq uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", seq, fileExt))
+}
+
+// record метрика в том виде, в котором она хранится в файле очереди.
+type record struct {
+	Kind      string            `json:"type"`
+	Name      string            `json:"id"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Gauge     *float64          `json:"value,omitempty"`
+	Counter   *int64            `json:"delta,omitempty"`
+	Histogram *model.Histogram  `json:"histogram,omitempty"`
+}
+
+func newRecord(m *collector.Metric) (record, error) {
+	r := record{
+		Kind:   m.Kind(),
+		Name:   m.Name(),
+		Labels: m.Labels(),
+	}
+
+	switch m.Kind() {
+	case collector.Gauge:
+		value, err := m.GaugeValue()
+		if err != nil {
+			return record{}, err
+		}
+		r.Gauge = &value
+	case collector.Counter:
+		value, err := m.CounterValue()
+		if err != nil {
+			return record{}, err
+		}
+		r.Counter = &value
+	case collector.Histogram:
+		value, err := m.HistogramValue()
+		if err != nil {
+			return record{}, err
+		}
+		r.Histogram = &value
+	}
+
+	return r, nil
+}
+
+func (r record) metric() *collector.Metric {
+	var value interface{}
+
+	switch {
+	case r.Gauge != nil:
+		value = *r.Gauge
+	case r.Counter != nil:
+		value = *r.Counter
+	case r.Histogram != nil:
+		value = *r.Histogram
+	}
+
+	return collector.NewMetric(r.Kind, r.Name, value).WithLabels(r.Labels)
+}
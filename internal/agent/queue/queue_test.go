@@ -0,0 +1,86 @@
+package queue_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+	"github.com/bjlag/go-metrics/internal/agent/queue"
+	"github.com/bjlag/go-metrics/internal/model"
+)
+
+func TestQueue_Persistence(t *testing.T) {
+	dir := t.TempDir()
+
+	q, err := queue.New(dir, 10)
+	require.NoError(t, err)
+
+	_, err = q.Push([]*collector.Metric{
+		collector.NewCounterMetric("PollCount", 1).WithLabels(map[string]string{"host": "a"}),
+		collector.NewGaugeMetric("Alloc", 1.5),
+	})
+	require.NoError(t, err)
+	_, err = q.Push([]*collector.Metric{
+		collector.NewHistogramMetric("Latency", model.Histogram{Bounds: []float64{1}, Counts: []uint64{1, 0}, Sum: 0.5, Count: 1}),
+	})
+	require.NoError(t, err)
+
+	reopened, err := queue.New(dir, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, reopened.Len())
+
+	batch, ok, err := reopened.Peek()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, batch, 2)
+
+	delta, err := batch[0].CounterValue()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), delta)
+	assert.Equal(t, map[string]string{"host": "a"}, batch[0].Labels())
+
+	value, err := batch[1].GaugeValue()
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, value)
+
+	require.NoError(t, reopened.Pop())
+
+	batch, ok, err = reopened.Peek()
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	histogram, err := batch[0].HistogramValue()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), histogram.Count)
+
+	require.NoError(t, reopened.Pop())
+
+	_, ok, err = reopened.Peek()
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestQueue_DropsOldest(t *testing.T) {
+	q, err := queue.New(t.TempDir(), 2)
+	require.NoError(t, err)
+
+	for i := 1; i <= 3; i++ {
+		dropped, err := q.Push([]*collector.Metric{collector.NewCounterMetric("PollCount", i)})
+		require.NoError(t, err)
+
+		if i == 3 {
+			assert.Equal(t, 1, dropped)
+		}
+	}
+
+	assert.Equal(t, 2, q.Len())
+
+	batch, _, err := q.Peek()
+	require.NoError(t, err)
+
+	delta, err := batch[0].CounterValue()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), delta)
+}
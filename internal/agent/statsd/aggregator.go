@@ -0,0 +1,106 @@
+package statsd
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+	"github.com/bjlag/go-metrics/internal/model"
+)
+
+// Aggregator накапливает значения StatsD между отправками отчетов.
+//
+// Счетчики суммируются, при каждом [Aggregator.Flush] отправляется целая часть суммы, а дробный остаток
+// переносится на следующую отправку. Gauge хранит последнее значение, но отправляется, только если менялся с прошлой отправки.
+type Aggregator struct {
+	lock     sync.Mutex
+	counters map[string]*entry
+	gauges   map[string]*entry
+}
+
+type entry struct {
+	name    string
+	labels  map[string]string
+	value   float64
+	updated bool
+}
+
+// NewAggregator создает агрегатор.
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		counters: make(map[string]*entry),
+		gauges:   make(map[string]*entry),
+	}
+}
+
+// Add учитывает значение.
+func (a *Aggregator) Add(s Sample) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	values := a.gauges
+	if s.Type == TypeCounter {
+		values = a.counters
+	}
+
+	key := model.SeriesKey(s.Name, s.Labels)
+
+	e, ok := values[key]
+	if !ok {
+		e = &entry{name: s.Name, labels: s.Labels}
+		values[key] = e
+	}
+
+	if s.Type == TypeCounter || s.Relative {
+		e.value += s.Value
+	} else {
+		e.value = s.Value
+	}
+	e.updated = true
+}
+
+// Flush возвращает накопленные с прошлого вызова значения в виде метрик агента, упорядоченных по ключу серии.
+// Счетчик, накопивший меньше единицы, не отправляется, его значение остается до следующего вызова.
+func (a *Aggregator) Flush() []*collector.Metric {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	var metrics []*collector.Metric
+
+	for _, key := range sortedKeys(a.counters) {
+		e := a.counters[key]
+
+		whole := math.Trunc(e.value)
+		e.value -= whole
+		if e.value == 0 {
+			delete(a.counters, key)
+		}
+
+		if whole != 0 {
+			metrics = append(metrics, collector.NewCounterMetric(e.name, int64(whole)).WithLabels(e.labels))
+		}
+	}
+
+	for _, key := range sortedKeys(a.gauges) {
+		e := a.gauges[key]
+		if !e.updated {
+			continue
+		}
+
+		metrics = append(metrics, collector.NewGaugeMetric(e.name, e.value).WithLabels(e.labels))
+		e.updated = false
+	}
+
+	return metrics
+}
+
+func sortedKeys(values map[string]*entry) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,52 @@
+package statsd_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+	"github.com/bjlag/go-metrics/internal/agent/statsd"
+)
+
+func TestAggregator_Flush(t *testing.T) {
+	a := statsd.NewAggregator()
+
+	a.Add(statsd.Sample{Name: "requests", Type: statsd.TypeCounter, Value: 2})
+	a.Add(statsd.Sample{Name: "requests", Type: statsd.TypeCounter, Value: 3.4})
+	a.Add(statsd.Sample{Name: "requests", Type: statsd.TypeCounter, Value: 1, Labels: map[string]string{"code": "500"}})
+	a.Add(statsd.Sample{Name: "queue_size", Type: statsd.TypeGauge, Value: 10})
+	a.Add(statsd.Sample{Name: "queue_size", Type: statsd.TypeGauge, Value: -3, Relative: true})
+
+	assert.Equal(t, []*collector.Metric{
+		collector.NewCounterMetric("requests", int64(5)),
+		collector.NewCounterMetric("requests", int64(1)).WithLabels(map[string]string{"code": "500"}),
+		collector.NewGaugeMetric("queue_size", float64(7)),
+	}, a.Flush())
+
+	assert.Empty(t, a.Flush(), "counters are reset and unchanged gauges are not sent")
+
+	a.Add(statsd.Sample{Name: "queue_size", Type: statsd.TypeGauge, Value: 1, Relative: true})
+
+	assert.Equal(t, []*collector.Metric{
+		collector.NewGaugeMetric("queue_size", float64(8)),
+	}, a.Flush())
+}
+
+func TestAggregator_Flush_CounterRemainder(t *testing.T) {
+	a := statsd.NewAggregator()
+
+	// При частоте выборки 0.4 каждое событие считается за 2.5.
+	a.Add(statsd.Sample{Name: "requests", Type: statsd.TypeCounter, Value: 2.5})
+	assert.Equal(t, []*collector.Metric{collector.NewCounterMetric("requests", int64(2))}, a.Flush())
+
+	a.Add(statsd.Sample{Name: "requests", Type: statsd.TypeCounter, Value: 2.5})
+	assert.Equal(t, []*collector.Metric{collector.NewCounterMetric("requests", int64(3))}, a.Flush())
+
+	a.Add(statsd.Sample{Name: "requests", Type: statsd.TypeCounter, Value: 0.25})
+	assert.Empty(t, a.Flush(), "counter below one is kept")
+
+	a.Add(statsd.Sample{Name: "requests", Type: statsd.TypeCounter, Value: 0.75})
+	assert.Equal(t, []*collector.Metric{collector.NewCounterMetric("requests", int64(1))}, a.Flush())
+	assert.Empty(t, a.Flush())
+}
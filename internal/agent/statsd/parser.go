@@ -0,0 +1,112 @@
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bjlag/go-metrics/internal/model"
+)
+
+const (
+	// TypeCounter тип строки StatsD для счетчика.
+	TypeCounter = "c"
+	// TypeGauge тип строки StatsD для gauge.
+	TypeGauge = "g"
+)
+
+var ErrInvalidLine = errors.New("invalid statsd line")
+
+// Sample значение метрики из одной строки StatsD.
+type Sample struct {
+	// Name название метрики.
+	Name string
+	// Type тип метрики: [TypeCounter] или [TypeGauge].
+	Type string
+	// Value значение. Для счетчика уже поделено на частоту выборки.
+	Value float64
+	// Relative для gauge со знаком + или - значение прибавляется к текущему, а не заменяет его.
+	Relative bool
+	// Labels метки метрики, передаются тегами в формате DogStatsD.
+	Labels map[string]string
+}
+
+// Parse разбирает строку StatsD формата `name:value|type[|@rate][|#tag:value,...]`.
+// Поддерживаются типы c (counter) и g (gauge). Частота выборки @rate применяется только к счетчикам.
+func Parse(line string) (Sample, error) {
+	name, rest, ok := strings.Cut(line, ":")
+	if !ok {
+		return Sample{}, fmt.Errorf("%w: %q", ErrInvalidLine, line)
+	}
+
+	if !model.IsValidID(name) {
+		return Sample{}, fmt.Errorf("%w: invalid name %q", ErrInvalidLine, name)
+	}
+
+	parts := strings.Split(rest, "|")
+	if len(parts) < 2 {
+		return Sample{}, fmt.Errorf("%w: %q", ErrInvalidLine, line)
+	}
+
+	s := Sample{
+		Name: name,
+		Type: parts[1],
+	}
+
+	value, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return Sample{}, fmt.Errorf("%w: invalid value %q", ErrInvalidLine, parts[0])
+	}
+	s.Value = value
+
+	switch s.Type {
+	case TypeCounter:
+	case TypeGauge:
+		s.Relative = strings.HasPrefix(parts[0], "+") || strings.HasPrefix(parts[0], "-")
+	default:
+		return Sample{}, fmt.Errorf("%w: unsupported type %q", ErrInvalidLine, s.Type)
+	}
+
+	for _, part := range parts[2:] {
+		switch {
+		case strings.HasPrefix(part, "@"):
+			rate, err := strconv.ParseFloat(part[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return Sample{}, fmt.Errorf("%w: invalid sample rate %q", ErrInvalidLine, part)
+			}
+
+			if s.Type == TypeCounter {
+				s.Value /= rate
+			}
+		case strings.HasPrefix(part, "#"):
+			s.Labels, err = parseTags(part[1:])
+			if err != nil {
+				return Sample{}, err
+			}
+		default:
+			return Sample{}, fmt.Errorf("%w: unknown field %q", ErrInvalidLine, part)
+		}
+	}
+
+	return s, nil
+}
+
+func parseTags(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+
+	for _, tag := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(tag, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid tag %q", ErrInvalidLine, tag)
+		}
+
+		labels[name] = value
+	}
+
+	if err := model.Labels(labels).Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLine, err)
+	}
+
+	return labels, nil
+}
@@ -0,0 +1,93 @@
+package statsd_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/agent/statsd"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    statsd.Sample
+		wantErr bool
+	}{
+		{
+			name: "counter",
+			line: "requests:3|c",
+			want: statsd.Sample{Name: "requests", Type: statsd.TypeCounter, Value: 3},
+		},
+		{
+			name: "counter with sample rate",
+			line: "requests:1|c|@0.1",
+			want: statsd.Sample{Name: "requests", Type: statsd.TypeCounter, Value: 10},
+		},
+		{
+			name: "gauge",
+			line: "queue_size:42.5|g",
+			want: statsd.Sample{Name: "queue_size", Type: statsd.TypeGauge, Value: 42.5},
+		},
+		{
+			name: "relative gauge",
+			line: "queue_size:-2|g",
+			want: statsd.Sample{Name: "queue_size", Type: statsd.TypeGauge, Value: -2, Relative: true},
+		},
+		{
+			name: "tags",
+			line: "requests:1|c|#route:api,code:200",
+			want: statsd.Sample{Name: "requests", Type: statsd.TypeCounter, Value: 1, Labels: map[string]string{"route": "api", "code": "200"}},
+		},
+		{
+			name:    "without type",
+			line:    "requests:1",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported type",
+			line:    "latency:12|ms",
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			line:    "requests:abc|c",
+			wantErr: true,
+		},
+		{
+			name:    "empty name",
+			line:    ":1|c",
+			wantErr: true,
+		},
+		{
+			name:    "invalid name",
+			line:    "requests{host}:1|c",
+			wantErr: true,
+		},
+		{
+			name:    "invalid sample rate",
+			line:    "requests:1|c|@2",
+			wantErr: true,
+		},
+		{
+			name:    "invalid tag name",
+			line:    "requests:1|c|#1route:api",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := statsd.Parse(tt.line)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, statsd.ErrInvalidLine)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
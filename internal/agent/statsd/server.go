@@ -0,0 +1,76 @@
+package statsd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+)
+
+// maxPacketSize максимальный размер UDP пакета.
+const maxPacketSize = 65535
+
+// Server принимает метрики по протоколу StatsD через UDP и передает их в [Aggregator].
+// В одном пакете может быть несколько строк, разделенных переводом строки. Некорректные строки пропускаются.
+type Server struct {
+	addr       string
+	aggregator *Aggregator
+	log        log
+}
+
+// NewServer создает сервер StatsD.
+func NewServer(addr string, aggregator *Aggregator, log log) *Server {
+	return &Server{
+		addr:       addr,
+		aggregator: aggregator,
+		log:        log,
+	}
+}
+
+// Start слушает UDP адрес, пока не будет отменен контекст.
+func (s *Server) Start(ctx context.Context) error {
+	conn, err := net.ListenPacket("udp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("Starting StatsD server")
+
+	go func() {
+		<-ctx.Done()
+
+		s.log.Info("Shutting down StatsD server")
+		_ = conn.Close()
+	}()
+
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return err
+		}
+
+		s.handle(string(buf[:n]))
+	}
+}
+
+func (s *Server) handle(packet string) {
+	for _, line := range strings.Split(packet, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		sample, err := Parse(line)
+		if err != nil {
+			s.log.WithError(err).Info("Skipped StatsD line")
+			continue
+		}
+
+		s.aggregator.Add(sample)
+	}
+}
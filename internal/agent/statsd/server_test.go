@@ -0,0 +1,81 @@
+package statsd_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+	"github.com/bjlag/go-metrics/internal/agent/statsd"
+	"github.com/bjlag/go-metrics/internal/mock"
+)
+
+func TestServer_Start(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	log := mock.NewMockLogger(ctrl)
+	log.EXPECT().WithError(gomock.Any()).Return(log).AnyTimes()
+	log.EXPECT().Info(gomock.Any()).AnyTimes()
+
+	// Свободный порт, который затем займет сервер.
+	probe, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := probe.LocalAddr().String()
+	require.NoError(t, probe.Close())
+
+	aggregator := statsd.NewAggregator()
+	server := statsd.NewServer(addr, aggregator, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Start(ctx)
+	}()
+
+	conn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// Пакеты, отправленные до запуска сервера, теряются, поэтому отправляем, пока сервер не ответит значением.
+	require.Eventually(t, func() bool {
+		_, err := conn.Write([]byte("ready:1|g"))
+		require.NoError(t, err)
+
+		return len(aggregator.Flush()) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = conn.Write([]byte("requests:2|c|#code:200\nbroken line\nqueue_size:7|g\n"))
+	require.NoError(t, err)
+
+	// Последние пакеты ready могут дойти уже после первой отправки, их пропускаем.
+	var metrics []*collector.Metric
+	require.Eventually(t, func() bool {
+		for _, m := range aggregator.Flush() {
+			if m.Name() != "ready" {
+				metrics = append(metrics, m)
+			}
+		}
+		return len(metrics) >= 2
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, []*collector.Metric{
+		collector.NewCounterMetric("requests", int64(2)).WithLabels(map[string]string{"code": "200"}),
+		collector.NewGaugeMetric("queue_size", float64(7)),
+	}, metrics)
+
+	cancel()
+
+	select {
+	case err := <-errCh:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop")
+	}
+}
@@ -0,0 +1,18 @@
+package alert
+
+import (
+	"context"
+
+	"github.com/bjlag/go-metrics/internal/logger"
+)
+
+type repo interface {
+	GetGauge(ctx context.Context, id string) (float64, error)
+	GetCounter(ctx context.Context, id string) (int64, error)
+}
+
+type log interface {
+	WithField(key string, value interface{}) logger.Logger
+	WithError(err error) logger.Logger
+	Info(msg string)
+}
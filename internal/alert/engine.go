@@ -0,0 +1,224 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+// Engine по расписанию проверяет правила алертинга на данных из хранилища и хранит состояние алертов.
+type Engine struct {
+	repo     repo
+	rules    []*Rule
+	interval time.Duration
+	log      log
+
+	lock   sync.RWMutex
+	alerts map[string]*Alert
+
+	// evalLock не дает проверкам идти параллельно и защищает counters,
+	// при этом чтение состояния алертов не ждет обращений к хранилищу.
+	evalLock sync.Mutex
+	counters map[string]counterSample
+}
+
+// counterSample последнее прочитанное значение метрики типа counter, нужно для вычисления rate.
+type counterSample struct {
+	value int64
+	at    time.Time
+}
+
+// NewEngine создает движок алертинга.
+// Параметр interval регулирует, с какой периодичностью проверяются правила.
+func NewEngine(repo repo, rules []*Rule, interval time.Duration, log log) *Engine {
+	alerts := make(map[string]*Alert, len(rules))
+	for _, r := range rules {
+		alerts[r.Name()] = &Alert{
+			Name:  r.Name(),
+			Expr:  r.Expr(),
+			State: StateInactive,
+		}
+	}
+
+	return &Engine{
+		repo:     repo,
+		rules:    rules,
+		interval: interval,
+		log:      log,
+		alerts:   alerts,
+		counters: make(map[string]counterSample),
+	}
+}
+
+// Start запускает воркер, который в фоновом режиме проверяет правила.
+func (e *Engine) Start(ctx context.Context) {
+	if len(e.rules) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(e.interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				e.Evaluate(ctx, now)
+			}
+		}
+	}()
+
+	e.log.Info("Alert engine started")
+}
+
+// Evaluate проверяет все правила на момент времени now и обновляет состояние алертов.
+// Значения правил вычисляются без блокировки состояния алертов, блокировка берется только для их обновления.
+func (e *Engine) Evaluate(ctx context.Context, now time.Time) {
+	e.evalLock.Lock()
+	defer e.evalLock.Unlock()
+
+	e.lock.RLock()
+	rules := make([]*Rule, len(e.rules))
+	copy(rules, e.rules)
+	e.lock.RUnlock()
+
+	rates := make(map[string]*float64)
+	values := make([]*float64, len(rules))
+
+	for i, r := range rules {
+		value, err := e.value(ctx, r.parsed, now, rates)
+		if err != nil {
+			var notFoundErr *storage.NotFoundError
+			if !errors.As(err, &notFoundErr) {
+				e.log.WithField("rule", r.Name()).WithError(err).Error("Failed to evaluate alert rule")
+			}
+		}
+
+		values[i] = value
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for i, r := range rules {
+		e.transit(r, e.alerts[r.Name()], values[i], now)
+	}
+}
+
+// Alerts возвращает состояние всех алертов, отсортированное по названию правила.
+func (e *Engine) Alerts() []Alert {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	alerts := make([]Alert, 0, len(e.alerts))
+	for _, a := range e.alerts {
+		alerts = append(alerts, *a)
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Name < alerts[j].Name
+	})
+
+	return alerts
+}
+
+// Метод transit переводит алерт в следующее состояние в зависимости от значения выражения.
+func (e *Engine) transit(r *Rule, a *Alert, value *float64, now time.Time) {
+	a.Value = value
+	a.EvalAt = &now
+
+	matched := value != nil && r.parsed.match(*value)
+
+	switch {
+	case matched:
+		if a.State != StatePending && a.State != StateFiring {
+			a.State = StatePending
+			a.ActiveAt = &now
+			a.FiredAt = nil
+			a.ResolvedAt = nil
+		}
+
+		if a.State == StatePending && now.Sub(*a.ActiveAt) >= r.For() {
+			a.State = StateFiring
+			a.FiredAt = &now
+
+			e.log.WithField("rule", r.Name()).WithField("value", *value).Info("Alert is firing")
+		}
+	case a.State == StateFiring:
+		a.State = StateResolved
+		a.ResolvedAt = &now
+
+		e.log.WithField("rule", r.Name()).Info("Alert is resolved")
+	case a.State == StatePending:
+		a.State = StateInactive
+		a.ActiveAt = nil
+	}
+}
+
+// Метод value вычисляет значение левой части выражения.
+// Возвращает nil, если данных для вычисления пока недостаточно.
+func (e *Engine) value(ctx context.Context, expr *expression, now time.Time, rates map[string]*float64) (*float64, error) {
+	if expr.rate {
+		return e.rate(ctx, expr.metric, now, rates)
+	}
+
+	gauge, err := e.repo.GetGauge(ctx, expr.metric)
+	if err == nil {
+		return &gauge, nil
+	}
+
+	var notFoundErr *storage.NotFoundError
+	if !errors.As(err, &notFoundErr) {
+		return nil, err
+	}
+
+	counter, err := e.repo.GetCounter(ctx, expr.metric)
+	if err != nil {
+		return nil, err
+	}
+
+	value := float64(counter)
+
+	return &value, nil
+}
+
+// Метод rate вычисляет скорость роста метрики типа counter в секунду с момента предыдущей проверки.
+// В рамках одной проверки значение вычисляется один раз, даже если метрика используется в нескольких правилах.
+func (e *Engine) rate(ctx context.Context, id string, now time.Time, rates map[string]*float64) (*float64, error) {
+	if value, ok := rates[id]; ok {
+		return value, nil
+	}
+
+	current, err := e.repo.GetCounter(ctx, id)
+	if err != nil {
+		rates[id] = nil
+		return nil, err
+	}
+
+	prev, ok := e.counters[id]
+	e.counters[id] = counterSample{value: current, at: now}
+
+	elapsed := now.Sub(prev.at).Seconds()
+	if !ok || elapsed <= 0 {
+		rates[id] = nil
+		return nil, nil
+	}
+
+	delta := current - prev.value
+	if delta < 0 {
+		// Счетчик был сброшен, считаем, что он рос с нуля.
+		delta = current
+	}
+
+	value := float64(delta) / elapsed
+	rates[id] = &value
+
+	return &value, nil
+}
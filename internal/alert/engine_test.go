@@ -0,0 +1,130 @@
+package alert_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/alert"
+	"github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/storage/memory"
+)
+
+func TestEngine_Evaluate(t *testing.T) {
+	newLogger := func(ctrl *gomock.Controller) *mock.MockLogger {
+		mockLogger := mock.NewMockLogger(ctrl)
+		mockLogger.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(mockLogger).AnyTimes()
+		mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+		return mockLogger
+	}
+
+	t.Run("gauge lifecycle", func(t *testing.T) {
+		ctx := context.Background()
+		repo := memory.NewStorage()
+
+		rule, err := alert.NewRule("HighHeap", "HeapAlloc > 100 for 2m", 0)
+		require.NoError(t, err)
+
+		e := alert.NewEngine(repo, []*alert.Rule{rule}, time.Second, newLogger(gomock.NewController(t)))
+		start := time.Now()
+
+		e.Evaluate(ctx, start)
+		assert.Equal(t, alert.StateInactive, e.Alerts()[0].State)
+		assert.Nil(t, e.Alerts()[0].Value)
+
+		repo.SetGauge(ctx, "HeapAlloc", 200)
+		e.Evaluate(ctx, start.Add(time.Minute))
+		assert.Equal(t, alert.StatePending, e.Alerts()[0].State)
+
+		e.Evaluate(ctx, start.Add(3*time.Minute))
+		assert.Equal(t, alert.StateFiring, e.Alerts()[0].State)
+		assert.Equal(t, float64(200), *e.Alerts()[0].Value)
+
+		repo.SetGauge(ctx, "HeapAlloc", 50)
+		e.Evaluate(ctx, start.Add(4*time.Minute))
+		assert.Equal(t, alert.StateResolved, e.Alerts()[0].State)
+		assert.NotNil(t, e.Alerts()[0].ResolvedAt)
+	})
+
+	t.Run("pending returns to inactive", func(t *testing.T) {
+		ctx := context.Background()
+		repo := memory.NewStorage()
+
+		rule, err := alert.NewRule("HighHeap", "HeapAlloc > 100 for 2m", 0)
+		require.NoError(t, err)
+
+		e := alert.NewEngine(repo, []*alert.Rule{rule}, time.Second, newLogger(gomock.NewController(t)))
+		start := time.Now()
+
+		repo.SetGauge(ctx, "HeapAlloc", 200)
+		e.Evaluate(ctx, start)
+		assert.Equal(t, alert.StatePending, e.Alerts()[0].State)
+
+		repo.SetGauge(ctx, "HeapAlloc", 50)
+		e.Evaluate(ctx, start.Add(time.Minute))
+		assert.Equal(t, alert.StateInactive, e.Alerts()[0].State)
+		assert.Nil(t, e.Alerts()[0].ActiveAt)
+	})
+
+	t.Run("counter rate", func(t *testing.T) {
+		ctx := context.Background()
+		repo := memory.NewStorage()
+
+		fast, err := alert.NewRule("FastPoll", "rate(PollCount) > 5", 0)
+		require.NoError(t, err)
+		slow, err := alert.NewRule("SlowPoll", "rate(PollCount) < 1", 0)
+		require.NoError(t, err)
+
+		e := alert.NewEngine(repo, []*alert.Rule{fast, slow}, time.Second, newLogger(gomock.NewController(t)))
+		start := time.Now()
+
+		repo.AddCounter(ctx, "PollCount", 10)
+		e.Evaluate(ctx, start)
+		assert.Equal(t, alert.StateInactive, e.Alerts()[0].State)
+		assert.Equal(t, alert.StateInactive, e.Alerts()[1].State)
+
+		repo.AddCounter(ctx, "PollCount", 100)
+		e.Evaluate(ctx, start.Add(10*time.Second))
+
+		alerts := e.Alerts()
+		assert.Equal(t, "FastPoll", alerts[0].Name)
+		assert.Equal(t, alert.StateFiring, alerts[0].State)
+		assert.Equal(t, float64(10), *alerts[0].Value)
+		assert.Equal(t, "SlowPoll", alerts[1].Name)
+		assert.Equal(t, alert.StateInactive, alerts[1].State)
+		assert.Equal(t, float64(10), *alerts[1].Value)
+	})
+	t.Run("storage error is logged", func(t *testing.T) {
+		ctx := context.Background()
+		ctrl := gomock.NewController(t)
+
+		rule, err := alert.NewRule("HighHeap", "HeapAlloc > 100", 0)
+		require.NoError(t, err)
+
+		mockLogger := mock.NewMockLogger(ctrl)
+		mockLogger.EXPECT().WithField("rule", "HighHeap").Return(mockLogger)
+		mockLogger.EXPECT().WithError(gomock.Any()).Return(mockLogger)
+		mockLogger.EXPECT().Error("Failed to evaluate alert rule")
+
+		e := alert.NewEngine(failingRepo{}, []*alert.Rule{rule}, time.Second, mockLogger)
+		e.Evaluate(ctx, time.Now())
+
+		assert.Equal(t, alert.StateInactive, e.Alerts()[0].State)
+		assert.Nil(t, e.Alerts()[0].Value)
+	})
+}
+
+type failingRepo struct{}
+
+func (failingRepo) GetGauge(_ context.Context, _ string) (float64, error) {
+	return 0, errors.New("storage is unavailable")
+}
+
+func (failingRepo) GetCounter(_ context.Context, _ string) (int64, error) {
+	return 0, errors.New("storage is unavailable")
+}
@@ -0,0 +1,117 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidExpr ошибка, если выражение правила не удалось разобрать.
+var ErrInvalidExpr = errors.New("alert expression is invalid")
+
+const (
+	funcRate = "rate"
+	forToken = "for"
+)
+
+// operator оператор сравнения значения метрики с порогом.
+type operator string
+
+const (
+	opGreater      operator = ">"
+	opGreaterEqual operator = ">="
+	opLess         operator = "<"
+	opLessEqual    operator = "<="
+	opEqual        operator = "=="
+	opNotEqual     operator = "!="
+)
+
+// Порядок важен: двухсимвольные операторы проверяются раньше односимвольных.
+var operators = []operator{opGreaterEqual, opLessEqual, opEqual, opNotEqual, opGreater, opLess}
+
+// expression разобранное выражение правила вида `HeapAlloc > 5e8` или `rate(PollCount) > 10`.
+type expression struct {
+	metric    string
+	rate      bool
+	op        operator
+	threshold float64
+}
+
+// parseExpr разбирает выражение правила.
+// Выражение может заканчиваться суффиксом `for <duration>`, тогда вторым параметром возвращается его длительность.
+func parseExpr(s string) (*expression, time.Duration, error) {
+	s = strings.TrimSpace(s)
+
+	var holdFor time.Duration
+	if fields := strings.Fields(s); len(fields) > 2 && fields[len(fields)-2] == forToken {
+		d, err := time.ParseDuration(fields[len(fields)-1])
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: parse 'for' duration: %s", ErrInvalidExpr, err)
+		}
+
+		holdFor = d
+		s = strings.TrimSpace(s[:strings.LastIndex(s, forToken)])
+	}
+
+	var (
+		op  operator
+		pos = -1
+	)
+	for _, o := range operators {
+		if i := strings.Index(s, string(o)); i > 0 {
+			op, pos = o, i
+			break
+		}
+	}
+
+	if pos < 0 {
+		return nil, 0, fmt.Errorf("%w: comparison operator not found in '%s'", ErrInvalidExpr, s)
+	}
+
+	left := strings.TrimSpace(s[:pos])
+	right := strings.TrimSpace(s[pos+len(op):])
+
+	threshold, err := strconv.ParseFloat(right, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: invalid threshold '%s'", ErrInvalidExpr, right)
+	}
+
+	e := &expression{
+		metric:    left,
+		op:        op,
+		threshold: threshold,
+	}
+
+	if strings.HasPrefix(left, funcRate+"(") && strings.HasSuffix(left, ")") {
+		e.rate = true
+		e.metric = strings.TrimSpace(left[len(funcRate)+1 : len(left)-1])
+	}
+
+	if e.metric == "" || strings.ContainsAny(e.metric, " ()") {
+		return nil, 0, fmt.Errorf("%w: invalid metric '%s'", ErrInvalidExpr, left)
+	}
+
+	return e, holdFor, nil
+}
+
+// match сравнивает значение с порогом выражения.
+func (e *expression) match(value float64) bool {
+	switch e.op {
+	case opGreater:
+		return value > e.threshold
+	case opGreaterEqual:
+		return value >= e.threshold
+	case opLess:
+		return value < e.threshold
+	case opLessEqual:
+		return value <= e.threshold
+	case opEqual:
+		return value == e.threshold
+	case opNotEqual:
+		return value != e.threshold
+	default:
+		return false
+	}
+}
@@ -0,0 +1,79 @@
+package alert_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bjlag/go-metrics/internal/alert"
+)
+
+func TestNewRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		holdFor time.Duration
+		wantFor time.Duration
+		wantErr bool
+	}{
+		{
+			name: "gauge threshold",
+			expr: "HeapAlloc > 5e8",
+		},
+		{
+			name:    "for in expression",
+			expr:    "HeapAlloc > 5e8 for 2m",
+			holdFor: time.Minute,
+			wantFor: 2 * time.Minute,
+		},
+		{
+			name:    "for from parameter",
+			expr:    "HeapAlloc >= 5e8",
+			holdFor: time.Minute,
+			wantFor: time.Minute,
+		},
+		{
+			name: "counter rate",
+			expr: "rate(PollCount) > 10",
+		},
+		{
+			name: "without spaces",
+			expr: "Alloc<=-1.5",
+		},
+		{
+			name:    "operator not found",
+			expr:    "HeapAlloc 5e8",
+			wantErr: true,
+		},
+		{
+			name:    "invalid threshold",
+			expr:    "HeapAlloc > many",
+			wantErr: true,
+		},
+		{
+			name:    "invalid duration",
+			expr:    "HeapAlloc > 1 for ever",
+			wantErr: true,
+		},
+		{
+			name:    "empty metric",
+			expr:    "rate() > 1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := alert.NewRule("rule", tt.expr, tt.holdFor)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, alert.ErrInvalidExpr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantFor, rule.For())
+			assert.Equal(t, tt.expr, rule.Expr())
+		})
+	}
+}
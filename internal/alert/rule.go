@@ -0,0 +1,82 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// State состояние алерта.
+type State string
+
+const (
+	// StateInactive условие правила не выполняется.
+	StateInactive State = "inactive"
+	// StatePending условие выполняется, но меньше времени, указанного в параметре for правила.
+	StatePending State = "pending"
+	// StateFiring условие выполняется дольше времени, указанного в параметре for правила.
+	StateFiring State = "firing"
+	// StateResolved условие перестало выполняться после срабатывания алерта.
+	StateResolved State = "resolved"
+)
+
+// Rule правило алертинга.
+type Rule struct {
+	name    string
+	expr    string
+	holdFor time.Duration
+	parsed  *expression
+}
+
+// NewRule создает правило.
+// Параметр expr задает выражение вида `HeapAlloc > 5e8` или `rate(PollCount) > 10`,
+// в конце выражения можно указать время удержания условия: `HeapAlloc > 5e8 for 2m`.
+// Параметр holdFor используется, если время удержания не указано в самом выражении.
+func NewRule(name, expr string, holdFor time.Duration) (*Rule, error) {
+	if name == "" {
+		return nil, errors.New("alert rule name is empty")
+	}
+
+	parsed, exprHoldFor, err := parseExpr(expr)
+	if err != nil {
+		return nil, fmt.Errorf("rule '%s': %w", name, err)
+	}
+
+	if exprHoldFor > 0 {
+		holdFor = exprHoldFor
+	}
+
+	return &Rule{
+		name:    name,
+		expr:    expr,
+		holdFor: holdFor,
+		parsed:  parsed,
+	}, nil
+}
+
+// Name возвращает название правила.
+func (r Rule) Name() string {
+	return r.name
+}
+
+// Expr возвращает выражение правила.
+func (r Rule) Expr() string {
+	return r.expr
+}
+
+// For возвращает время, в течение которого условие должно выполняться, чтобы алерт сработал.
+func (r Rule) For() time.Duration {
+	return r.holdFor
+}
+
+// Alert текущее состояние алерта по правилу.
+type Alert struct {
+	Name       string     `json:"name"`                  // Название правила
+	Expr       string     `json:"expr"`                  // Выражение правила
+	State      State      `json:"state"`                 // Состояние алерта
+	Value      *float64   `json:"value,omitempty"`       // Значение выражения на момент последней проверки
+	ActiveAt   *time.Time `json:"active_at,omitempty"`   // Когда условие начало выполняться
+	FiredAt    *time.Time `json:"fired_at,omitempty"`    // Когда алерт сработал
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"` // Когда алерт перестал быть активным
+	EvalAt     *time.Time `json:"eval_at,omitempty"`     // Время последней проверки
+}
@@ -29,22 +29,37 @@ func New(storage storage.Repository, fStorage *file.Storage, log logger.Logger)
 func (b *Backup) Create(ctx context.Context) error {
 	counters := b.storage.GetAllCounters(ctx)
 	gauges := b.storage.GetAllGauges(ctx)
+	histograms := b.storage.GetAllHistograms(ctx)
 
-	data := make([]file.Metric, 0, len(counters)+len(gauges))
+	data := make([]file.Metric, 0, len(counters)+len(gauges)+len(histograms))
 
-	for id, value := range counters {
+	for key, value := range counters {
+		id, labels := model.SplitSeriesKey(key)
 		data = append(data, file.Metric{
-			ID:    id,
-			MType: model.TypeCounter,
-			Delta: &value,
+			ID:     id,
+			MType:  model.TypeCounter,
+			Delta:  &value,
+			Labels: labels,
 		})
 	}
 
-	for id, value := range gauges {
+	for key, value := range gauges {
+		id, labels := model.SplitSeriesKey(key)
 		data = append(data, file.Metric{
-			ID:    id,
-			MType: model.TypeGauge,
-			Value: &value,
+			ID:     id,
+			MType:  model.TypeGauge,
+			Value:  &value,
+			Labels: labels,
+		})
+	}
+
+	for key, value := range histograms {
+		id, labels := model.SplitSeriesKey(key)
+		data = append(data, file.Metric{
+			ID:        id,
+			MType:     model.TypeHistogram,
+			Histogram: &value,
+			Labels:    labels,
 		})
 	}
 
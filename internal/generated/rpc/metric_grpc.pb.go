@@ -19,7 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MetricService_Updates_FullMethodName = "/metric.MetricService/Updates"
+	MetricService_Updates_FullMethodName       = "/metric.MetricService/Updates"
+	MetricService_StreamUpdates_FullMethodName = "/metric.MetricService/StreamUpdates"
+	MetricService_Delete_FullMethodName        = "/metric.MetricService/Delete"
+	MetricService_GetValue_FullMethodName      = "/metric.MetricService/GetValue"
+	MetricService_ListMetrics_FullMethodName   = "/metric.MetricService/ListMetrics"
+	MetricService_Watch_FullMethodName         = "/metric.MetricService/Watch"
+	MetricService_Query_FullMethodName         = "/metric.MetricService/Query"
 )
 
 // MetricServiceClient is the client API for MetricService service.
@@ -28,6 +34,19 @@ const (
 type MetricServiceClient interface {
 	// Обновление метрик батчами
 	Updates(ctx context.Context, in *UpdatesIn, opts ...grpc.CallOption) (*UpdatesOut, error)
+	// Обновление метрик батчами в рамках одного долгоживущего потока.
+	// Сервер периодически подтверждает принятые батчи, последнее подтверждение отправляется после закрытия потока клиентом.
+	StreamUpdates(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[UpdatesIn, StreamAck], error)
+	// Удаление метрик вместе с историей их значений
+	Delete(ctx context.Context, in *DeleteIn, opts ...grpc.CallOption) (*DeleteOut, error)
+	// Получение значения метрики
+	GetValue(ctx context.Context, in *GetValueIn, opts ...grpc.CallOption) (*GetValueOut, error)
+	// Получение списка метрик постранично, метрики упорядочены по названию и типу
+	ListMetrics(ctx context.Context, in *ListMetricsIn, opts ...grpc.CallOption) (*ListMetricsOut, error)
+	// Подписка на изменения метрик по мере их записи
+	Watch(ctx context.Context, in *WatchIn, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MetricEvent], error)
+	// Вычисление rate, increase, min, max или avg по истории значений метрики за окно
+	Query(ctx context.Context, in *QueryIn, opts ...grpc.CallOption) (*QueryOut, error)
 }
 
 type metricServiceClient struct {
@@ -48,12 +67,97 @@ func (c *metricServiceClient) Updates(ctx context.Context, in *UpdatesIn, opts .
 	return out, nil
 }
 
+func (c *metricServiceClient) StreamUpdates(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[UpdatesIn, StreamAck], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MetricService_ServiceDesc.Streams[0], MetricService_StreamUpdates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UpdatesIn, StreamAck]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricService_StreamUpdatesClient = grpc.BidiStreamingClient[UpdatesIn, StreamAck]
+
+func (c *metricServiceClient) Delete(ctx context.Context, in *DeleteIn, opts ...grpc.CallOption) (*DeleteOut, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteOut)
+	err := c.cc.Invoke(ctx, MetricService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricServiceClient) GetValue(ctx context.Context, in *GetValueIn, opts ...grpc.CallOption) (*GetValueOut, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetValueOut)
+	err := c.cc.Invoke(ctx, MetricService_GetValue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricServiceClient) ListMetrics(ctx context.Context, in *ListMetricsIn, opts ...grpc.CallOption) (*ListMetricsOut, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMetricsOut)
+	err := c.cc.Invoke(ctx, MetricService_ListMetrics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricServiceClient) Watch(ctx context.Context, in *WatchIn, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MetricEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MetricService_ServiceDesc.Streams[1], MetricService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchIn, MetricEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricService_WatchClient = grpc.ServerStreamingClient[MetricEvent]
+
+func (c *metricServiceClient) Query(ctx context.Context, in *QueryIn, opts ...grpc.CallOption) (*QueryOut, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryOut)
+	err := c.cc.Invoke(ctx, MetricService_Query_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MetricServiceServer is the server API for MetricService service.
 // All implementations should embed UnimplementedMetricServiceServer
 // for forward compatibility.
 type MetricServiceServer interface {
 	// Обновление метрик батчами
 	Updates(context.Context, *UpdatesIn) (*UpdatesOut, error)
+	// Обновление метрик батчами в рамках одного долгоживущего потока.
+	// Сервер периодически подтверждает принятые батчи, последнее подтверждение отправляется после закрытия потока клиентом.
+	StreamUpdates(grpc.BidiStreamingServer[UpdatesIn, StreamAck]) error
+	// Удаление метрик вместе с историей их значений
+	Delete(context.Context, *DeleteIn) (*DeleteOut, error)
+	// Получение значения метрики
+	GetValue(context.Context, *GetValueIn) (*GetValueOut, error)
+	// Получение списка метрик постранично, метрики упорядочены по названию и типу
+	ListMetrics(context.Context, *ListMetricsIn) (*ListMetricsOut, error)
+	// Подписка на изменения метрик по мере их записи
+	Watch(*WatchIn, grpc.ServerStreamingServer[MetricEvent]) error
+	// Вычисление rate, increase, min, max или avg по истории значений метрики за окно
+	Query(context.Context, *QueryIn) (*QueryOut, error)
 }
 
 // UnimplementedMetricServiceServer should be embedded to have
@@ -66,6 +170,24 @@ type UnimplementedMetricServiceServer struct{}
 func (UnimplementedMetricServiceServer) Updates(context.Context, *UpdatesIn) (*UpdatesOut, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Updates not implemented")
 }
+func (UnimplementedMetricServiceServer) StreamUpdates(grpc.BidiStreamingServer[UpdatesIn, StreamAck]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamUpdates not implemented")
+}
+func (UnimplementedMetricServiceServer) Delete(context.Context, *DeleteIn) (*DeleteOut, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedMetricServiceServer) GetValue(context.Context, *GetValueIn) (*GetValueOut, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetValue not implemented")
+}
+func (UnimplementedMetricServiceServer) ListMetrics(context.Context, *ListMetricsIn) (*ListMetricsOut, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMetrics not implemented")
+}
+func (UnimplementedMetricServiceServer) Watch(*WatchIn, grpc.ServerStreamingServer[MetricEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedMetricServiceServer) Query(context.Context, *QueryIn) (*QueryOut, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
 func (UnimplementedMetricServiceServer) testEmbeddedByValue() {}
 
 // UnsafeMetricServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -104,6 +226,96 @@ func _MetricService_Updates_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MetricService_StreamUpdates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MetricServiceServer).StreamUpdates(&grpc.GenericServerStream[UpdatesIn, StreamAck]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricService_StreamUpdatesServer = grpc.BidiStreamingServer[UpdatesIn, StreamAck]
+
+func _MetricService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteIn)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricServiceServer).Delete(ctx, req.(*DeleteIn))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricService_GetValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetValueIn)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricServiceServer).GetValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricService_GetValue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricServiceServer).GetValue(ctx, req.(*GetValueIn))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricService_ListMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMetricsIn)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricServiceServer).ListMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricService_ListMetrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricServiceServer).ListMetrics(ctx, req.(*ListMetricsIn))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchIn)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MetricServiceServer).Watch(m, &grpc.GenericServerStream[WatchIn, MetricEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricService_WatchServer = grpc.ServerStreamingServer[MetricEvent]
+
+func _MetricService_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryIn)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricServiceServer).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricService_Query_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricServiceServer).Query(ctx, req.(*QueryIn))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MetricService_ServiceDesc is the grpc.ServiceDesc for MetricService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,7 +327,35 @@ var MetricService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Updates",
 			Handler:    _MetricService_Updates_Handler,
 		},
+		{
+			MethodName: "Delete",
+			Handler:    _MetricService_Delete_Handler,
+		},
+		{
+			MethodName: "GetValue",
+			Handler:    _MetricService_GetValue_Handler,
+		},
+		{
+			MethodName: "ListMetrics",
+			Handler:    _MetricService_ListMetrics_Handler,
+		},
+		{
+			MethodName: "Query",
+			Handler:    _MetricService_Query_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamUpdates",
+			Handler:       _MetricService_StreamUpdates_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _MetricService_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/metric.proto",
 }
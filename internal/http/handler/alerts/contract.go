@@ -0,0 +1,15 @@
+package alerts
+
+import (
+	"github.com/bjlag/go-metrics/internal/alert"
+	"github.com/bjlag/go-metrics/internal/logger"
+)
+
+type engine interface {
+	Alerts() []alert.Alert
+}
+
+type log interface {
+	WithError(err error) logger.Logger
+	Error(msg string)
+}
@@ -0,0 +1,43 @@
+package alerts
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler обработчик HTTP запроса на получение текущего состояния алертов.
+type Handler struct {
+	engine engine
+	log    log
+}
+
+// NewHandler создает обработчик.
+func NewHandler(engine engine, log log) *Handler {
+	return &Handler{
+		engine: engine,
+		log:    log,
+	}
+}
+
+// Handle обрабатывает HTTP запрос.
+//
+//	@Summary	Получить состояние алертов.
+//	@Router		/alerts [get]
+//	@Security	BearerAuth
+//	@Produce	json
+//	@Success	200	{array}	alert.Alert
+//	@Failure	500	"Ошибка"
+func (h Handler) Handle(w http.ResponseWriter, _ *http.Request) {
+	data, err := json.Marshal(h.engine.Alerts())
+	if err != nil {
+		h.log.WithError(err).Error("Failed to marshal alerts")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		h.log.WithError(err).Error("Failed to write response")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,24 @@
+//go:generate mockgen -source ${GOFILE} -package mock -destination mock/contract_mock.go
+
+package batch
+
+import (
+	"context"
+
+	"github.com/bjlag/go-metrics/internal/logger"
+)
+
+type repo interface {
+	Delete(ctx context.Context, kind, name string) error
+}
+
+type backup interface {
+	Create(ctx context.Context) error
+}
+
+type log interface {
+	WithField(key string, value interface{}) logger.Logger
+	WithError(err error) logger.Logger
+	Error(msg string)
+	Info(msg string)
+}
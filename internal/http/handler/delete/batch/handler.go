@@ -0,0 +1,106 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bjlag/go-metrics/internal/model"
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+// Handler обработчик HTTP запроса на удаление метрик батчами.
+type Handler struct {
+	repo   repo
+	backup backup
+	log    log
+}
+
+// NewHandler создает обработчик.
+func NewHandler(repo repo, backup backup, log log) *Handler {
+	return &Handler{
+		repo:   repo,
+		backup: backup,
+		log:    log,
+	}
+}
+
+// Handle обрабатывает HTTP запрос.
+// Метрики, которых нет в хранилище, пропускаются.
+//
+//	@Summary	Удалить набор метрик.
+//	@Router		/deletes/ [post]
+//	@Security	BearerAuth
+//	@Accept		json
+//	@Param		HashSHA256			header	string			false	"Подпись запроса (если включена проверка подписи)"
+//	@Param		X-Sign-Timestamp	header	string			false	"Время подписи в секундах Unix (если включена защита от повторов)"
+//	@Param		X-Sign-Nonce		header	string			false	"Одноразовое значение запроса (если включена защита от повторов)"
+//	@Param		X-Key-ID			header	string			false	"Идентификатор ключа агента (если агенту выдан свой ключ)"
+//	@Param		value				body	[]model.ValueIn	true	"Request body"
+//	@Success	200					"Метрики удалены"
+//	@Failure	400					"Некорректный запрос"
+//	@Failure	500					"Ошибка"
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	var err error
+	var buf bytes.Buffer
+
+	_, err = buf.ReadFrom(r.Body)
+	if err != nil {
+		h.log.WithError(err).Error("Error reading request body")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	var in []model.ValueIn
+
+	err = json.Unmarshal(buf.Bytes(), &in)
+	if err != nil {
+		h.log.WithError(err).Info("Invalid request body")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.deleteMetrics(r.Context(), in)
+	if err != nil {
+		h.log.WithError(err).Error("Failed to delete metrics")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if deleted == 0 {
+		return
+	}
+
+	err = h.backup.Create(r.Context())
+	if err != nil {
+		h.log.WithError(err).Error("Failed to backup data")
+	}
+}
+
+func (h *Handler) deleteMetrics(ctx context.Context, in []model.ValueIn) (int, error) {
+	var deleted int
+
+	for _, m := range in {
+		err := h.repo.Delete(ctx, m.MType, m.SeriesKey())
+		if err != nil {
+			var metricNotFoundError *storage.NotFoundError
+			if errors.As(err, &metricNotFoundError) {
+				h.log.WithField("type", m.MType).
+					WithField("id", m.SeriesKey()).
+					Info("Metric not found")
+				continue
+			}
+
+			return deleted, err
+		}
+
+		deleted++
+	}
+
+	return deleted, nil
+}
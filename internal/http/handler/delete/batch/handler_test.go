@@ -0,0 +1,161 @@
+package batch_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bjlag/go-metrics/internal/http/handler/delete/batch"
+	"github.com/bjlag/go-metrics/internal/http/handler/delete/batch/mock"
+	mockLogger "github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+func TestHandler_Handle(t *testing.T) {
+	tests := []struct {
+		name       string
+		storage    func(ctrl *gomock.Controller) *mock.Mockrepo
+		backup     func(ctrl *gomock.Controller) *mock.Mockbackup
+		body       string
+		statusCode int
+	}{
+		{
+			name: "success",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().Delete(gomock.Any(), "gauge", "Alloc").Return(nil).Times(1)
+				mockStorage.EXPECT().Delete(gomock.Any(), "counter", `PollCount{host="alpha"}`).Return(nil).Times(1)
+				return mockStorage
+			},
+			backup: func(ctrl *gomock.Controller) *mock.Mockbackup {
+				mockBackup := mock.NewMockbackup(ctrl)
+				mockBackup.EXPECT().Create(context.Background()).Times(1)
+				return mockBackup
+			},
+			body:       `[{"id":"Alloc","type":"gauge"},{"id":"PollCount","type":"counter","labels":{"host":"alpha"}}]`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "not found is skipped",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().Delete(gomock.Any(), "counter", "PollCount").
+					Return(storage.NewMetricNotFoundError("counter", "PollCount", nil)).Times(1)
+				return mockStorage
+			},
+			backup: func(ctrl *gomock.Controller) *mock.Mockbackup {
+				mockBackup := mock.NewMockbackup(ctrl)
+				mockBackup.EXPECT().Create(gomock.Any()).Times(0)
+				return mockBackup
+			},
+			body:       `[{"id":"PollCount","type":"counter"}]`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "invalid type",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().Delete(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				return mockStorage
+			},
+			backup: func(ctrl *gomock.Controller) *mock.Mockbackup {
+				mockBackup := mock.NewMockbackup(ctrl)
+				mockBackup.EXPECT().Create(gomock.Any()).Times(0)
+				return mockBackup
+			},
+			body:       `[{"id":"Alloc","type":"other"}]`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid labels",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().Delete(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				return mockStorage
+			},
+			backup: func(ctrl *gomock.Controller) *mock.Mockbackup {
+				mockBackup := mock.NewMockbackup(ctrl)
+				mockBackup.EXPECT().Create(gomock.Any()).Times(0)
+				return mockBackup
+			},
+			body:       `[{"id":"Alloc","type":"gauge","labels":{"host-name":"alpha"}}]`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "malformed json",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().Delete(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				return mockStorage
+			},
+			backup: func(ctrl *gomock.Controller) *mock.Mockbackup {
+				mockBackup := mock.NewMockbackup(ctrl)
+				mockBackup.EXPECT().Create(gomock.Any()).Times(0)
+				return mockBackup
+			},
+			body:       `[{"id":"Alloc",`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "wrong json shape",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().Delete(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				return mockStorage
+			},
+			backup: func(ctrl *gomock.Controller) *mock.Mockbackup {
+				mockBackup := mock.NewMockbackup(ctrl)
+				mockBackup.EXPECT().Create(gomock.Any()).Times(0)
+				return mockBackup
+			},
+			body:       `{"id":"Alloc","type":"gauge"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "storage error",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().Delete(gomock.Any(), "gauge", "Alloc").Return(errors.New("some error")).Times(1)
+				return mockStorage
+			},
+			backup: func(ctrl *gomock.Controller) *mock.Mockbackup {
+				mockBackup := mock.NewMockbackup(ctrl)
+				mockBackup.EXPECT().Create(gomock.Any()).Times(0)
+				return mockBackup
+			},
+			body:       `[{"id":"Alloc","type":"gauge"}]`,
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			log := mockLogger.NewMockLogger(ctrl)
+			log.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(log).AnyTimes()
+			log.EXPECT().WithError(gomock.Any()).Return(log).AnyTimes()
+			log.EXPECT().Info(gomock.Any()).AnyTimes()
+			log.EXPECT().Error(gomock.Any()).AnyTimes()
+
+			w := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/deletes/", strings.NewReader(tt.body))
+
+			h := http.HandlerFunc(batch.NewHandler(tt.storage(ctrl), tt.backup(ctrl), log).Handle)
+			h.ServeHTTP(w, request)
+
+			response := w.Result()
+			defer func() {
+				_ = response.Body.Close()
+			}()
+
+			assert.Equal(t, tt.statusCode, response.StatusCode)
+		})
+	}
+}
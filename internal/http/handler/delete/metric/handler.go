@@ -0,0 +1,82 @@
+package metric
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bjlag/go-metrics/internal/model"
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+// Handler обработчик HTTP запроса на удаление метрики.
+type Handler struct {
+	repo   repo
+	backup backup
+	log    log
+}
+
+// NewHandler создает обработчик.
+func NewHandler(repo repo, backup backup, log log) *Handler {
+	return &Handler{
+		repo:   repo,
+		backup: backup,
+		log:    log,
+	}
+}
+
+// Handle обрабатывает HTTP запрос.
+//
+//	@Summary	Удалить метрику вместе с историей ее значений.
+//	@Router		/value/{kind}/{name} [delete]
+//	@Security	BearerAuth
+//	@Param		HashSHA256			header	string	false	"Подпись запроса (если включена проверка подписи)"
+//	@Param		X-Sign-Timestamp	header	string	false	"Время подписи в секундах Unix (если включена защита от повторов)"
+//	@Param		X-Sign-Nonce		header	string	false	"Одноразовое значение запроса (если включена защита от повторов)"
+//	@Param		X-Key-ID			header	string	false	"Идентификатор ключа агента (если агенту выдан свой ключ)"
+//	@Param		kind				path	string	true	"Тип метрики: gauge, counter или histogram"	example(gauge)
+//	@Param		name				path	string	true	"Название метрики"							example(Sys)
+//	@Success	200					"Метрика удалена"
+//	@Failure	400					"Неизвестный тип метрики или некорректное название"
+//	@Failure	404					"Метрика не найдена"
+//	@Failure	500					"Ошибка"
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	kind := r.PathValue("kind")
+	name := r.PathValue("name")
+
+	if kind != model.TypeGauge && kind != model.TypeCounter && kind != model.TypeHistogram {
+		h.log.WithField("type", kind).
+			WithField("url", r.URL.Path).
+			Info("Invalid metric type")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if !model.IsValidID(name) {
+		h.log.WithField("name", name).
+			WithField("url", r.URL.Path).
+			Info("Invalid metric name")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	err := h.repo.Delete(r.Context(), kind, name)
+	if err != nil {
+		var metricNotFoundError *storage.NotFoundError
+		if errors.As(err, &metricNotFoundError) {
+			h.log.WithField("type", kind).
+				WithField("name", name).
+				Info("Metric not found")
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		h.log.WithError(err).Error("Failed to delete metric")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	err = h.backup.Create(r.Context())
+	if err != nil {
+		h.log.WithError(err).Error("Failed to backup data")
+	}
+}
@@ -0,0 +1,137 @@
+package metric_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bjlag/go-metrics/internal/http/handler/delete/metric"
+	"github.com/bjlag/go-metrics/internal/http/handler/delete/metric/mock"
+	mockLogger "github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+func TestHandler_Handle(t *testing.T) {
+	type fields struct {
+		kind string
+		name string
+	}
+
+	tests := []struct {
+		name       string
+		storage    func(ctrl *gomock.Controller) *mock.Mockrepo
+		backup     func(ctrl *gomock.Controller) *mock.Mockbackup
+		fields     fields
+		statusCode int
+	}{
+		{
+			name: "success",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().Delete(gomock.Any(), "gauge", "Alloc").Return(nil).Times(1)
+				return mockStorage
+			},
+			backup: func(ctrl *gomock.Controller) *mock.Mockbackup {
+				mockBackup := mock.NewMockbackup(ctrl)
+				mockBackup.EXPECT().Create(context.Background()).Times(1)
+				return mockBackup
+			},
+			fields:     fields{kind: "gauge", name: "Alloc"},
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "not found",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().Delete(gomock.Any(), "counter", "PollCount").
+					Return(storage.NewMetricNotFoundError("counter", "PollCount", nil)).Times(1)
+				return mockStorage
+			},
+			backup: func(ctrl *gomock.Controller) *mock.Mockbackup {
+				mockBackup := mock.NewMockbackup(ctrl)
+				mockBackup.EXPECT().Create(gomock.Any()).Times(0)
+				return mockBackup
+			},
+			fields:     fields{kind: "counter", name: "PollCount"},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "unknown kind",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().Delete(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				return mockStorage
+			},
+			backup: func(ctrl *gomock.Controller) *mock.Mockbackup {
+				mockBackup := mock.NewMockbackup(ctrl)
+				mockBackup.EXPECT().Create(gomock.Any()).Times(0)
+				return mockBackup
+			},
+			fields:     fields{kind: "other", name: "Alloc"},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid name",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().Delete(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				return mockStorage
+			},
+			backup: func(ctrl *gomock.Controller) *mock.Mockbackup {
+				mockBackup := mock.NewMockbackup(ctrl)
+				mockBackup.EXPECT().Create(gomock.Any()).Times(0)
+				return mockBackup
+			},
+			fields:     fields{kind: "gauge", name: "Alloc{host}"},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "storage error",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().Delete(gomock.Any(), "gauge", "Alloc").Return(errors.New("some error")).Times(1)
+				return mockStorage
+			},
+			backup: func(ctrl *gomock.Controller) *mock.Mockbackup {
+				mockBackup := mock.NewMockbackup(ctrl)
+				mockBackup.EXPECT().Create(gomock.Any()).Times(0)
+				return mockBackup
+			},
+			fields:     fields{kind: "gauge", name: "Alloc"},
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			log := mockLogger.NewMockLogger(ctrl)
+			log.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(log).AnyTimes()
+			log.EXPECT().WithError(gomock.Any()).Return(log).AnyTimes()
+			log.EXPECT().Info(gomock.Any()).AnyTimes()
+			log.EXPECT().Error(gomock.Any()).AnyTimes()
+
+			w := httptest.NewRecorder()
+
+			request := httptest.NewRequest(http.MethodDelete, "/", nil)
+			request.SetPathValue("kind", tt.fields.kind)
+			request.SetPathValue("name", tt.fields.name)
+
+			h := http.HandlerFunc(metric.NewHandler(tt.storage(ctrl), tt.backup(ctrl), log).Handle)
+			h.ServeHTTP(w, request)
+
+			response := w.Result()
+			defer func() {
+				_ = response.Body.Close()
+			}()
+
+			assert.Equal(t, tt.statusCode, response.StatusCode)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"io"
 
+	"github.com/bjlag/go-metrics/internal/alert"
 	"github.com/bjlag/go-metrics/internal/logger"
 	"github.com/bjlag/go-metrics/internal/storage"
 )
@@ -17,6 +18,11 @@ type renderer interface {
 type repo interface {
 	GetAllGauges(ctx context.Context) storage.Gauges
 	GetAllCounters(ctx context.Context) storage.Counters
+	GetAllHistograms(ctx context.Context) storage.Histograms
+}
+
+type alerts interface {
+	Alerts() []alert.Alert
 }
 
 type log interface {
@@ -3,6 +3,7 @@ package list
 import (
 	"net/http"
 
+	"github.com/bjlag/go-metrics/internal/alert"
 	"github.com/bjlag/go-metrics/internal/storage"
 )
 
@@ -14,14 +15,16 @@ const (
 type Handler struct {
 	renderer renderer
 	repo     repo
+	alerts   alerts
 	log      log
 }
 
 // NewHandler создает обработчик.
-func NewHandler(renderer renderer, repo repo, log log) *Handler {
+func NewHandler(renderer renderer, repo repo, alerts alerts, log log) *Handler {
 	return &Handler{
 		renderer: renderer,
 		repo:     repo,
+		alerts:   alerts,
 		log:      log,
 	}
 }
@@ -29,13 +32,17 @@ func NewHandler(renderer renderer, repo repo, log log) *Handler {
 // Handle обрабатывает HTTP запрос.
 func (h Handler) Handle(w http.ResponseWriter, r *http.Request) {
 	data := struct {
-		Title    string
-		Gauges   storage.Gauges
-		Counters storage.Counters
+		Title      string
+		Gauges     storage.Gauges
+		Counters   storage.Counters
+		Histograms storage.Histograms
+		Alerts     []alert.Alert
 	}{
-		Title:    "Список метрик",
-		Gauges:   h.repo.GetAllGauges(r.Context()),
-		Counters: h.repo.GetAllCounters(r.Context()),
+		Title:      "Список метрик",
+		Gauges:     h.repo.GetAllGauges(r.Context()),
+		Counters:   h.repo.GetAllCounters(r.Context()),
+		Histograms: h.repo.GetAllHistograms(r.Context()),
+		Alerts:     h.alerts.Alerts(),
 	}
 
 	err := h.renderer.Render(w, "list.html", data)
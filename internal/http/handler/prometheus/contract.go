@@ -0,0 +1,23 @@
+//go:generate mockgen -source ${GOFILE} -package mock -destination mock/contract_mock.go
+
+package prometheus
+
+import (
+	"context"
+
+	"github.com/bjlag/go-metrics/internal/logger"
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+type repo interface {
+	GetAllGauges(ctx context.Context) storage.Gauges
+	GetAllCounters(ctx context.Context) storage.Counters
+	GetAllHistograms(ctx context.Context) storage.Histograms
+}
+
+type log interface {
+	WithField(key string, value interface{}) logger.Logger
+	WithError(err error) logger.Logger
+	Error(msg string)
+	Info(msg string)
+}
@@ -0,0 +1,197 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bjlag/go-metrics/internal/model"
+)
+
+// ContentType тип содержимого ответа в текстовом формате Prometheus версии 0.0.4.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Handler обработчик HTTP запроса на выгрузку всех метрик в текстовом формате Prometheus.
+//
+// Названия метрик приводятся к виду, допустимому в Prometheus: [a-zA-Z_:][a-zA-Z0-9_:]*.
+// Серии одной метрики с разными метками выгружаются одним семейством с общей строкой # TYPE.
+// Гистограммы выгружаются сериями _bucket с накопительными значениями, _sum и _count.
+// Если после приведения названия нескольких метрик совпадают, выгружается только первая из них.
+type Handler struct {
+	repo repo
+	log  log
+}
+
+// family семейство серий одной метрики.
+type family struct {
+	id     string
+	kind   string
+	series []string
+}
+
+// NewHandler создает обработчик.
+func NewHandler(repo repo, log log) *Handler {
+	return &Handler{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// Handle обрабатывает HTTP запрос.
+//
+//	@Summary	Выгрузить метрики в формате Prometheus.
+//	@Router		/metrics [get]
+//	@Security	BearerAuth
+//	@Produce	plain
+//	@Success	200	{string}	string	"Метрики в текстовом формате Prometheus 0.0.4"
+//	@Failure	500	"Ошибка"
+func (h Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	var (
+		names    []string
+		families = make(map[string]*family)
+	)
+
+	add := func(key, kind string, lines func(name string, labels model.Labels) []string) {
+		id, labels := model.SplitSeriesKey(key)
+		name := SanitizeName(id)
+
+		f, ok := families[name]
+		if !ok {
+			f = &family{id: id, kind: kind}
+			families[name] = f
+			names = append(names, name)
+		}
+
+		if f.id != id || f.kind != kind {
+			h.log.WithField("id", id).
+				WithField("type", kind).
+				WithField("name", name).
+				Info("Duplicate Prometheus metric name, metric skipped")
+			return
+		}
+
+		f.series = append(f.series, lines(name, labels)...)
+	}
+
+	counters := h.repo.GetAllCounters(r.Context())
+	for _, key := range sortedKeys(counters) {
+		add(key, model.TypeCounter, func(name string, labels model.Labels) []string {
+			return []string{seriesName(name, labels) + " " + strconv.FormatInt(counters[key], 10)}
+		})
+	}
+
+	gauges := h.repo.GetAllGauges(r.Context())
+	for _, key := range sortedKeys(gauges) {
+		add(key, model.TypeGauge, func(name string, labels model.Labels) []string {
+			return []string{seriesName(name, labels) + " " + formatFloat(gauges[key])}
+		})
+	}
+
+	histograms := h.repo.GetAllHistograms(r.Context())
+	for _, key := range sortedKeys(histograms) {
+		add(key, model.TypeHistogram, func(name string, labels model.Labels) []string {
+			return histogramLines(name, labels, histograms[key])
+		})
+	}
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		f := families[name]
+
+		_, _ = fmt.Fprintf(&buf, "# TYPE %s %s\n", name, f.kind)
+		for _, line := range f.series {
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+
+	_, err := w.Write(buf.Bytes())
+	if err != nil {
+		h.log.WithError(err).Error("Failed to write response")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// Функция histogramLines возвращает серии гистограммы: бакеты с накопительными значениями, сумму и количество наблюдений.
+func histogramLines(name string, labels model.Labels, h model.Histogram) []string {
+	lines := make([]string, 0, len(h.Counts)+2)
+
+	var cumulative uint64
+	for i, c := range h.Counts {
+		cumulative += c
+
+		le := "+Inf"
+		if i < len(h.Bounds) {
+			le = formatFloat(h.Bounds[i])
+		}
+
+		bucketLabels := make(model.Labels, len(labels)+1)
+		for k, v := range labels {
+			bucketLabels[k] = v
+		}
+		bucketLabels["le"] = le
+
+		lines = append(lines, seriesName(name+"_bucket", bucketLabels)+" "+strconv.FormatUint(cumulative, 10))
+	}
+
+	lines = append(lines,
+		seriesName(name+"_sum", labels)+" "+formatFloat(h.Sum),
+		seriesName(name+"_count", labels)+" "+strconv.FormatUint(h.Count, 10),
+	)
+
+	return lines
+}
+
+func seriesName(name string, labels model.Labels) string {
+	if len(labels) == 0 {
+		return name
+	}
+
+	return name + "{" + labels.String() + "}"
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// SanitizeName приводит название метрики к виду, допустимому в Prometheus.
+// Недопустимые символы заменяются на '_', если название начинается с цифры, к нему добавляется префикс '_'.
+func SanitizeName(id string) string {
+	var b strings.Builder
+	b.Grow(len(id) + 1)
+
+	for i, ch := range id {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch == '_', ch == ':':
+			b.WriteRune(ch)
+		case ch >= '0' && ch <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(ch)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	if b.Len() == 0 {
+		return "_"
+	}
+
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,149 @@
+package prometheus_test
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/http/handler/prometheus"
+	"github.com/bjlag/go-metrics/internal/http/handler/prometheus/mock"
+	mockLogger "github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+func TestHandler_Handle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	repo := mock.NewMockrepo(ctrl)
+	repo.EXPECT().GetAllCounters(gomock.Any()).Return(storage.Counters{
+		"PollCount": 5,
+		"1st.count": 1,
+	})
+	repo.EXPECT().GetAllGauges(gomock.Any()).Return(storage.Gauges{
+		"HeapAlloc":  1.5e8,
+		"PollCount":  1,
+		"Inf-Value":  math.Inf(1),
+		"cpu:util%1": 0.25,
+	})
+	repo.EXPECT().GetAllHistograms(gomock.Any()).Return(storage.Histograms{})
+
+	log := mockLogger.NewMockLogger(ctrl)
+	log.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(log).Times(3)
+	log.EXPECT().Info(gomock.Any()).Times(1)
+
+	w := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+
+	h := http.HandlerFunc(prometheus.NewHandler(repo, log).Handle)
+	h.ServeHTTP(w, request)
+
+	response := w.Result()
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, prometheus.ContentType, response.Header.Get("Content-Type"))
+	assert.Equal(t, `# TYPE _1st_count counter
+_1st_count 1
+# TYPE PollCount counter
+PollCount 5
+# TYPE HeapAlloc gauge
+HeapAlloc 1.5e+08
+# TYPE Inf_Value gauge
+Inf_Value +Inf
+# TYPE cpu:util_1 gauge
+cpu:util_1 0.25
+`, string(body))
+}
+
+func TestHandler_Handle_LabelsAndHistograms(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	repo := mock.NewMockrepo(ctrl)
+	repo.EXPECT().GetAllCounters(gomock.Any()).Return(storage.Counters{
+		`Requests{code="200",path="/"}`: 3,
+		`Requests{code="500",path="/"}`: 1,
+		"Requests":                      2,
+	})
+	repo.EXPECT().GetAllGauges(gomock.Any()).Return(storage.Gauges{
+		`Requests{code="200"}`:       7,
+		`Temp{sensor="a \"b\"\\ c"}`: 36.6,
+	})
+	repo.EXPECT().GetAllHistograms(gomock.Any()).Return(storage.Histograms{
+		`Latency{path="/"}`: {
+			Bounds: []float64{0.1, 0.5},
+			Counts: []uint64{3, 5, 1},
+			Sum:    2.7,
+			Count:  9,
+		},
+		"Temp": {
+			Counts: []uint64{1},
+			Sum:    1,
+			Count:  1,
+		},
+	})
+
+	log := mockLogger.NewMockLogger(ctrl)
+	log.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(log).Times(6)
+	log.EXPECT().Info(gomock.Any()).Times(2)
+
+	w := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+
+	h := http.HandlerFunc(prometheus.NewHandler(repo, log).Handle)
+	h.ServeHTTP(w, request)
+
+	response := w.Result()
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `# TYPE Requests counter
+Requests 2
+Requests{code="200",path="/"} 3
+Requests{code="500",path="/"} 1
+# TYPE Temp gauge
+Temp{sensor="a \"b\"\\ c"} 36.6
+# TYPE Latency histogram
+Latency_bucket{le="0.1",path="/"} 3
+Latency_bucket{le="0.5",path="/"} 8
+Latency_bucket{le="+Inf",path="/"} 9
+Latency_sum{path="/"} 2.7
+Latency_count{path="/"} 9
+`, string(body))
+}
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{id: "HeapAlloc", want: "HeapAlloc"},
+		{id: "http_requests:rate", want: "http_requests:rate"},
+		{id: "CPUutilization1", want: "CPUutilization1"},
+		{id: "9lives", want: "_9lives"},
+		{id: "disk /var used", want: "disk__var_used"},
+		{id: "метрика", want: "_______"},
+		{id: "", want: "_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			assert.Equal(t, tt.want, prometheus.SanitizeName(tt.id))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	logger "github.com/bjlag/go-metrics/internal/logger"
+	storage "github.com/bjlag/go-metrics/internal/storage"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// Mockrepo is a mock of repo interface.
+type Mockrepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockrepoMockRecorder
+}
+
+// MockrepoMockRecorder is the mock recorder for Mockrepo.
+type MockrepoMockRecorder struct {
+	mock *Mockrepo
+}
+
+// NewMockrepo creates a new mock instance.
+func NewMockrepo(ctrl *gomock.Controller) *Mockrepo {
+	mock := &Mockrepo{ctrl: ctrl}
+	mock.recorder = &MockrepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockrepo) EXPECT() *MockrepoMockRecorder {
+	return m.recorder
+}
+
+// GetAllCounters mocks base method.
+func (m *Mockrepo) GetAllCounters(ctx context.Context) storage.Counters {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllCounters", ctx)
+	ret0, _ := ret[0].(storage.Counters)
+	return ret0
+}
+
+// GetAllCounters indicates an expected call of GetAllCounters.
+func (mr *MockrepoMockRecorder) GetAllCounters(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCounters", reflect.TypeOf((*Mockrepo)(nil).GetAllCounters), ctx)
+}
+
+// GetAllGauges mocks base method.
+func (m *Mockrepo) GetAllGauges(ctx context.Context) storage.Gauges {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllGauges", ctx)
+	ret0, _ := ret[0].(storage.Gauges)
+	return ret0
+}
+
+// GetAllGauges indicates an expected call of GetAllGauges.
+func (mr *MockrepoMockRecorder) GetAllGauges(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllGauges", reflect.TypeOf((*Mockrepo)(nil).GetAllGauges), ctx)
+}
+
+// GetAllHistograms mocks base method.
+func (m *Mockrepo) GetAllHistograms(ctx context.Context) storage.Histograms {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllHistograms", ctx)
+	ret0, _ := ret[0].(storage.Histograms)
+	return ret0
+}
+
+// GetAllHistograms indicates an expected call of GetAllHistograms.
+func (mr *MockrepoMockRecorder) GetAllHistograms(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllHistograms", reflect.TypeOf((*Mockrepo)(nil).GetAllHistograms), ctx)
+}
+
+// Mocklog is a mock of log interface.
+type Mocklog struct {
+	ctrl     *gomock.Controller
+	recorder *MocklogMockRecorder
+}
+
+// MocklogMockRecorder is the mock recorder for Mocklog.
+type MocklogMockRecorder struct {
+	mock *Mocklog
+}
+
+// NewMocklog creates a new mock instance.
+func NewMocklog(ctrl *gomock.Controller) *Mocklog {
+	mock := &Mocklog{ctrl: ctrl}
+	mock.recorder = &MocklogMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mocklog) EXPECT() *MocklogMockRecorder {
+	return m.recorder
+}
+
+// Error mocks base method.
+func (m *Mocklog) Error(msg string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Error", msg)
+}
+
+// Error indicates an expected call of Error.
+func (mr *MocklogMockRecorder) Error(msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*Mocklog)(nil).Error), msg)
+}
+
+// Info mocks base method.
+func (m *Mocklog) Info(msg string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Info", msg)
+}
+
+// Info indicates an expected call of Info.
+func (mr *MocklogMockRecorder) Info(msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*Mocklog)(nil).Info), msg)
+}
+
+// WithError mocks base method.
+func (m *Mocklog) WithError(err error) logger.Logger {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithError", err)
+	ret0, _ := ret[0].(logger.Logger)
+	return ret0
+}
+
+// WithError indicates an expected call of WithError.
+func (mr *MocklogMockRecorder) WithError(err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithError", reflect.TypeOf((*Mocklog)(nil).WithError), err)
+}
+
+// WithField mocks base method.
+func (m *Mocklog) WithField(key string, value interface{}) logger.Logger {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithField", key, value)
+	ret0, _ := ret[0].(logger.Logger)
+	return ret0
+}
+
+// WithField indicates an expected call of WithField.
+func (mr *MocklogMockRecorder) WithField(key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithField", reflect.TypeOf((*Mocklog)(nil).WithField), key, value)
+}
@@ -0,0 +1,135 @@
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bjlag/go-metrics/internal/model"
+	"github.com/bjlag/go-metrics/internal/query"
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+// Handler обработчик HTTP запроса к истории значений метрики.
+type Handler struct {
+	engine engine
+	log    log
+}
+
+// NewHandler создает обработчик.
+func NewHandler(engine engine, log log) *Handler {
+	return &Handler{
+		engine: engine,
+		log:    log,
+	}
+}
+
+// Handle обрабатывает HTTP запрос.
+//
+//	@Summary	Вычислить rate, increase, min, max или avg по истории значений метрики за окно.
+//	@Router		/query/ [get]
+//	@Security	BearerAuth
+//	@Produce	json
+//	@Param		func	query		string	true	"Функция: rate, increase, min, max или avg"	example(rate)
+//	@Param		type	query		string	true	"Тип метрики: gauge или counter"			example(counter)
+//	@Param		id		query		string	true	"Название метрики"							example(PollCount)
+//	@Param		window	query		string	true	"Окно, например 5m"							example(5m)
+//	@Param		labels	query		string	false	"Метки метрики: name=value,name=value"		example(host=localhost)
+//	@Success	200		{object}	model.QueryOut
+//	@Failure	400		"Некорректный запрос"
+//	@Failure	404		"Метрика не найдена или в окне недостаточно значений"
+//	@Failure	500		"Ошибка"
+func (h Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	window, err := time.ParseDuration(params.Get("window"))
+	if err != nil {
+		h.log.WithField("window", params.Get("window")).Info("Invalid query window")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	labels, err := parseLabels(params.Get("labels"))
+	if err != nil {
+		h.log.WithError(err).Info("Invalid query labels")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	req := query.Request{
+		Func:   params.Get("func"),
+		Kind:   params.Get("type"),
+		ID:     params.Get("id"),
+		Window: window,
+	}
+
+	if req.ID != "" {
+		req.ID = model.SeriesKey(req.ID, labels)
+	}
+
+	result, err := h.engine.Query(r.Context(), req)
+	if err != nil {
+		var metricNotFoundError *storage.NotFoundError
+
+		switch {
+		case errors.Is(err, query.ErrInvalidQuery):
+			h.log.WithError(err).Info("Invalid query")
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		case errors.Is(err, query.ErrNoData), errors.As(err, &metricNotFoundError):
+			h.log.WithField("id", req.ID).WithError(err).Info("No data for query")
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		default:
+			h.log.WithError(err).Error("Failed to execute query")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	data, err := json.Marshal(model.QueryOut{
+		Func:    req.Func,
+		ID:      params.Get("id"),
+		MType:   req.Kind,
+		Labels:  labels,
+		Window:  window.String(),
+		Value:   result.Value,
+		Samples: result.Samples,
+	})
+	if err != nil {
+		h.log.WithError(err).Error("Failed to marshal response")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		h.log.WithError(err).Error("Failed to write response")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// Функция parseLabels разбирает метки в формате name=value,name=value.
+func parseLabels(s string) (model.Labels, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	labels := make(model.Labels)
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", model.ErrInvalidLabels, pair)
+		}
+
+		labels[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	if err := labels.Validate(); err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
@@ -0,0 +1,121 @@
+package query_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	handler "github.com/bjlag/go-metrics/internal/http/handler/query"
+	"github.com/bjlag/go-metrics/internal/http/handler/query/mock"
+	mockLogger "github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/query"
+)
+
+func TestHandler_Handle(t *testing.T) {
+	type want struct {
+		status int
+		body   string
+	}
+
+	tests := []struct {
+		name    string
+		target  string
+		req     *query.Request
+		result  query.Result
+		err     error
+		want    want
+		wantLog bool
+	}{
+		{
+			name:   "rate",
+			target: "/query/?func=rate&type=counter&id=Requests&window=5m&labels=code=200,path=/",
+			req:    &query.Request{Func: query.FuncRate, Kind: "counter", ID: `Requests{code="200",path="/"}`, Window: 5 * time.Minute},
+			result: query.Result{Value: 1.5, Samples: 10},
+			want: want{
+				status: http.StatusOK,
+				body:   `{"func":"rate","id":"Requests","type":"counter","labels":{"code":"200","path":"/"},"window":"5m0s","value":1.5,"samples":10}`,
+			},
+		},
+		{
+			name:    "invalid window",
+			target:  "/query/?func=rate&type=counter&id=Requests&window=5",
+			want:    want{status: http.StatusBadRequest},
+			wantLog: true,
+		},
+		{
+			name:    "invalid labels",
+			target:  "/query/?func=rate&type=counter&id=Requests&window=5m&labels=code",
+			want:    want{status: http.StatusBadRequest},
+			wantLog: true,
+		},
+		{
+			name:    "invalid query",
+			target:  "/query/?func=rate&type=gauge&id=Alloc&window=5m",
+			req:     &query.Request{Func: query.FuncRate, Kind: "gauge", ID: "Alloc", Window: 5 * time.Minute},
+			err:     query.ErrInvalidQuery,
+			want:    want{status: http.StatusBadRequest},
+			wantLog: true,
+		},
+		{
+			name:    "no data",
+			target:  "/query/?func=avg&type=gauge&id=Alloc&window=1m",
+			req:     &query.Request{Func: query.FuncAvg, Kind: "gauge", ID: "Alloc", Window: time.Minute},
+			err:     query.ErrNoData,
+			want:    want{status: http.StatusNotFound},
+			wantLog: true,
+		},
+		{
+			name:    "internal error",
+			target:  "/query/?func=max&type=gauge&id=Alloc&window=1m",
+			req:     &query.Request{Func: query.FuncMax, Kind: "gauge", ID: "Alloc", Window: time.Minute},
+			err:     errors.New("some error"),
+			want:    want{status: http.StatusInternalServerError},
+			wantLog: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			engine := mock.NewMockengine(ctrl)
+			if tt.req != nil {
+				engine.EXPECT().Query(gomock.Any(), *tt.req).Return(tt.result, tt.err)
+			}
+
+			log := mockLogger.NewMockLogger(ctrl)
+			if tt.wantLog {
+				log.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(log).AnyTimes()
+				log.EXPECT().WithError(gomock.Any()).Return(log).AnyTimes()
+				log.EXPECT().Info(gomock.Any()).AnyTimes()
+				log.EXPECT().Error(gomock.Any()).AnyTimes()
+			}
+
+			w := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+
+			h := http.HandlerFunc(handler.NewHandler(engine, log).Handle)
+			h.ServeHTTP(w, request)
+
+			response := w.Result()
+			defer func() {
+				_ = response.Body.Close()
+			}()
+
+			assert.Equal(t, tt.want.status, response.StatusCode)
+
+			if tt.want.body != "" {
+				body, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.want.body, string(body))
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	logger "github.com/bjlag/go-metrics/internal/logger"
+	query "github.com/bjlag/go-metrics/internal/query"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// Mockengine is a mock of engine interface.
+type Mockengine struct {
+	ctrl     *gomock.Controller
+	recorder *MockengineMockRecorder
+}
+
+// MockengineMockRecorder is the mock recorder for Mockengine.
+type MockengineMockRecorder struct {
+	mock *Mockengine
+}
+
+// NewMockengine creates a new mock instance.
+func NewMockengine(ctrl *gomock.Controller) *Mockengine {
+	mock := &Mockengine{ctrl: ctrl}
+	mock.recorder = &MockengineMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockengine) EXPECT() *MockengineMockRecorder {
+	return m.recorder
+}
+
+// Query mocks base method.
+func (m *Mockengine) Query(ctx context.Context, req query.Request) (query.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, req)
+	ret0, _ := ret[0].(query.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockengineMockRecorder) Query(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*Mockengine)(nil).Query), ctx, req)
+}
+
+// Mocklog is a mock of log interface.
+type Mocklog struct {
+	ctrl     *gomock.Controller
+	recorder *MocklogMockRecorder
+}
+
+// MocklogMockRecorder is the mock recorder for Mocklog.
+type MocklogMockRecorder struct {
+	mock *Mocklog
+}
+
+// NewMocklog creates a new mock instance.
+func NewMocklog(ctrl *gomock.Controller) *Mocklog {
+	mock := &Mocklog{ctrl: ctrl}
+	mock.recorder = &MocklogMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mocklog) EXPECT() *MocklogMockRecorder {
+	return m.recorder
+}
+
+// Error mocks base method.
+func (m *Mocklog) Error(msg string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Error", msg)
+}
+
+// Error indicates an expected call of Error.
+func (mr *MocklogMockRecorder) Error(msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*Mocklog)(nil).Error), msg)
+}
+
+// Info mocks base method.
+func (m *Mocklog) Info(msg string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Info", msg)
+}
+
+// Info indicates an expected call of Info.
+func (mr *MocklogMockRecorder) Info(msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*Mocklog)(nil).Info), msg)
+}
+
+// WithError mocks base method.
+func (m *Mocklog) WithError(err error) logger.Logger {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithError", err)
+	ret0, _ := ret[0].(logger.Logger)
+	return ret0
+}
+
+// WithError indicates an expected call of WithError.
+func (mr *MocklogMockRecorder) WithError(err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithError", reflect.TypeOf((*Mocklog)(nil).WithError), err)
+}
+
+// WithField mocks base method.
+func (m *Mocklog) WithField(key string, value interface{}) logger.Logger {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithField", key, value)
+	ret0, _ := ret[0].(logger.Logger)
+	return ret0
+}
+
+// WithField indicates an expected call of WithField.
+func (mr *MocklogMockRecorder) WithField(key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithField", reflect.TypeOf((*Mocklog)(nil).WithField), key, value)
+}
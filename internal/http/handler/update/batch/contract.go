@@ -12,6 +12,7 @@ import (
 type repo interface {
 	SetGauges(ctx context.Context, gauges []storage.Gauge) error
 	AddCounters(ctx context.Context, counters []storage.Counter) error
+	AddHistograms(ctx context.Context, histograms []storage.Histogram) error
 }
 
 type backup interface {
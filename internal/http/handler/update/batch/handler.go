@@ -31,13 +31,17 @@ func NewHandler(repo repo, backup backup, log log) *Handler {
 //
 //	@Summary	Обновить набор метрик.
 //	@Router		/updates/ [post]
+//	@Security	BearerAuth
 //	@Accept		json
-//	@Param		HashSHA256	header	string				false	"Подпись запроса (если включена проверка подписи)"
-//	@Param		value		body	[]model.UpdateIn	true	"Request body"
-//	@Success	200			"Метрики обновлены"
-//	@Failure	400			"Некорректный запрос"
-//	@Failure	404			"Метрика не найдена"
-//	@Failure	500			"Ошибка"
+//	@Param		HashSHA256			header	string				false	"Подпись запроса (если включена проверка подписи)"
+//	@Param		X-Sign-Timestamp	header	string				false	"Время подписи в секундах Unix (если включена защита от повторов)"
+//	@Param		X-Sign-Nonce		header	string				false	"Одноразовое значение запроса (если включена защита от повторов)"
+//	@Param		X-Key-ID			header	string				false	"Идентификатор ключа агента (если агенту выдан свой ключ)"
+//	@Param		value				body	[]model.UpdateIn	true	"Request body"
+//	@Success	200					"Метрики обновлены"
+//	@Failure	400					"Некорректный запрос"
+//	@Failure	404					"Метрика не найдена"
+//	@Failure	500					"Ошибка"
 func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 	var err error
 	var buf bytes.Buffer
@@ -56,7 +60,8 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 
 	err = json.Unmarshal(buf.Bytes(), &in)
 	if err != nil {
-		if errors.Is(err, model.ErrInvalidID) || errors.Is(err, model.ErrInvalidType) || errors.Is(err, model.ErrInvalidValue) {
+		if errors.Is(err, model.ErrInvalidID) || errors.Is(err, model.ErrInvalidType) || errors.Is(err, model.ErrInvalidValue) ||
+			errors.Is(err, model.ErrInvalidLabels) {
 			h.log.Info(err.Error())
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 			return
@@ -83,6 +88,7 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) saveMetric(ctx context.Context, in []model.UpdateIn) error {
 	gauges := make([]storage.Gauge, 0, len(in))
 	counters := make([]storage.Counter, 0, len(in))
+	histograms := make([]storage.Histogram, 0)
 
 	for _, u := range in {
 		switch u.MType {
@@ -93,8 +99,9 @@ func (h *Handler) saveMetric(ctx context.Context, in []model.UpdateIn) error {
 			}
 
 			gauges = append(gauges, storage.Gauge{
-				ID:    u.ID,
-				Value: *u.Value,
+				ID:     u.ID,
+				Labels: u.Labels,
+				Value:  *u.Value,
 			})
 		case model.TypeCounter:
 			if u.Delta == nil {
@@ -103,8 +110,20 @@ func (h *Handler) saveMetric(ctx context.Context, in []model.UpdateIn) error {
 			}
 
 			counters = append(counters, storage.Counter{
-				ID:    u.ID,
-				Value: *u.Delta,
+				ID:     u.ID,
+				Labels: u.Labels,
+				Value:  *u.Delta,
+			})
+		case model.TypeHistogram:
+			if u.Histogram == nil {
+				h.log.Info("Invalid value")
+				continue
+			}
+
+			histograms = append(histograms, storage.Histogram{
+				ID:     u.ID,
+				Labels: u.Labels,
+				Value:  *u.Histogram,
 			})
 		}
 	}
@@ -121,5 +140,11 @@ func (h *Handler) saveMetric(ctx context.Context, in []model.UpdateIn) error {
 		return err
 	}
 
+	err = h.repo.AddHistograms(ctx, histograms)
+	if err != nil {
+		h.log.WithError(err).Error("Failed to save histograms")
+		return err
+	}
+
 	return nil
 }
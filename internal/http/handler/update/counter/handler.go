@@ -3,6 +3,8 @@ package counter
 import (
 	"net/http"
 	"strconv"
+
+	"github.com/bjlag/go-metrics/internal/model"
 )
 
 // Handler обработчик HTTP запроса на обновление метрики типа Counter.
@@ -25,12 +27,17 @@ func NewHandler(repo repo, backup backup, log log) *Handler {
 //
 //	@Summary	Обновление метрики типа Counter.
 //	@Router		/update/counter/{name}/{value} [post]
-//	@Param		name	path	string	true	"Название метрики"	example(PollCount)
-//	@Param		value	path	string	true	"Значение метрики"	example(1)
-//	@Success	200		"Метрику обновили"
-//	@Failure	400		"Некорректный запрос"
-//	@Failure	404		"Метрика не найдена"
-//	@Failure	500		"Ошибка"
+//	@Security	BearerAuth
+//	@Param		HashSHA256			header	string	false	"Подпись запроса (если включена проверка подписи)"
+//	@Param		X-Sign-Timestamp	header	string	false	"Время подписи в секундах Unix (если включена защита от повторов)"
+//	@Param		X-Sign-Nonce		header	string	false	"Одноразовое значение запроса (если включена защита от повторов)"
+//	@Param		X-Key-ID			header	string	false	"Идентификатор ключа агента (если агенту выдан свой ключ)"
+//	@Param		name				path	string	true	"Название метрики"	example(PollCount)
+//	@Param		value				path	string	true	"Значение метрики"	example(1)
+//	@Success	200					"Метрику обновили"
+//	@Failure	400					"Некорректный запрос"
+//	@Failure	404					"Метрика не найдена"
+//	@Failure	500					"Ошибка"
 func (h Handler) Handle(w http.ResponseWriter, r *http.Request) {
 	nameMetric := r.PathValue("name")
 	valueMetric := r.PathValue("value")
@@ -41,6 +48,12 @@ func (h Handler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !model.IsValidID(nameMetric) {
+		h.log.WithField("name", nameMetric).Info("Invalid metric name")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
 	value, err := strconv.ParseInt(valueMetric, 10, 64)
 	if err != nil {
 		h.log.WithField("error", err.Error()).
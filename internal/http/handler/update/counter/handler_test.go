@@ -57,6 +57,33 @@ func Test_Handle(t *testing.T) {
 				statusCode: http.StatusOK,
 			},
 		},
+		{
+			name: "error invalid name",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().AddCounter(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+				return mockStorage
+			},
+			backup: func(ctrl *gomock.Controller) *mock.Mockbackup {
+				mockBackup := mock.NewMockbackup(ctrl)
+				mockBackup.EXPECT().Create(context.Background()).Times(0)
+				return mockBackup
+			},
+			log: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLog := mock.NewMockLogger(ctrl)
+				mockLog.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(mockLog)
+				mockLog.EXPECT().Info(gomock.Any()).AnyTimes()
+				return mockLog
+			},
+			fields: fields{
+				name:  "test{a}",
+				value: "1",
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
 		{
 			name: "error empty name",
 			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
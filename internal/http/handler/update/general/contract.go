@@ -6,6 +6,7 @@ import (
 	"context"
 
 	"github.com/bjlag/go-metrics/internal/logger"
+	"github.com/bjlag/go-metrics/internal/model"
 	"github.com/bjlag/go-metrics/internal/storage"
 )
 
@@ -14,6 +15,8 @@ type repo interface {
 	AddCounter(ctx context.Context, name string, value int64)
 	GetGauge(ctx context.Context, name string) (float64, error)
 	GetCounter(ctx context.Context, name string) (int64, error)
+	AddHistograms(ctx context.Context, histograms []storage.Histogram) error
+	GetHistogram(ctx context.Context, name string) (model.Histogram, error)
 	GetAllGauges(ctx context.Context) storage.Gauges
 	GetAllCounters(ctx context.Context) storage.Counters
 }
@@ -9,9 +9,10 @@ import (
 	"net/http"
 
 	"github.com/bjlag/go-metrics/internal/model"
+	"github.com/bjlag/go-metrics/internal/storage"
 )
 
-// Handler обработчик HTTP запроса на обновление метрик обоих типов Counter и Gauge.
+// Handler обработчик HTTP запроса на обновление метрик типов Counter, Gauge и Histogram.
 type Handler struct {
 	repo   repo
 	backup backup
@@ -31,13 +32,18 @@ func NewHandler(repo repo, backup backup, log log) *Handler {
 //
 //	@Summary	Обновить метрику.
 //	@Router		/update/ [post]
+//	@Security	BearerAuth
 //	@Accept		json
 //	@Produce	json
-//	@Param		value	body		model.UpdateIn	true	"Request body"
-//	@Success	200		{object}	model.UpdateOut
-//	@Failure	400		"Некорректный запрос"
-//	@Failure	404		"Метрика не найдена"
-//	@Failure	500		"Ошибка"
+//	@Param		HashSHA256			header		string			false	"Подпись запроса (если включена проверка подписи)"
+//	@Param		X-Sign-Timestamp	header		string			false	"Время подписи в секундах Unix (если включена защита от повторов)"
+//	@Param		X-Sign-Nonce		header		string			false	"Одноразовое значение запроса (если включена защита от повторов)"
+//	@Param		X-Key-ID			header		string			false	"Идентификатор ключа агента (если агенту выдан свой ключ)"
+//	@Param		value				body		model.UpdateIn	true	"Request body"
+//	@Success	200					{object}	model.UpdateOut
+//	@Failure	400					"Некорректный запрос"
+//	@Failure	404					"Метрика не найдена"
+//	@Failure	500					"Ошибка"
 func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 	var err error
 	var buf bytes.Buffer
@@ -57,7 +63,8 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 
 	err = json.Unmarshal(buf.Bytes(), &in)
 	if err != nil {
-		if errors.Is(err, model.ErrInvalidID) || errors.Is(err, model.ErrInvalidType) || errors.Is(err, model.ErrInvalidValue) {
+		if errors.Is(err, model.ErrInvalidID) || errors.Is(err, model.ErrInvalidType) || errors.Is(err, model.ErrInvalidValue) ||
+			errors.Is(err, model.ErrInvalidLabels) {
 			h.log.Info(err.Error())
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusNotFound)
 			return
@@ -102,9 +109,15 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) saveMetric(ctx context.Context, in model.UpdateIn) error {
 	switch in.MType {
 	case model.TypeCounter:
-		h.repo.AddCounter(ctx, in.ID, *in.Delta)
+		h.repo.AddCounter(ctx, in.SeriesKey(), *in.Delta)
 	case model.TypeGauge:
-		h.repo.SetGauge(ctx, in.ID, *in.Value)
+		h.repo.SetGauge(ctx, in.SeriesKey(), *in.Value)
+	case model.TypeHistogram:
+		return h.repo.AddHistograms(ctx, []storage.Histogram{{
+			ID:     in.ID,
+			Labels: in.Labels,
+			Value:  *in.Histogram,
+		}})
 	default:
 		return fmt.Errorf("unknown metric type: %s", in.MType)
 	}
@@ -114,12 +127,13 @@ func (h *Handler) saveMetric(ctx context.Context, in model.UpdateIn) error {
 
 func (h *Handler) getResponseData(ctx context.Context, request model.UpdateIn) ([]byte, error) {
 	out := &model.UpdateOut{
-		ID:    request.ID,
-		MType: request.MType,
+		ID:     request.ID,
+		MType:  request.MType,
+		Labels: request.Labels,
 	}
 
 	if request.IsGauge() {
-		value, err := h.repo.GetGauge(ctx, request.ID)
+		value, err := h.repo.GetGauge(ctx, request.SeriesKey())
 		if err != nil {
 			return nil, err
 		}
@@ -127,13 +141,21 @@ func (h *Handler) getResponseData(ctx context.Context, request model.UpdateIn) (
 	}
 
 	if request.IsCounter() {
-		value, err := h.repo.GetCounter(ctx, request.ID)
+		value, err := h.repo.GetCounter(ctx, request.SeriesKey())
 		if err != nil {
 			return nil, err
 		}
 		out.Delta = &value
 	}
 
+	if request.IsHistogram() {
+		value, err := h.repo.GetHistogram(ctx, request.SeriesKey())
+		if err != nil {
+			return nil, err
+		}
+		out.Histogram = &value
+	}
+
 	data, err := json.Marshal(out)
 	if err != nil {
 		return nil, err
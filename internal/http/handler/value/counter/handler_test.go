@@ -0,0 +1,102 @@
+package counter_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	handler "github.com/bjlag/go-metrics/internal/http/handler/value/counter"
+	"github.com/bjlag/go-metrics/internal/http/handler/value/counter/mock"
+	mockLogger "github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+func TestHandler_Handle(t *testing.T) {
+	tests := []struct {
+		name       string
+		metric     string
+		storage    func(ctrl *gomock.Controller) *mock.Mockrepo
+		statusCode int
+		body       string
+	}{
+		{
+			name:   "success",
+			metric: "Alloc",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().GetCounter(gomock.Any(), "Alloc").Return(int64(5), nil)
+				return mockStorage
+			},
+			statusCode: http.StatusOK,
+			body:       "5",
+		},
+		{
+			name:   "not found",
+			metric: "Alloc",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().GetCounter(gomock.Any(), "Alloc").Return(int64(0), storage.NewMetricNotFoundError("counter", "Alloc", nil))
+				return mockStorage
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:   "invalid name",
+			metric: "Alloc{host}",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().GetCounter(gomock.Any(), gomock.Any()).Times(0)
+				return mockStorage
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:   "storage error",
+			metric: "Alloc",
+			storage: func(ctrl *gomock.Controller) *mock.Mockrepo {
+				mockStorage := mock.NewMockrepo(ctrl)
+				mockStorage.EXPECT().GetCounter(gomock.Any(), "Alloc").Return(int64(0), errors.New("error"))
+				return mockStorage
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			mockLog := mockLogger.NewMockLogger(ctrl)
+			mockLog.EXPECT().WithField(gomock.Any(), gomock.Any()).Return(mockLog).AnyTimes()
+			mockLog.EXPECT().Info(gomock.Any()).AnyTimes()
+			mockLog.EXPECT().Error(gomock.Any()).AnyTimes()
+
+			w := httptest.NewRecorder()
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.SetPathValue("name", tt.metric)
+
+			h := http.HandlerFunc(handler.NewHandler(tt.storage(ctrl), mockLog).Handle)
+			h.ServeHTTP(w, request)
+
+			response := w.Result()
+			defer func() {
+				_ = response.Body.Close()
+			}()
+
+			assert.Equal(t, tt.statusCode, response.StatusCode)
+
+			if tt.body != "" {
+				body, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+				assert.Equal(t, tt.body, string(body))
+			}
+		})
+	}
+}
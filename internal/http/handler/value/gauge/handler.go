@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/bjlag/go-metrics/internal/model"
 	"github.com/bjlag/go-metrics/internal/storage"
 )
 
@@ -26,6 +27,7 @@ func NewHandler(repo repo, log log) *Handler {
 //
 //	@Summary	Получить значение метрики типа Gauge.
 //	@Router		/value/gauge/{name} [get]
+//	@Security	BearerAuth
 //	@Produce	text/plain
 //	@Param		name	path		string	true	"Название метрики" example(Sys)
 //	@Success	200		{string}	string	"Значение метрики"
@@ -35,6 +37,12 @@ func NewHandler(repo repo, log log) *Handler {
 func (h Handler) Handle(w http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("name")
 
+	if !model.IsValidID(name) {
+		h.log.WithField("name", name).Info("Invalid metric name")
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
 	storeValue, err := h.repo.GetGauge(r.Context(), name)
 	if err != nil {
 		var metricNotFoundError *storage.NotFoundError
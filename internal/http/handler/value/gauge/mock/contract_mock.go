@@ -0,0 +1,127 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	logger "github.com/bjlag/go-metrics/internal/logger"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// Mockrepo is a mock of repo interface.
+type Mockrepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockrepoMockRecorder
+}
+
+// MockrepoMockRecorder is the mock recorder for Mockrepo.
+type MockrepoMockRecorder struct {
+	mock *Mockrepo
+}
+
+// NewMockrepo creates a new mock instance.
+func NewMockrepo(ctrl *gomock.Controller) *Mockrepo {
+	mock := &Mockrepo{ctrl: ctrl}
+	mock.recorder = &MockrepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockrepo) EXPECT() *MockrepoMockRecorder {
+	return m.recorder
+}
+
+// GetCounter mocks base method.
+func (m *Mockrepo) GetCounter(ctx context.Context, name string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCounter", ctx, name)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCounter indicates an expected call of GetCounter.
+func (mr *MockrepoMockRecorder) GetCounter(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCounter", reflect.TypeOf((*Mockrepo)(nil).GetCounter), ctx, name)
+}
+
+// GetGauge mocks base method.
+func (m *Mockrepo) GetGauge(ctx context.Context, name string) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGauge", ctx, name)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGauge indicates an expected call of GetGauge.
+func (mr *MockrepoMockRecorder) GetGauge(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGauge", reflect.TypeOf((*Mockrepo)(nil).GetGauge), ctx, name)
+}
+
+// Mocklog is a mock of log interface.
+type Mocklog struct {
+	ctrl     *gomock.Controller
+	recorder *MocklogMockRecorder
+}
+
+// MocklogMockRecorder is the mock recorder for Mocklog.
+type MocklogMockRecorder struct {
+	mock *Mocklog
+}
+
+// NewMocklog creates a new mock instance.
+func NewMocklog(ctrl *gomock.Controller) *Mocklog {
+	mock := &Mocklog{ctrl: ctrl}
+	mock.recorder = &MocklogMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mocklog) EXPECT() *MocklogMockRecorder {
+	return m.recorder
+}
+
+// Error mocks base method.
+func (m *Mocklog) Error(msg string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Error", msg)
+}
+
+// Error indicates an expected call of Error.
+func (mr *MocklogMockRecorder) Error(msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*Mocklog)(nil).Error), msg)
+}
+
+// Info mocks base method.
+func (m *Mocklog) Info(msg string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Info", msg)
+}
+
+// Info indicates an expected call of Info.
+func (mr *MocklogMockRecorder) Info(msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*Mocklog)(nil).Info), msg)
+}
+
+// WithField mocks base method.
+func (m *Mocklog) WithField(key string, value interface{}) logger.Logger {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithField", key, value)
+	ret0, _ := ret[0].(logger.Logger)
+	return ret0
+}
+
+// WithField indicates an expected call of WithField.
+func (mr *MocklogMockRecorder) WithField(key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithField", reflect.TypeOf((*Mocklog)(nil).WithField), key, value)
+}
@@ -6,11 +6,13 @@ import (
 	"context"
 
 	"github.com/bjlag/go-metrics/internal/logger"
+	"github.com/bjlag/go-metrics/internal/model"
 )
 
 type repo interface {
 	GetGauge(ctx context.Context, name string) (float64, error)
 	GetCounter(ctx context.Context, name string) (int64, error)
+	GetHistogram(ctx context.Context, name string) (model.Histogram, error)
 }
 
 type log interface {
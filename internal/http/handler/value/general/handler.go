@@ -11,7 +11,7 @@ import (
 	"github.com/bjlag/go-metrics/internal/storage"
 )
 
-// Handler обработчик HTTP запроса на получение значения метрик типа Counter и Gauge.
+// Handler обработчик HTTP запроса на получение значения метрик типа Counter, Gauge и Histogram.
 type Handler struct {
 	repo repo
 	log  log
@@ -29,6 +29,7 @@ func NewHandler(repo repo, log log) *Handler {
 //
 //	@Summary	Получить значение метрики.
 //	@Router		/value/ [post]
+//	@Security	BearerAuth
 //	@Accept		json
 //	@Produce	json
 //	@Param		value	body		model.ValueIn	true	"Request body"
@@ -52,7 +53,7 @@ func (h Handler) Handle(w http.ResponseWriter, r *http.Request) {
 
 	err = json.Unmarshal(buf.Bytes(), &in)
 	if err != nil {
-		if errors.Is(err, model.ErrInvalidID) || errors.Is(err, model.ErrInvalidType) {
+		if errors.Is(err, model.ErrInvalidID) || errors.Is(err, model.ErrInvalidType) || errors.Is(err, model.ErrInvalidLabels) {
 			h.log.Info(err.Error())
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusNotFound)
 			return
@@ -91,12 +92,13 @@ func (h Handler) Handle(w http.ResponseWriter, r *http.Request) {
 
 func (h Handler) getResponseData(ctx context.Context, in model.ValueIn) ([]byte, error) {
 	out := &model.ValueOut{
-		ID:    in.ID,
-		MType: in.MType,
+		ID:     in.ID,
+		MType:  in.MType,
+		Labels: in.Labels,
 	}
 
 	if in.IsGauge() {
-		value, err := h.repo.GetGauge(ctx, in.ID)
+		value, err := h.repo.GetGauge(ctx, in.SeriesKey())
 		if err != nil {
 			return nil, err
 		}
@@ -104,13 +106,21 @@ func (h Handler) getResponseData(ctx context.Context, in model.ValueIn) ([]byte,
 	}
 
 	if in.IsCounter() {
-		value, err := h.repo.GetCounter(ctx, in.ID)
+		value, err := h.repo.GetCounter(ctx, in.SeriesKey())
 		if err != nil {
 			return nil, err
 		}
 		out.Delta = &value
 	}
 
+	if in.IsHistogram() {
+		value, err := h.repo.GetHistogram(ctx, in.SeriesKey())
+		if err != nil {
+			return nil, err
+		}
+		out.Histogram = &value
+	}
+
 	data, err := json.Marshal(out)
 	if err != nil {
 		return nil, err
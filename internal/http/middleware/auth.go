@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bjlag/go-metrics/internal/logger"
+	"github.com/bjlag/go-metrics/internal/securety/auth"
+)
+
+// AuthMiddleware HTTP middleware проверяет токен из заголовка `Authorization: Bearer <token>` и наличие у него роли role.
+// Если токен не передан или неизвестен, возвращает 401, если у токена нет роли — 403.
+func AuthMiddleware(tokens *auth.TokenStore, role auth.Role, logger logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !tokens.Enable() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			identity, err := tokens.Authorize(auth.BearerToken(r.Header.Get("Authorization")), role)
+			if err != nil {
+				if errors.Is(err, auth.ErrForbidden) {
+					logger.WithField("token", identity.Name).WithField("role", string(role)).Info("Token does not have required role")
+					http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+					return
+				}
+
+				logger.Info("Request is not authenticated")
+				w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
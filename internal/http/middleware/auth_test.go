@@ -0,0 +1,121 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/http/middleware"
+	"github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/securety/auth"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	err := os.WriteFile(path, []byte(`{"tokens":[
+		{"name":"agent","token":"ingest-token","roles":["ingest"]},
+		{"name":"ops","token":"admin-token","roles":["admin"]}
+	]}`), 0600)
+	require.NoError(t, err)
+
+	tokens, err := auth.LoadTokenStore(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		logger     func(ctrl *gomock.Controller) *mock.MockLogger
+		tokens     *auth.TokenStore
+		role       auth.Role
+		header     string
+		wantStatus int
+	}{
+		{
+			name:       "auth disabled",
+			logger:     mock.NewMockLogger,
+			role:       auth.RoleAdmin,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "success",
+			logger:     mock.NewMockLogger,
+			tokens:     tokens,
+			role:       auth.RoleIngest,
+			header:     "Bearer ingest-token",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "admin has all roles",
+			logger:     mock.NewMockLogger,
+			tokens:     tokens,
+			role:       auth.RoleRead,
+			header:     "Bearer admin-token",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "no token",
+			logger: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLogger := mock.NewMockLogger(ctrl)
+				mockLogger.EXPECT().Info(gomock.Any())
+				return mockLogger
+			},
+			tokens:     tokens,
+			role:       auth.RoleIngest,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "unknown token",
+			logger: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLogger := mock.NewMockLogger(ctrl)
+				mockLogger.EXPECT().Info(gomock.Any())
+				return mockLogger
+			},
+			tokens:     tokens,
+			role:       auth.RoleIngest,
+			header:     "Bearer unknown",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "forbidden",
+			logger: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLogger := mock.NewMockLogger(ctrl)
+				mockLogger.EXPECT().WithField("token", "agent").Return(mockLogger)
+				mockLogger.EXPECT().WithField("role", "admin").Return(mockLogger)
+				mockLogger.EXPECT().Info(gomock.Any())
+				return mockLogger
+			},
+			tokens:     tokens,
+			role:       auth.RoleAdmin,
+			header:     "Bearer ingest-token",
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			w := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/url", nil)
+			if tt.header != "" {
+				request.Header.Set("Authorization", tt.header)
+			}
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			middleware.AuthMiddleware(tt.tokens, tt.role, tt.logger(ctrl))(handler).ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"net/http"
 
@@ -9,7 +10,12 @@ import (
 	"github.com/bjlag/go-metrics/internal/securety/crypt"
 )
 
-func DecryptMiddleware(crypt *crypt.DecryptManager, logger logger.Logger) func(http.Handler) http.Handler {
+// DecryptMiddleware расшифровывает тело запроса по схеме из заголовка [crypt.HeaderScheme].
+// Запрос без заголовка расшифровывается по устаревшей схеме [crypt.SchemeRSA], так работают агенты предыдущих версий.
+//
+// На некорректный конверт отвечает 400, такой запрос клиент не повторяет. Если данные зашифрованы другим ключом,
+// отвечает 422: после согласования ключей запрос можно повторить.
+func DecryptMiddleware(decrypt *crypt.DecryptManager, logger logger.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			var buf bytes.Buffer
@@ -20,7 +26,17 @@ func DecryptMiddleware(crypt *crypt.DecryptManager, logger logger.Logger) func(h
 				return
 			}
 
-			decryptedBody, err := crypt.Decrypt(buf.Bytes())
+			decryptedBody, err := decrypt.DecryptScheme(r.Header.Get(crypt.HeaderScheme), buf.Bytes())
+			if errors.Is(err, crypt.ErrUnknownScheme) || errors.Is(err, crypt.ErrInvalidEnvelope) {
+				logger.WithError(err).Info("Invalid encrypted body")
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+			if errors.Is(err, crypt.ErrKeyMismatch) {
+				logger.WithError(err).Info("Encrypted with another key")
+				http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+				return
+			}
 			if err != nil {
 				logger.WithError(err).Error("Error decrypting body")
 				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -0,0 +1,133 @@
+package middleware_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/http/middleware"
+	"github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/securety/crypt"
+)
+
+func TestDecryptMiddleware(t *testing.T) {
+	publicKeyPath, privateKeyPath := writeRSAKeys(t)
+
+	encrypt, err := crypt.NewEncryptManager(publicKeyPath)
+	require.NoError(t, err)
+
+	decrypt, err := crypt.NewDecryptManager(privateKeyPath)
+	require.NoError(t, err)
+
+	body := []byte(`[{"id":"Alloc","type":"gauge","value":1}]`)
+
+	tests := []struct {
+		name       string
+		scheme     string
+		body       func(t *testing.T) []byte
+		wantStatus int
+	}{
+		{
+			name:   "envelope",
+			scheme: crypt.SchemeEnvelope,
+			body: func(t *testing.T) []byte {
+				cipherData, err := encrypt.Encrypt(body)
+				require.NoError(t, err)
+				return cipherData
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "corrupted wrapped key",
+			scheme: crypt.SchemeEnvelope,
+			body: func(t *testing.T) []byte {
+				cipherData, err := encrypt.Encrypt(body)
+				require.NoError(t, err)
+				cipherData[0] ^= 0xff
+				return cipherData
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "corrupted data",
+			scheme: crypt.SchemeEnvelope,
+			body: func(t *testing.T) []byte {
+				cipherData, err := encrypt.Encrypt(body)
+				require.NoError(t, err)
+				cipherData[len(cipherData)-1] ^= 0xff
+				return cipherData
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "unknown scheme",
+			scheme: "rot13",
+			body: func(_ *testing.T) []byte {
+				return body
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			log := mock.NewMockLogger(ctrl)
+			log.EXPECT().WithError(gomock.Any()).Return(log).AnyTimes()
+			log.EXPECT().Info(gomock.Any()).AnyTimes()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, body, got)
+			})
+
+			request := httptest.NewRequest(http.MethodPost, "/updates/", bytes.NewReader(tt.body(t)))
+			request.Header.Set(crypt.HeaderScheme, tt.scheme)
+			w := httptest.NewRecorder()
+
+			middleware.DecryptMiddleware(decrypt, log)(next).ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+// writeRSAKeys создает пару ключей RSA и возвращает пути к публичному и приватному ключам.
+func writeRSAKeys(t *testing.T) (string, string) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	publicKeyPath := filepath.Join(dir, "public.pem")
+	privateKeyPath := filepath.Join(dir, "private.pem")
+
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PUBLIC KEY",
+		Bytes: x509.MarshalPKCS1PublicKey(&privateKey.PublicKey),
+	})
+	require.NoError(t, os.WriteFile(publicKeyPath, publicKeyPEM, 0o600))
+
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+	require.NoError(t, os.WriteFile(privateKeyPath, privateKeyPEM, 0o600))
+
+	return publicKeyPath, privateKeyPath
+}
@@ -1,26 +1,28 @@
 package middleware
 
 import (
-	"net"
 	"net/http"
 
 	"github.com/bjlag/go-metrics/internal/logger"
+	"github.com/bjlag/go-metrics/internal/securety/ipfilter"
 )
 
-// CheckRealIPMiddleware HTTP middleware проверяет, что входящий запрос идет из разрешенной подсети.
-func CheckRealIPMiddleware(trustedSubnet *net.IPNet, logger logger.Logger) func(http.Handler) http.Handler {
+// CheckRealIPMiddleware HTTP middleware проверяет, что входящий запрос идет с разрешенного адреса.
+// Адрес клиента определяется по адресу соединения, заголовки X-Forwarded-For и X-Real-IP учитываются
+// только для доверенных прокси, см. [ipfilter.Filter.ClientIP].
+func CheckRealIPMiddleware(filter *ipfilter.Filter, logger logger.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if trustedSubnet != nil {
-				ip := net.ParseIP(r.Header.Get("X-Real-IP"))
+			if filter.Enable() {
+				ip := filter.ClientIP(ipfilter.RemoteIP(r.RemoteAddr), r.Header.Get("X-Forwarded-For"), r.Header.Get("X-Real-IP"))
 				if ip == nil {
-					logger.Error("Request is not contain `X-Real-IP` header. The request is rejected")
+					logger.Error("Failed to determine request IP. The request is rejected")
 					http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 					return
 				}
 
-				if !trustedSubnet.Contains(ip) {
-					logger.WithField("IP", ip.String()).Error("Request IP is not from trusted subnet. The request is rejected")
+				if !filter.Allowed(ip) {
+					logger.WithField("IP", ip.String()).Error("Request IP is not allowed. The request is rejected")
 					http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 					return
 				}
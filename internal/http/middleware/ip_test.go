@@ -8,32 +8,71 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/bjlag/go-metrics/internal/http/middleware"
 	"github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/securety/ipfilter"
 )
 
 func TestCheckRealIPMiddleware(t *testing.T) {
+	parse := func(s string) []*net.IPNet {
+		nets, err := ipfilter.ParseCIDRs(s)
+		require.NoError(t, err)
+		return nets
+	}
+
+	filter := ipfilter.New(
+		ipfilter.WithAllowed(parse("192.168.1.0/24,fd00::/8")),
+		ipfilter.WithDenied(parse("192.168.1.13")),
+		ipfilter.WithTrustedProxies(parse("10.0.0.1")),
+	)
+
 	tests := []struct {
-		name          string
-		logger        func(ctrl *gomock.Controller) *mock.MockLogger
-		realIP        string
-		trustedSubnet string
-		wantStatus    int
+		name         string
+		logger       func(ctrl *gomock.Controller) *mock.MockLogger
+		filter       *ipfilter.Filter
+		remoteAddr   string
+		realIP       string
+		forwardedFor string
+		wantStatus   int
 	}{
 		{
-			name:          "success",
-			logger:        mock.NewMockLogger,
-			realIP:        "192.168.1.1",
-			trustedSubnet: "192.168.1.0/24",
-			wantStatus:    http.StatusOK,
+			name:       "success",
+			logger:     mock.NewMockLogger,
+			filter:     filter,
+			remoteAddr: "192.168.1.1:1234",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "success, IPv6",
+			logger:     mock.NewMockLogger,
+			filter:     filter,
+			remoteAddr: "[fd00::1]:1234",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "filter is disabled",
+			logger:     mock.NewMockLogger,
+			remoteAddr: "192.168.2.1:1234",
+			realIP:     "192.168.2.1",
+			wantStatus: http.StatusOK,
 		},
 		{
-			name:          "trusted subnet is empty",
-			logger:        mock.NewMockLogger,
-			realIP:        "192.168.1.1",
-			trustedSubnet: "",
-			wantStatus:    http.StatusOK,
+			name:         "success, client behind trusted proxy",
+			logger:       mock.NewMockLogger,
+			filter:       filter,
+			remoteAddr:   "10.0.0.1:1234",
+			forwardedFor: "192.168.1.1",
+			wantStatus:   http.StatusOK,
+		},
+		{
+			name:       "success, X-Real-IP from trusted proxy",
+			logger:     mock.NewMockLogger,
+			filter:     filter,
+			remoteAddr: "10.0.0.1:1234",
+			realIP:     "192.168.1.1",
+			wantStatus: http.StatusOK,
 		},
 		{
 			name: "rejected",
@@ -43,20 +82,46 @@ func TestCheckRealIPMiddleware(t *testing.T) {
 				mockLogger.EXPECT().Error(gomock.Any())
 				return mockLogger
 			},
-			realIP:        "192.168.2.1",
-			trustedSubnet: "192.168.1.0/24",
-			wantStatus:    http.StatusForbidden,
+			filter:     filter,
+			remoteAddr: "192.168.2.1:1234",
+			wantStatus: http.StatusForbidden,
 		},
 		{
-			name: "rejected, X-Real-IP is empty",
+			name: "rejected, denied address",
 			logger: func(ctrl *gomock.Controller) *mock.MockLogger {
 				mockLogger := mock.NewMockLogger(ctrl)
+				mockLogger.EXPECT().WithField("IP", "192.168.1.13").Return(mockLogger)
 				mockLogger.EXPECT().Error(gomock.Any())
 				return mockLogger
 			},
-			realIP:        "",
-			trustedSubnet: "192.168.1.0/24",
-			wantStatus:    http.StatusForbidden,
+			filter:     filter,
+			remoteAddr: "192.168.1.13:1234",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "rejected, X-Real-IP from untrusted client is ignored",
+			logger: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLogger := mock.NewMockLogger(ctrl)
+				mockLogger.EXPECT().WithField("IP", "192.168.2.1").Return(mockLogger)
+				mockLogger.EXPECT().Error(gomock.Any())
+				return mockLogger
+			},
+			filter:       filter,
+			remoteAddr:   "192.168.2.1:1234",
+			realIP:       "192.168.1.1",
+			forwardedFor: "192.168.1.1",
+			wantStatus:   http.StatusForbidden,
+		},
+		{
+			name: "rejected, unknown remote address",
+			logger: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLogger := mock.NewMockLogger(ctrl)
+				mockLogger.EXPECT().Error(gomock.Any())
+				return mockLogger
+			},
+			filter:     filter,
+			remoteAddr: "pipe",
+			wantStatus: http.StatusForbidden,
 		},
 	}
 
@@ -66,22 +131,18 @@ func TestCheckRealIPMiddleware(t *testing.T) {
 
 			w := httptest.NewRecorder()
 			request := httptest.NewRequest("POST", "/url", nil)
-			request.Header.Set("X-Real-IP", tt.realIP)
-
-			var (
-				err   error
-				ipNet *net.IPNet
-			)
-
-			if tt.trustedSubnet != "" {
-				_, ipNet, err = net.ParseCIDR(tt.trustedSubnet)
-				assert.NoError(t, err)
+			request.RemoteAddr = tt.remoteAddr
+			if tt.realIP != "" {
+				request.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if tt.forwardedFor != "" {
+				request.Header.Set("X-Forwarded-For", tt.forwardedFor)
 			}
 
-			h := middleware.CheckRealIPMiddleware(ipNet, tt.logger(ctrl))(http.HandlerFunc(handlerHeaderResponse))
+			h := middleware.CheckRealIPMiddleware(tt.filter, tt.logger(ctrl))(http.HandlerFunc(handlerHeaderResponse))
 			h.ServeHTTP(w, request)
 
-			assert.Equal(t, w.Code, tt.wantStatus)
+			assert.Equal(t, tt.wantStatus, w.Code)
 		})
 	}
 }
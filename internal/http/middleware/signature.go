@@ -12,7 +12,24 @@ import (
 
 const headerHash = "HashSHA256"
 
-// SignatureMiddleware HTTP middleware подписывает ответ.
+// Единственный маршрут, на который клиенты предыдущих версий отправляли подписанные запросы.
+const legacySignedPath = "/updates/"
+
+// SignatureMiddleware HTTP middleware проверяет подпись изменяющего запроса и подписывает ответ.
+//
+// Подписываются метод, путь и тело запроса, см. [signature.RequestPayload]. Для POST /updates/ также принимается
+// подпись одного тела, которую отправляют клиенты предыдущих версий. Остальные запросы принимаются только
+// с подписью метода и пути, иначе подписанное тело батча можно было бы отправить на другой маршрут, например /deletes/.
+//
+// Если в заголовках [signature.HeaderTimestamp] и [signature.HeaderNonce] передана метка запроса,
+// она подписывается вместе с телом и проверяется на повтор.
+// Если в заголовке [signature.HeaderKeyID] передан идентификатор ключа агента, подпись проверяется этим ключом.
+//
+// Запрос без подписи отклоняется с кодом 401, с неверной подписью, меткой или повторный запрос — с кодом 403.
+// Код 400 остается за некорректными данными, которые клиент не отправляет повторно.
+//
+// Ответ буферизуется, его тело подписывается тем же ключом, что и запрос, а подпись передается в заголовке HashSHA256
+// до записи тела.
 func SignatureMiddleware(sign *signature.SignManager, logger logger.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
@@ -24,7 +41,7 @@ func SignatureMiddleware(sign *signature.SignManager, logger logger.Logger) func
 			reqSign := r.Header.Get(headerHash)
 			if len(reqSign) == 0 {
 				logger.Info(fmt.Sprintf("No '%s' header", headerHash))
-				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 				return
 			}
 
@@ -40,18 +57,70 @@ func SignatureMiddleware(sign *signature.SignManager, logger logger.Logger) func
 			body := buf.Bytes()
 			r.Body = io.NopCloser(bytes.NewBuffer(body))
 
-			isValid, respSign := sign.Verify(buf.Bytes(), reqSign)
+			stamp, err := signature.ParseStamp(r.Header.Get(signature.HeaderTimestamp), r.Header.Get(signature.HeaderNonce))
+			if err != nil {
+				logger.WithError(err).Info("Invalid signature stamp")
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+
+			keyID := r.Header.Get(signature.HeaderKeyID)
+
+			isValid, _ := sign.VerifyKey(keyID, signature.RequestPayload(r.Method, r.URL.RequestURI(), stamp, body), reqSign)
+			if !isValid && isLegacySigned(r) {
+				isValid, _ = sign.VerifyKey(keyID, stamp.Payload(body), reqSign)
+			}
 			if !isValid {
-				logger.Info("Signature is not correct")
-				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				logger.WithField("key_id", keyID).Info("Signature is not correct")
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+
+			if err = sign.CheckReplay(stamp); err != nil {
+				logger.WithError(err).Info("Replayed or stale request")
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			bw := newBufferedResponseWriter(w)
+			next.ServeHTTP(bw, r)
+
+			if respSign := sign.SignKey(keyID, bw.body.Bytes()); respSign != "" {
+				w.Header().Set(headerHash, respSign)
+			}
 
-			w.Header().Set(headerHash, respSign)
+			w.WriteHeader(bw.status)
+			_, _ = w.Write(bw.body.Bytes())
 		}
 
 		return http.HandlerFunc(fn)
 	}
 }
+
+// Функция isLegacySigned возвращает true для запроса, который клиенты предыдущих версий подписывали без метода и пути.
+func isLegacySigned(r *http.Request) bool {
+	return r.Method == http.MethodPost && r.URL.Path == legacySignedPath
+}
+
+// bufferedResponseWriter накапливает ответ обработчика, чтобы заголовки можно было дополнить после его формирования.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+
+	status int
+	body   bytes.Buffer
+}
+
+func newBufferedResponseWriter(w http.ResponseWriter) *bufferedResponseWriter {
+	return &bufferedResponseWriter{
+		ResponseWriter: w,
+		status:         http.StatusOK,
+	}
+}
+
+func (w *bufferedResponseWriter) Write(buf []byte) (int, error) {
+	return w.body.Write(buf)
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
@@ -0,0 +1,142 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bjlag/go-metrics/internal/http/middleware"
+	"github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/securety/signature"
+)
+
+func TestSignatureMiddleware(t *testing.T) {
+	sign := signature.NewSignManager("secret")
+	stamp := signature.Stamp{Timestamp: 1700000000, Nonce: "abc"}
+
+	tests := []struct {
+		name       string
+		logger     func(ctrl *gomock.Controller) *mock.MockLogger
+		sign       *signature.SignManager
+		target     string
+		body       string
+		hash       string
+		wantStatus int
+	}{
+		{
+			name:       "signing disabled",
+			logger:     mock.NewMockLogger,
+			sign:       signature.NewSignManager(""),
+			target:     "/update/gauge/Alloc/1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "path write signed with method and path",
+			logger:     mock.NewMockLogger,
+			sign:       sign,
+			target:     "/update/gauge/Alloc/1",
+			hash:       sign.Sing(signature.RequestPayload(http.MethodPost, "/update/gauge/Alloc/1", stamp, nil)),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "body write signed with method and path",
+			logger:     mock.NewMockLogger,
+			sign:       sign,
+			target:     "/updates/",
+			body:       `[{"id":"Alloc","type":"gauge","value":1}]`,
+			hash:       sign.Sing(signature.RequestPayload(http.MethodPost, "/updates/", stamp, []byte(`[{"id":"Alloc","type":"gauge","value":1}]`))),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "body write signed with body only",
+			logger:     mock.NewMockLogger,
+			sign:       sign,
+			target:     "/updates/",
+			body:       `[{"id":"Alloc","type":"gauge","value":1}]`,
+			hash:       sign.Sing(stamp.Payload([]byte(`[{"id":"Alloc","type":"gauge","value":1}]`))),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "batch delete signed with body only",
+			logger: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLogger := mock.NewMockLogger(ctrl)
+				mockLogger.EXPECT().WithField("key_id", "").Return(mockLogger)
+				mockLogger.EXPECT().Info(gomock.Any())
+				return mockLogger
+			},
+			sign:       sign,
+			target:     "/deletes/",
+			body:       `[{"id":"Alloc","type":"gauge","value":1}]`,
+			hash:       sign.Sing(stamp.Payload([]byte(`[{"id":"Alloc","type":"gauge","value":1}]`))),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "no signature",
+			logger: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLogger := mock.NewMockLogger(ctrl)
+				mockLogger.EXPECT().Info(gomock.Any())
+				return mockLogger
+			},
+			sign:       sign,
+			target:     "/update/gauge/Alloc/1",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "path write signed for another path",
+			logger: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLogger := mock.NewMockLogger(ctrl)
+				mockLogger.EXPECT().WithField("key_id", "").Return(mockLogger)
+				mockLogger.EXPECT().Info(gomock.Any())
+				return mockLogger
+			},
+			sign:       sign,
+			target:     "/update/gauge/Alloc/100",
+			hash:       sign.Sing(signature.RequestPayload(http.MethodPost, "/update/gauge/Alloc/1", stamp, nil)),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "path write signed with empty body only",
+			logger: func(ctrl *gomock.Controller) *mock.MockLogger {
+				mockLogger := mock.NewMockLogger(ctrl)
+				mockLogger.EXPECT().WithField("key_id", "").Return(mockLogger)
+				mockLogger.EXPECT().Info(gomock.Any())
+				return mockLogger
+			},
+			sign:       sign,
+			target:     "/update/gauge/Alloc/1",
+			hash:       sign.Sing(stamp.Payload(nil)),
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			w := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			request.Header.Set(signature.HeaderTimestamp, stamp.TimestampString())
+			request.Header.Set(signature.HeaderNonce, stamp.Nonce)
+			if tt.hash != "" {
+				request.Header.Set("HashSHA256", tt.hash)
+			}
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte("ok"))
+			})
+
+			middleware.SignatureMiddleware(tt.sign, tt.logger(ctrl))(handler).ServeHTTP(w, request)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK && tt.sign.Enable() {
+				assert.Equal(t, "ok", w.Body.String())
+				assert.Equal(t, tt.sign.Sing([]byte("ok")), w.Header().Get("HashSHA256"), "response body must be signed")
+			}
+		})
+	}
+}
@@ -5,4 +5,6 @@ const (
 	TypeGauge = "gauge"
 	// TypeCounter тип метрики Counter
 	TypeCounter = "counter"
+	// TypeHistogram тип метрики Histogram
+	TypeHistogram = "histogram"
 )
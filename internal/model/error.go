@@ -3,10 +3,12 @@ package model
 import "errors"
 
 var (
-	// ErrInvalidID ошибка, если в запросе не указан ID метрики.
-	ErrInvalidID = errors.New("metric ID not specified")
+	// ErrInvalidID ошибка, если в запросе не указан ID метрики или он содержит недопустимые символы.
+	ErrInvalidID = errors.New("metric ID not specified or invalid")
 	// ErrInvalidType ошибка, если в запросе передан невалидный тип метрики.
 	ErrInvalidType = errors.New("metric type is invalid")
 	// ErrInvalidValue ошибка, если в запросе передано невалидное значение.
 	ErrInvalidValue = errors.New("metric value is invalid")
+	// ErrInvalidLabels ошибка, если в запросе переданы метки с недопустимыми названиями.
+	ErrInvalidLabels = errors.New("metric labels are invalid")
 )
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Histogram значение метрики типа histogram.
+//
+// Bounds задает верхние границы бакетов по возрастанию, последний бакет (+Inf) в Bounds не указывается.
+// Counts содержит количество наблюдений в каждом бакете, поэтому его длина на единицу больше длины Bounds.
+// Значения в Counts не накопительные: наблюдение учитывается только в одном бакете.
+type Histogram struct {
+	Bounds []float64 `json:"bounds" example:"0.1,0.5,1"` // Верхние границы бакетов
+	Counts []uint64  `json:"counts" example:"3,5,1,0"`   // Количество наблюдений в бакетах, последний бакет +Inf
+	Sum    float64   `json:"sum" example:"2.7"`          // Сумма наблюдений
+	Count  uint64    `json:"count" example:"9"`          // Количество наблюдений
+}
+
+// Validate проверяет, что границы бакетов конечные и строго возрастают,
+// количество бакетов соответствует границам, а Count равен сумме Counts.
+func (h Histogram) Validate() error {
+	for i, bound := range h.Bounds {
+		if math.IsNaN(bound) || math.IsInf(bound, 0) {
+			return fmt.Errorf("%w: histogram bound must be finite", ErrInvalidValue)
+		}
+
+		if i > 0 && bound <= h.Bounds[i-1] {
+			return fmt.Errorf("%w: histogram bounds must be sorted in ascending order", ErrInvalidValue)
+		}
+	}
+
+	if len(h.Counts) != len(h.Bounds)+1 {
+		return fmt.Errorf("%w: histogram must have len(bounds)+1 counts", ErrInvalidValue)
+	}
+
+	var count uint64
+	for _, c := range h.Counts {
+		count += c
+	}
+
+	if count != h.Count {
+		return fmt.Errorf("%w: histogram count must be equal to sum of counts", ErrInvalidValue)
+	}
+
+	if math.IsNaN(h.Sum) {
+		return fmt.Errorf("%w: histogram sum is NaN", ErrInvalidValue)
+	}
+
+	return nil
+}
+
+// Merge объединяет гистограмму с новыми наблюдениями other.
+// Если границы бакетов совпадают, количества наблюдений и суммы складываются.
+// Если границы различаются, гистограмма заменяется на other: накопленные значения нельзя перенести в новые бакеты.
+func (h Histogram) Merge(other Histogram) Histogram {
+	if !h.sameBounds(other) {
+		return other.Clone()
+	}
+
+	merged := h.Clone()
+	for i, c := range other.Counts {
+		merged.Counts[i] += c
+	}
+	merged.Sum += other.Sum
+	merged.Count += other.Count
+
+	return merged
+}
+
+// Clone возвращает копию гистограммы, не разделяющую с ней память.
+func (h Histogram) Clone() Histogram {
+	return Histogram{
+		Bounds: append([]float64(nil), h.Bounds...),
+		Counts: append([]uint64(nil), h.Counts...),
+		Sum:    h.Sum,
+		Count:  h.Count,
+	}
+}
+
+// String возвращает гистограмму в виде `count=9 sum=2.7 buckets=[0.1:3 0.5:5 1:1 +Inf:0]`.
+func (h Histogram) String() string {
+	var b strings.Builder
+
+	b.WriteString("count=")
+	b.WriteString(strconv.FormatUint(h.Count, 10))
+	b.WriteString(" sum=")
+	b.WriteString(strconv.FormatFloat(h.Sum, 'g', -1, 64))
+	b.WriteString(" buckets=[")
+
+	for i, c := range h.Counts {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+
+		if i < len(h.Bounds) {
+			b.WriteString(strconv.FormatFloat(h.Bounds[i], 'g', -1, 64))
+		} else {
+			b.WriteString("+Inf")
+		}
+		b.WriteByte(':')
+		b.WriteString(strconv.FormatUint(c, 10))
+	}
+
+	b.WriteByte(']')
+
+	return b.String()
+}
+
+func (h Histogram) sameBounds(other Histogram) bool {
+	if len(h.Bounds) != len(other.Bounds) || len(h.Counts) != len(other.Counts) {
+		return false
+	}
+
+	for i := range h.Bounds {
+		if h.Bounds[i] != other.Bounds[i] {
+			return false
+		}
+	}
+
+	return true
+}
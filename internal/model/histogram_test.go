@@ -0,0 +1,122 @@
+package model_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/model"
+)
+
+func TestHistogram_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		histogram model.Histogram
+		wantErr   bool
+	}{
+		{
+			name:      "valid",
+			histogram: model.Histogram{Bounds: []float64{0.1, 0.5}, Counts: []uint64{1, 2, 3}, Sum: 4.2, Count: 6},
+		},
+		{
+			name:      "only +Inf bucket",
+			histogram: model.Histogram{Counts: []uint64{2}, Sum: 3, Count: 2},
+		},
+		{
+			name:      "unsorted bounds",
+			histogram: model.Histogram{Bounds: []float64{0.5, 0.1}, Counts: []uint64{0, 0, 0}},
+			wantErr:   true,
+		},
+		{
+			name:      "duplicate bounds",
+			histogram: model.Histogram{Bounds: []float64{0.5, 0.5}, Counts: []uint64{0, 0, 0}},
+			wantErr:   true,
+		},
+		{
+			name:      "infinite bound",
+			histogram: model.Histogram{Bounds: []float64{math.Inf(1)}, Counts: []uint64{0, 0}},
+			wantErr:   true,
+		},
+		{
+			name:      "counts mismatch bounds",
+			histogram: model.Histogram{Bounds: []float64{0.1}, Counts: []uint64{1}, Count: 1},
+			wantErr:   true,
+		},
+		{
+			name:      "count mismatch counts",
+			histogram: model.Histogram{Bounds: []float64{0.1}, Counts: []uint64{1, 1}, Count: 5},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.histogram.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, model.ErrInvalidValue)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestHistogram_Merge(t *testing.T) {
+	current := model.Histogram{Bounds: []float64{0.1, 0.5}, Counts: []uint64{1, 2, 3}, Sum: 4, Count: 6}
+
+	t.Run("same bounds", func(t *testing.T) {
+		merged := current.Merge(model.Histogram{Bounds: []float64{0.1, 0.5}, Counts: []uint64{1, 0, 1}, Sum: 1, Count: 2})
+
+		assert.Equal(t, model.Histogram{Bounds: []float64{0.1, 0.5}, Counts: []uint64{2, 2, 4}, Sum: 5, Count: 8}, merged)
+		assert.Equal(t, []uint64{1, 2, 3}, current.Counts)
+	})
+
+	t.Run("different bounds", func(t *testing.T) {
+		other := model.Histogram{Bounds: []float64{1}, Counts: []uint64{1, 1}, Sum: 3, Count: 2}
+
+		assert.Equal(t, other, current.Merge(other))
+	})
+}
+
+func TestUpdateIn_UnmarshalJSON_Histogram(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			body: `{"id":"Latency","type":"histogram","histogram":{"bounds":[0.1],"counts":[1,2],"sum":1.5,"count":3}}`,
+		},
+		{
+			name:    "without histogram",
+			body:    `{"id":"Latency","type":"histogram"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid histogram",
+			body:    `{"id":"Latency","type":"histogram","histogram":{"bounds":[0.1],"counts":[1],"sum":1.5,"count":1}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var in model.UpdateIn
+
+			err := json.Unmarshal([]byte(tt.body), &in)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, model.ErrInvalidValue)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, in.IsHistogram())
+			assert.Equal(t, uint64(3), in.Histogram.Count)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// Labels метки метрики. Метки входят в идентичность серии: метрики с одинаковым ID, но разными метками хранятся отдельно.
+type Labels map[string]string
+
+// Validate проверяет, что названия меток соответствуют формату [a-zA-Z_][a-zA-Z0-9_]*.
+func (l Labels) Validate() error {
+	for name := range l {
+		if !isValidLabelName(name) {
+			return ErrInvalidLabels
+		}
+	}
+
+	return nil
+}
+
+// String возвращает метки в каноническом виде: отсортированные по названию пары name="value" через запятую.
+// Для пустого набора меток возвращается пустая строка.
+func (l Labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(l))
+	for name := range l {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(labelValueReplacer.Replace(l[name]))
+		b.WriteByte('"')
+	}
+
+	return b.String()
+}
+
+// IsValidID проверяет, что ID метрики не пустой и не содержит фигурных скобок.
+// Скобки зарезервированы под метки в ключе серии, иначе ID вида Alloc{host="a"} совпал бы с ключом серии с метками.
+func IsValidID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, "{}")
+}
+
+// SeriesKey возвращает ключ серии, под которым метрика хранится в хранилище: ID{name="value",...}.
+// Для метрики без меток ключом является ее ID.
+func SeriesKey(id string, labels Labels) string {
+	if len(labels) == 0 {
+		return id
+	}
+
+	return id + "{" + labels.String() + "}"
+}
+
+// SplitSeriesKey разбирает ключ серии на ID и метки.
+// Если ключ не содержит корректного набора меток, он целиком считается ID метрики.
+func SplitSeriesKey(key string) (string, Labels) {
+	start := strings.IndexByte(key, '{')
+	if start <= 0 || !strings.HasSuffix(key, "}") {
+		return key, nil
+	}
+
+	labels, ok := parseLabels(key[start+1 : len(key)-1])
+	if !ok {
+		return key, nil
+	}
+
+	return key[:start], labels
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// parseLabels разбирает метки в каноническом виде, который возвращает [Labels.String].
+func parseLabels(s string) (Labels, bool) {
+	labels := make(Labels)
+
+	for len(s) > 0 {
+		eq := strings.Index(s, `="`)
+		if eq <= 0 || !isValidLabelName(s[:eq]) {
+			return nil, false
+		}
+		name := s[:eq]
+		s = s[eq+2:]
+
+		var (
+			value  strings.Builder
+			closed bool
+		)
+		for i := 0; i < len(s); i++ {
+			ch := s[i]
+			if ch == '"' {
+				s = s[i+1:]
+				closed = true
+				break
+			}
+
+			if ch == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					ch = '\n'
+				default:
+					ch = s[i]
+				}
+			}
+
+			value.WriteByte(ch)
+		}
+
+		if !closed {
+			return nil, false
+		}
+
+		labels[name] = value.String()
+
+		if len(s) > 0 {
+			if s[0] != ',' {
+				return nil, false
+			}
+			s = s[1:]
+		}
+	}
+
+	return labels, true
+}
+
+func isValidLabelName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, ch := range name {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch == '_':
+		case ch >= '0' && ch <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
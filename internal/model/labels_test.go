@@ -0,0 +1,117 @@
+package model_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bjlag/go-metrics/internal/model"
+)
+
+func TestLabels_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  model.Labels
+		wantErr bool
+	}{
+		{name: "empty", labels: nil},
+		{name: "valid", labels: model.Labels{"host": "alpha", "_env2": "prod"}},
+		{name: "starts with digit", labels: model.Labels{"2host": "alpha"}, wantErr: true},
+		{name: "invalid char", labels: model.Labels{"host-name": "alpha"}, wantErr: true},
+		{name: "empty name", labels: model.Labels{"": "alpha"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.labels.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, model.ErrInvalidLabels)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSeriesKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		labels model.Labels
+		want   string
+	}{
+		{name: "without labels", id: "Alloc", want: "Alloc"},
+		{name: "sorted labels", id: "Alloc", labels: model.Labels{"zone": "b", "host": "alpha"}, want: `Alloc{host="alpha",zone="b"}`},
+		{name: "escaped value", id: "Alloc", labels: model.Labels{"path": "a\"b\\c\nd"}, want: `Alloc{path="a\"b\\c\nd"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := model.SeriesKey(tt.id, tt.labels)
+			assert.Equal(t, tt.want, key)
+
+			id, labels := model.SplitSeriesKey(key)
+			assert.Equal(t, tt.id, id)
+			if len(tt.labels) == 0 {
+				assert.Empty(t, labels)
+			} else {
+				assert.Equal(t, tt.labels, labels)
+			}
+		})
+	}
+}
+
+func TestSplitSeriesKey_Invalid(t *testing.T) {
+	keys := []string{"{}", "Alloc{", "Alloc{host}", `Alloc{host="alpha}`, `Alloc{host="a"zone="b"}`}
+
+	for _, key := range keys {
+		t.Run(key, func(t *testing.T) {
+			id, labels := model.SplitSeriesKey(key)
+			assert.Equal(t, key, id)
+			assert.Nil(t, labels)
+		})
+	}
+}
+
+func TestIsValidID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "valid", id: "Alloc", want: true},
+		{name: "empty", id: ""},
+		{name: "open brace", id: "Alloc{"},
+		{name: "close brace", id: "Alloc}"},
+		{name: "series key", id: `Alloc{host="alpha"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, model.IsValidID(tt.id))
+		})
+	}
+}
+
+func TestSeriesKey_Collision(t *testing.T) {
+	labeled := model.SeriesKey("Alloc", model.Labels{"host": "alpha"})
+	fake := `Alloc{host="alpha"}`
+
+	// Без проверки ID метрика без меток с таким именем попала бы в серию с метками.
+	assert.Equal(t, labeled, model.SeriesKey(fake, nil))
+	assert.False(t, model.IsValidID(fake))
+
+	var update model.UpdateIn
+	err := json.Unmarshal([]byte(`{"id":"Alloc{host=\"alpha\"}","type":"gauge","value":1}`), &update)
+	assert.ErrorIs(t, err, model.ErrInvalidID)
+
+	var value model.ValueIn
+	err = json.Unmarshal([]byte(`{"id":"Alloc{host=\"alpha\"}","type":"gauge"}`), &value)
+	assert.ErrorIs(t, err, model.ErrInvalidID)
+
+	id, labels := model.SplitSeriesKey(labeled)
+	assert.Equal(t, "Alloc", id)
+	assert.Equal(t, model.Labels{"host": "alpha"}, labels)
+}
@@ -0,0 +1,12 @@
+package model
+
+// QueryOut модель описывает ответ на запрос к истории значений метрики.
+type QueryOut struct {
+	Func    string  `json:"func" example:"rate"`    // Функция: rate, increase, min, max или avg
+	ID      string  `json:"id" example:"PollCount"` // Имя метрики
+	MType   string  `json:"type" example:"counter"` // Тип метрики: gauge или counter
+	Labels  Labels  `json:"labels,omitempty"`       // Метки метрики
+	Window  string  `json:"window" example:"5m0s"`  // Окно, за которое вычислено значение
+	Value   float64 `json:"value" example:"0.5"`    // Вычисленное значение
+	Samples int     `json:"samples" example:"30"`   // Количество значений в окне
+}
@@ -7,10 +7,12 @@ import (
 
 // UpdateIn модель описывает входящий запрос на обновление метрики.
 type UpdateIn struct {
-	ID    string   `json:"id" example:"Sys"`              // Имя метрики
-	MType string   `json:"type" example:"gauge"`          // Параметр, принимающий значение gauge или counter
-	Delta *int64   `json:"delta,omitempty" example:"1"`   // Значение метрики в случае передачи counter
-	Value *float64 `json:"value,omitempty" example:"1.1"` // Значение метрики в случае передачи gauge
+	ID        string     `json:"id" example:"Sys"`              // Имя метрики
+	MType     string     `json:"type" example:"gauge"`          // Параметр, принимающий значение gauge, counter или histogram
+	Delta     *int64     `json:"delta,omitempty" example:"1"`   // Значение метрики в случае передачи counter
+	Value     *float64   `json:"value,omitempty" example:"1.1"` // Значение метрики в случае передачи gauge
+	Histogram *Histogram `json:"histogram,omitempty"`           // Значение метрики в случае передачи histogram
+	Labels    Labels     `json:"labels,omitempty"`              // Метки метрики, входят в идентичность серии
 }
 
 // IsValid проверяет валидный ли запрос.
@@ -23,6 +25,10 @@ func (m *UpdateIn) IsValid() bool {
 		return true
 	}
 
+	if m.IsHistogram() && m.Histogram != nil {
+		return true
+	}
+
 	return false
 }
 
@@ -36,6 +42,11 @@ func (m *UpdateIn) IsCounter() bool {
 	return m.MType == TypeCounter
 }
 
+// IsHistogram возвращает true, если запрос с типом метрики [TypeHistogram]
+func (m *UpdateIn) IsHistogram() bool {
+	return m.MType == TypeHistogram
+}
+
 // UnmarshalJSON анмаршалинг запроса в модель [UpdateIn] с валидацией входящих данных.
 func (m *UpdateIn) UnmarshalJSON(b []byte) error {
 	type UpdateInAlias UpdateIn
@@ -51,7 +62,7 @@ func (m *UpdateIn) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	if m.ID == "" {
+	if !IsValidID(m.ID) {
 		return ErrInvalidID
 	}
 
@@ -68,13 +79,32 @@ func (m *UpdateIn) UnmarshalJSON(b []byte) error {
 		errs = append(errs, ErrInvalidValue)
 	}
 
+	if m.IsHistogram() {
+		if m.Histogram == nil {
+			errs = append(errs, ErrInvalidValue)
+		} else if err := m.Histogram.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := m.Labels.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
 	return errors.Join(errs...)
 }
 
+// SeriesKey возвращает ключ серии метрики, см. [SeriesKey].
+func (m *UpdateIn) SeriesKey() string {
+	return SeriesKey(m.ID, m.Labels)
+}
+
 // UpdateOut модель описывает ответ результата обновления метрики.
 type UpdateOut struct {
-	ID    string   `json:"id"`              // Имя метрики
-	MType string   `json:"type"`            // Параметр, принимающий значение gauge или counter
-	Delta *int64   `json:"delta,omitempty"` // Значение метрики в случае передачи counter
-	Value *float64 `json:"value,omitempty"` // Значение метрики в случае передачи gauge
+	ID        string     `json:"id"`                  // Имя метрики
+	MType     string     `json:"type"`                // Параметр, принимающий значение gauge, counter или histogram
+	Delta     *int64     `json:"delta,omitempty"`     // Значение метрики в случае передачи counter
+	Value     *float64   `json:"value,omitempty"`     // Значение метрики в случае передачи gauge
+	Histogram *Histogram `json:"histogram,omitempty"` // Значение метрики в случае передачи histogram
+	Labels    Labels     `json:"labels,omitempty"`    // Метки метрики
 }
@@ -7,8 +7,9 @@ import (
 
 // ValueIn модель описывает входящий запрос на получение значения метрики.
 type ValueIn struct {
-	ID    string `json:"id" example:"Sys"`     // Имя метрики
-	MType string `json:"type" example:"gauge"` // Параметр, принимающий значение gauge или counter
+	ID     string `json:"id" example:"Sys"`     // Имя метрики
+	MType  string `json:"type" example:"gauge"` // Параметр, принимающий значение gauge, counter или histogram
+	Labels Labels `json:"labels,omitempty"`     // Метки метрики, входят в идентичность серии
 }
 
 // IsValid проверяет валидный ли запрос.
@@ -21,6 +22,10 @@ func (m *ValueIn) IsValid() bool {
 		return true
 	}
 
+	if m.IsHistogram() {
+		return true
+	}
+
 	return false
 }
 
@@ -34,6 +39,11 @@ func (m *ValueIn) IsCounter() bool {
 	return m.MType == TypeCounter
 }
 
+// IsHistogram возвращает true, если запрос с типом метрики [TypeHistogram]
+func (m *ValueIn) IsHistogram() bool {
+	return m.MType == TypeHistogram
+}
+
 // UnmarshalJSON анмаршалинг запроса в модель [ValueIn] с валидацией входящих данных.
 func (m *ValueIn) UnmarshalJSON(b []byte) error {
 	type ValueInAlias ValueIn
@@ -50,7 +60,7 @@ func (m *ValueIn) UnmarshalJSON(b []byte) error {
 	}
 
 	var errs []error
-	if m.ID == "" {
+	if !IsValidID(m.ID) {
 		errs = append(errs, ErrInvalidID)
 	}
 
@@ -58,13 +68,24 @@ func (m *ValueIn) UnmarshalJSON(b []byte) error {
 		errs = append(errs, ErrInvalidType)
 	}
 
+	if err := m.Labels.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
 	return errors.Join(errs...)
 }
 
+// SeriesKey возвращает ключ серии метрики, см. [SeriesKey].
+func (m *ValueIn) SeriesKey() string {
+	return SeriesKey(m.ID, m.Labels)
+}
+
 // ValueOut модель описывает ответ результата получения значения метрики.
 type ValueOut struct {
-	ID    string   `json:"id"`              // Имя метрики
-	MType string   `json:"type"`            // Параметр, принимающий значение gauge или counter
-	Delta *int64   `json:"delta,omitempty"` // Значение метрики в случае передачи counter
-	Value *float64 `json:"value,omitempty"` // Значение метрики в случае передачи gauge
+	ID        string     `json:"id"`                  // Имя метрики
+	MType     string     `json:"type"`                // Параметр, принимающий значение gauge, counter или histogram
+	Delta     *int64     `json:"delta,omitempty"`     // Значение метрики в случае передачи counter
+	Value     *float64   `json:"value,omitempty"`     // Значение метрики в случае передачи gauge
+	Histogram *Histogram `json:"histogram,omitempty"` // Значение метрики в случае передачи histogram
+	Labels    Labels     `json:"labels,omitempty"`    // Метки метрики
 }
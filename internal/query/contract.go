@@ -0,0 +1,12 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+type repo interface {
+	GetSamples(ctx context.Context, kind, id string, from, to time.Time) ([]storage.Sample, error)
+}
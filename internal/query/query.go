@@ -0,0 +1,180 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/bjlag/go-metrics/internal/model"
+)
+
+const (
+	// FuncRate средняя скорость роста счетчика в секунду за окно.
+	FuncRate = "rate"
+	// FuncIncrease прирост счетчика за окно.
+	FuncIncrease = "increase"
+	// FuncMin минимальное значение за окно.
+	FuncMin = "min"
+	// FuncMax максимальное значение за окно.
+	FuncMax = "max"
+	// FuncAvg среднее значение за окно.
+	FuncAvg = "avg"
+)
+
+var (
+	// ErrInvalidQuery ошибка, если запрос некорректен.
+	ErrInvalidQuery = errors.New("invalid query")
+	// ErrNoData ошибка, если в окне недостаточно значений для вычисления.
+	ErrNoData = errors.New("not enough samples in window")
+)
+
+// Request запрос к истории значений метрики.
+type Request struct {
+	// Func функция: rate, increase, min, max или avg.
+	Func string
+	// Kind тип метрики: gauge или counter. Функции rate и increase применимы только к counter.
+	Kind string
+	// ID ключ серии метрики, см. [model.SeriesKey].
+	ID string
+	// Window окно, за которое вычисляется значение. Окно заканчивается в момент запроса.
+	Window time.Duration
+}
+
+// Result результат запроса.
+type Result struct {
+	// Value вычисленное значение.
+	Value float64
+	// Samples количество значений в окне, по которым выполнено вычисление.
+	Samples int
+}
+
+// Engine вычисляет функции над историей значений метрик.
+type Engine struct {
+	repo repo
+	now  func() time.Time
+}
+
+// Option настраивает движок запросов.
+type Option func(e *Engine)
+
+// WithClock задает источник текущего времени, используется в тестах.
+func WithClock(now func() time.Time) Option {
+	return func(e *Engine) {
+		e.now = now
+	}
+}
+
+// NewEngine создает движок запросов.
+func NewEngine(repo repo, opts ...Option) *Engine {
+	e := &Engine{
+		repo: repo,
+		now:  time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Query вычисляет функцию запроса за окно, которое заканчивается в текущий момент.
+//
+// Функции rate и increase учитывают сброс счетчика: если значение уменьшилось, считается, что счетчик рос с нуля.
+// Значение rate — прирост, деленный на время между первым и последним значением в окне, без экстраполяции на края окна.
+// Для rate и increase нужно хотя бы два значения в окне, для остальных функций — одно.
+// Если метрики нет, возвращается ошибка [storage.NotFoundError].
+func (e *Engine) Query(ctx context.Context, req Request) (Result, error) {
+	err := req.validate()
+	if err != nil {
+		return Result{}, err
+	}
+
+	to := e.now()
+	samples, err := e.repo.GetSamples(ctx, req.Kind, req.ID, to.Add(-req.Window), to)
+	if err != nil {
+		return Result{}, err
+	}
+
+	minSamples := 1
+	if req.Func == FuncRate || req.Func == FuncIncrease {
+		minSamples = 2
+	}
+
+	if len(samples) < minSamples {
+		return Result{}, ErrNoData
+	}
+
+	result := Result{Samples: len(samples)}
+
+	switch req.Func {
+	case FuncIncrease, FuncRate:
+		var increase float64
+		for i := 1; i < len(samples); i++ {
+			delta := samples[i].Value - samples[i-1].Value
+			if delta < 0 {
+				delta = samples[i].Value
+			}
+			increase += delta
+		}
+
+		result.Value = increase
+
+		if req.Func == FuncRate {
+			elapsed := samples[len(samples)-1].Timestamp.Sub(samples[0].Timestamp).Seconds()
+			if elapsed <= 0 {
+				return Result{}, ErrNoData
+			}
+
+			result.Value = increase / elapsed
+		}
+	case FuncMin:
+		result.Value = math.Inf(1)
+		for _, s := range samples {
+			result.Value = math.Min(result.Value, s.Value)
+		}
+	case FuncMax:
+		result.Value = math.Inf(-1)
+		for _, s := range samples {
+			result.Value = math.Max(result.Value, s.Value)
+		}
+	case FuncAvg:
+		var sum float64
+		for _, s := range samples {
+			sum += s.Value
+		}
+		result.Value = sum / float64(len(samples))
+	}
+
+	return result, nil
+}
+
+func (r Request) validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("%w: %w", ErrInvalidQuery, model.ErrInvalidID)
+	}
+
+	if r.Window <= 0 {
+		return fmt.Errorf("%w: window must be positive", ErrInvalidQuery)
+	}
+
+	switch r.Kind {
+	case model.TypeCounter:
+	case model.TypeGauge:
+		if r.Func == FuncRate || r.Func == FuncIncrease {
+			return fmt.Errorf("%w: %s is applicable only to counters", ErrInvalidQuery, r.Func)
+		}
+	default:
+		return fmt.Errorf("%w: %w", ErrInvalidQuery, model.ErrInvalidType)
+	}
+
+	switch r.Func {
+	case FuncRate, FuncIncrease, FuncMin, FuncMax, FuncAvg:
+	default:
+		return fmt.Errorf("%w: unknown function '%s'", ErrInvalidQuery, r.Func)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package query_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/query"
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+type fakeRepo struct {
+	samples  []storage.Sample
+	from, to time.Time
+}
+
+func (r *fakeRepo) GetSamples(_ context.Context, kind, id string, from, to time.Time) ([]storage.Sample, error) {
+	if id != "PollCount" {
+		return nil, storage.NewMetricNotFoundError(kind, id, nil)
+	}
+
+	r.from, r.to = from, to
+
+	return r.samples, nil
+}
+
+func TestEngine_Query(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time {
+		return now.Add(time.Duration(seconds-60) * time.Second)
+	}
+
+	// Между 20-й и 30-й секундой счетчик сброшен.
+	samples := []storage.Sample{
+		{Timestamp: at(0), Value: 10},
+		{Timestamp: at(10), Value: 15},
+		{Timestamp: at(20), Value: 30},
+		{Timestamp: at(30), Value: 5},
+		{Timestamp: at(40), Value: 10},
+	}
+
+	tests := []struct {
+		name    string
+		req     query.Request
+		samples []storage.Sample
+		want    float64
+		wantErr error
+	}{
+		{
+			name:    "increase",
+			req:     query.Request{Func: query.FuncIncrease, Kind: "counter", ID: "PollCount", Window: time.Minute},
+			samples: samples,
+			want:    30,
+		},
+		{
+			name:    "rate",
+			req:     query.Request{Func: query.FuncRate, Kind: "counter", ID: "PollCount", Window: time.Minute},
+			samples: samples,
+			want:    0.75,
+		},
+		{
+			name:    "min",
+			req:     query.Request{Func: query.FuncMin, Kind: "gauge", ID: "PollCount", Window: time.Minute},
+			samples: samples,
+			want:    5,
+		},
+		{
+			name:    "max",
+			req:     query.Request{Func: query.FuncMax, Kind: "gauge", ID: "PollCount", Window: time.Minute},
+			samples: samples,
+			want:    30,
+		},
+		{
+			name:    "avg",
+			req:     query.Request{Func: query.FuncAvg, Kind: "gauge", ID: "PollCount", Window: time.Minute},
+			samples: samples,
+			want:    14,
+		},
+		{
+			name:    "rate with one sample",
+			req:     query.Request{Func: query.FuncRate, Kind: "counter", ID: "PollCount", Window: time.Minute},
+			samples: samples[:1],
+			wantErr: query.ErrNoData,
+		},
+		{
+			name:    "rate of gauge",
+			req:     query.Request{Func: query.FuncRate, Kind: "gauge", ID: "PollCount", Window: time.Minute},
+			wantErr: query.ErrInvalidQuery,
+		},
+		{
+			name:    "unknown function",
+			req:     query.Request{Func: "sum", Kind: "gauge", ID: "PollCount", Window: time.Minute},
+			wantErr: query.ErrInvalidQuery,
+		},
+		{
+			name:    "without window",
+			req:     query.Request{Func: query.FuncAvg, Kind: "gauge", ID: "PollCount"},
+			wantErr: query.ErrInvalidQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{samples: tt.samples}
+			engine := query.NewEngine(repo, query.WithClock(func() time.Time {
+				return now
+			}))
+
+			result, err := engine.Query(context.Background(), tt.req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, result.Value, 1e-9)
+			assert.Equal(t, len(tt.samples), result.Samples)
+			assert.Equal(t, now.Add(-tt.req.Window), repo.from)
+			assert.Equal(t, now, repo.to)
+		})
+	}
+}
+
+func TestEngine_Query_NotFound(t *testing.T) {
+	engine := query.NewEngine(&fakeRepo{})
+
+	_, err := engine.Query(context.Background(), query.Request{Func: query.FuncAvg, Kind: "gauge", ID: "Unknown", Window: time.Minute})
+
+	var notFoundErr *storage.NotFoundError
+	assert.ErrorAs(t, err, &notFoundErr)
+}
@@ -0,0 +1,22 @@
+package deletes
+
+import (
+	"context"
+
+	"github.com/bjlag/go-metrics/internal/logger"
+)
+
+type repo interface {
+	Delete(ctx context.Context, kind, id string) error
+}
+
+type backup interface {
+	Create(ctx context.Context) error
+}
+
+type log interface {
+	WithField(key string, value interface{}) logger.Logger
+	WithError(err error) logger.Logger
+	Error(msg string)
+	Info(msg string)
+}
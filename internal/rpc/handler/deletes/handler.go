@@ -0,0 +1,75 @@
+package deletes
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bjlag/go-metrics/internal/generated/rpc"
+	"github.com/bjlag/go-metrics/internal/model"
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+type Handler struct {
+	repo   repo
+	backup backup
+	log    log
+}
+
+func NewHandler(repo repo, backup backup, log log) *Handler {
+	return &Handler{
+		repo:   repo,
+		backup: backup,
+		log:    log,
+	}
+}
+
+// Delete удаляет перечисленные метрики. Метрики, которых нет в хранилище, пропускаются.
+func (h *Handler) Delete(ctx context.Context, in *rpc.DeleteIn) (*rpc.DeleteOut, error) {
+	for _, m := range in.Metrics {
+		if !model.IsValidID(m.Id) {
+			return nil, status.Error(codes.InvalidArgument, model.ErrInvalidID.Error())
+		}
+
+		if m.Type != model.TypeGauge && m.Type != model.TypeCounter && m.Type != model.TypeHistogram {
+			return nil, status.Error(codes.InvalidArgument, model.ErrInvalidType.Error())
+		}
+
+		if err := model.Labels(m.Labels).Validate(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	var deleted int64
+
+	for _, m := range in.Metrics {
+		key := model.SeriesKey(m.Id, m.Labels)
+
+		err := h.repo.Delete(ctx, m.Type, key)
+		if err != nil {
+			var metricNotFoundError *storage.NotFoundError
+			if errors.As(err, &metricNotFoundError) {
+				h.log.WithField("type", m.Type).
+					WithField("id", key).
+					Info("Metric not found")
+				continue
+			}
+
+			h.log.WithError(err).Error("Failed to delete metric")
+			return nil, status.Error(codes.Internal, "failed to delete metric")
+		}
+
+		deleted++
+	}
+
+	if deleted > 0 {
+		err := h.backup.Create(ctx)
+		if err != nil {
+			h.log.WithError(err).Error("Failed to backup data")
+		}
+	}
+
+	return &rpc.DeleteOut{Deleted: deleted}, nil
+}
@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/bjlag/go-metrics/internal/logger"
+	"github.com/bjlag/go-metrics/internal/model"
+	"github.com/bjlag/go-metrics/internal/storage"
+	"github.com/bjlag/go-metrics/internal/storage/watch"
+)
+
+type repo interface {
+	GetAllGauges(ctx context.Context) storage.Gauges
+	GetAllCounters(ctx context.Context) storage.Counters
+	GetAllHistograms(ctx context.Context) storage.Histograms
+	GetGauge(ctx context.Context, id string) (float64, error)
+	GetCounter(ctx context.Context, id string) (int64, error)
+	GetHistogram(ctx context.Context, id string) (model.Histogram, error)
+}
+
+type hub interface {
+	Subscribe(buffer int) (<-chan watch.Event, func())
+}
+
+type log interface {
+	WithField(key string, value interface{}) logger.Logger
+	WithError(err error) logger.Logger
+	Error(msg string)
+	Info(msg string)
+}
@@ -0,0 +1,290 @@
+package metrics
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bjlag/go-metrics/internal/generated/rpc"
+	"github.com/bjlag/go-metrics/internal/model"
+	"github.com/bjlag/go-metrics/internal/storage"
+	"github.com/bjlag/go-metrics/internal/storage/watch"
+)
+
+const (
+	// DefaultPageSize размер страницы ListMetrics, если он не указан в запросе.
+	DefaultPageSize = 100
+	// MaxPageSize максимальный размер страницы ListMetrics, больший размер уменьшается до него.
+	MaxPageSize = 1000
+
+	// Размер буфера событий подписчика Watch. При его переполнении подписка закрывается.
+	watchBuffer = 256
+	// Разделитель ключа серии и типа метрики в курсоре страницы.
+	cursorSeparator = "\x00"
+)
+
+var errInvalidPageToken = errors.New("invalid page token")
+
+type Handler struct {
+	repo repo
+	hub  hub
+	log  log
+}
+
+func NewHandler(repo repo, hub hub, log log) *Handler {
+	return &Handler{
+		repo: repo,
+		hub:  hub,
+		log:  log,
+	}
+}
+
+// GetValue возвращает текущее значение метрики.
+func (h *Handler) GetValue(ctx context.Context, in *rpc.GetValueIn) (*rpc.GetValueOut, error) {
+	if !model.IsValidID(in.Id) {
+		return nil, status.Error(codes.InvalidArgument, model.ErrInvalidID.Error())
+	}
+
+	if !isKnownType(in.Type) {
+		return nil, status.Error(codes.InvalidArgument, model.ErrInvalidType.Error())
+	}
+
+	if err := model.Labels(in.Labels).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	key := model.SeriesKey(in.Id, in.Labels)
+	event := watch.Event{Kind: in.Type, ID: key}
+
+	var err error
+	switch in.Type {
+	case model.TypeGauge:
+		event.Gauge, err = h.repo.GetGauge(ctx, key)
+	case model.TypeCounter:
+		event.Counter, err = h.repo.GetCounter(ctx, key)
+	case model.TypeHistogram:
+		event.Histogram, err = h.repo.GetHistogram(ctx, key)
+	}
+
+	if err != nil {
+		var metricNotFoundError *storage.NotFoundError
+		if errors.As(err, &metricNotFoundError) {
+			return nil, status.Error(codes.NotFound, "metric not found")
+		}
+
+		h.log.WithError(err).Error("Failed to get metric")
+		return nil, status.Error(codes.Internal, "failed to get metric")
+	}
+
+	return &rpc.GetValueOut{Metric: toMetric(event)}, nil
+}
+
+// ListMetrics возвращает страницу метрик, ID которых начинается с prefix.
+// Метрики упорядочены по ключу серии и типу. Для получения следующей страницы в запросе передается
+// next_page_token из ответа, пустой next_page_token означает последнюю страницу.
+func (h *Handler) ListMetrics(ctx context.Context, in *rpc.ListMetricsIn) (*rpc.ListMetricsOut, error) {
+	pageSize := int(in.PageSize)
+	switch {
+	case pageSize < 0:
+		return nil, status.Error(codes.InvalidArgument, "page size must not be negative")
+	case pageSize == 0:
+		pageSize = DefaultPageSize
+	case pageSize > MaxPageSize:
+		pageSize = MaxPageSize
+	}
+
+	var after *cursor
+	if in.PageToken != "" {
+		c, err := decodeCursor(in.PageToken)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		after = &c
+	}
+
+	events := h.collect(ctx, in.Prefix)
+	sort.Slice(events, func(i, j int) bool {
+		return cursorOf(events[i]).less(cursorOf(events[j]))
+	})
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(events), func(i int) bool {
+			return after.less(cursorOf(events[i]))
+		})
+	}
+
+	end := start + pageSize
+	if end > len(events) {
+		end = len(events)
+	}
+
+	out := &rpc.ListMetricsOut{
+		Metrics: make([]*rpc.Metric, 0, end-start),
+	}
+
+	for _, event := range events[start:end] {
+		out.Metrics = append(out.Metrics, toMetric(event))
+	}
+
+	if end < len(events) {
+		out.NextPageToken = cursorOf(events[end-1]).encode()
+	}
+
+	return out, nil
+}
+
+// Watch отправляет в поток изменения метрик по мере их записи.
+// Фильтр prefix применяется к ID метрики, types ограничивает типы метрик, пустой список означает все типы.
+// Если клиент не успевает читать события, поток завершается с кодом ResourceExhausted.
+func (h *Handler) Watch(in *rpc.WatchIn, stream rpc.MetricService_WatchServer) error {
+	types := make(map[string]struct{}, len(in.Types))
+	for _, t := range in.Types {
+		if !isKnownType(t) {
+			return status.Error(codes.InvalidArgument, model.ErrInvalidType.Error())
+		}
+		types[t] = struct{}{}
+	}
+
+	events, unsubscribe := h.hub.Subscribe(watchBuffer)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				h.log.Info("Watch subscriber is too slow, subscription closed")
+				return status.Error(codes.ResourceExhausted, "subscriber is too slow")
+			}
+
+			if _, ok := types[event.Kind]; len(types) > 0 && !ok {
+				continue
+			}
+
+			if id, _ := model.SplitSeriesKey(event.ID); !strings.HasPrefix(id, in.Prefix) {
+				continue
+			}
+
+			err := stream.Send(&rpc.MetricEvent{
+				Metric:  toMetric(event),
+				Deleted: event.Deleted,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Метод collect возвращает все метрики, ID которых начинается с prefix.
+func (h *Handler) collect(ctx context.Context, prefix string) []watch.Event {
+	var events []watch.Event
+
+	match := func(key string) bool {
+		id, _ := model.SplitSeriesKey(key)
+		return strings.HasPrefix(id, prefix)
+	}
+
+	for key, value := range h.repo.GetAllGauges(ctx) {
+		if match(key) {
+			events = append(events, watch.Event{Kind: model.TypeGauge, ID: key, Gauge: value})
+		}
+	}
+
+	for key, value := range h.repo.GetAllCounters(ctx) {
+		if match(key) {
+			events = append(events, watch.Event{Kind: model.TypeCounter, ID: key, Counter: value})
+		}
+	}
+
+	for key, value := range h.repo.GetAllHistograms(ctx) {
+		if match(key) {
+			events = append(events, watch.Event{Kind: model.TypeHistogram, ID: key, Histogram: value})
+		}
+	}
+
+	return events
+}
+
+// Функция toMetric преобразует значение метрики в сообщение gRPC. Для удаленной метрики значение не заполняется.
+func toMetric(event watch.Event) *rpc.Metric {
+	id, labels := model.SplitSeriesKey(event.ID)
+
+	m := &rpc.Metric{
+		Id:     id,
+		Type:   event.Kind,
+		Labels: labels,
+	}
+
+	if event.Deleted {
+		return m
+	}
+
+	switch event.Kind {
+	case model.TypeGauge:
+		m.Value = &event.Gauge
+	case model.TypeCounter:
+		m.Delta = &event.Counter
+	case model.TypeHistogram:
+		m.Histogram = &rpc.Histogram{
+			Bounds: event.Histogram.Bounds,
+			Counts: event.Histogram.Counts,
+			Sum:    event.Histogram.Sum,
+			Count:  event.Histogram.Count,
+		}
+	}
+
+	return m
+}
+
+func isKnownType(kind string) bool {
+	return kind == model.TypeGauge || kind == model.TypeCounter || kind == model.TypeHistogram
+}
+
+// cursor позиция метрики в упорядоченном списке ListMetrics.
+type cursor struct {
+	key  string
+	kind string
+}
+
+func cursorOf(event watch.Event) cursor {
+	return cursor{key: event.ID, kind: event.Kind}
+}
+
+func (c cursor) less(other cursor) bool {
+	if c.key != other.key {
+		return c.key < other.key
+	}
+
+	return c.kind < other.kind
+}
+
+func (c cursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.key + cursorSeparator + c.kind))
+}
+
+func decodeCursor(token string) (cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor{}, errInvalidPageToken
+	}
+
+	i := strings.LastIndex(string(raw), cursorSeparator)
+	if i <= 0 {
+		return cursor{}, errInvalidPageToken
+	}
+
+	c := cursor{key: string(raw[:i]), kind: string(raw[i+len(cursorSeparator):])}
+	if !isKnownType(c.kind) {
+		return cursor{}, errInvalidPageToken
+	}
+
+	return c, nil
+}
@@ -0,0 +1,88 @@
+package metrics_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bjlag/go-metrics/internal/generated/rpc"
+	mockLogger "github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/model"
+	"github.com/bjlag/go-metrics/internal/rpc/handler/metrics"
+	"github.com/bjlag/go-metrics/internal/storage"
+	"github.com/bjlag/go-metrics/internal/storage/memory"
+	"github.com/bjlag/go-metrics/internal/storage/watch"
+)
+
+func TestHandler_ListMetrics(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	log := mockLogger.NewMockLogger(ctrl)
+
+	repo := memory.NewStorage()
+	require.NoError(t, repo.SetGauges(ctx, []storage.Gauge{
+		{ID: "Alloc", Value: 1},
+		{ID: "HeapAlloc", Value: 2},
+		{ID: "HeapInuse", Value: 3},
+		{ID: "HeapInuse", Labels: model.Labels{"host": "a"}, Value: 4},
+	}))
+	require.NoError(t, repo.AddCounters(ctx, []storage.Counter{
+		{ID: "HeapInuse", Value: 5},
+	}))
+
+	h := metrics.NewHandler(repo, watch.NewHub(), log)
+
+	var got []string
+	in := &rpc.ListMetricsIn{Prefix: "Heap", PageSize: 2}
+	for {
+		out, err := h.ListMetrics(ctx, in)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(out.Metrics), 2)
+
+		for _, m := range out.Metrics {
+			got = append(got, m.Type+":"+model.SeriesKey(m.Id, m.Labels))
+		}
+
+		if out.NextPageToken == "" {
+			break
+		}
+		in.PageToken = out.NextPageToken
+	}
+
+	assert.Equal(t, []string{
+		"gauge:HeapAlloc",
+		"counter:HeapInuse",
+		"gauge:HeapInuse",
+		`gauge:HeapInuse{host="a"}`,
+	}, got)
+
+	_, err := h.ListMetrics(ctx, &rpc.ListMetricsIn{PageToken: "invalid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestHandler_GetValue(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	log := mockLogger.NewMockLogger(ctrl)
+
+	repo := memory.NewStorage()
+	repo.SetGauge(ctx, `Alloc{host="a"}`, 1.5)
+
+	h := metrics.NewHandler(repo, watch.NewHub(), log)
+
+	out, err := h.GetValue(ctx, &rpc.GetValueIn{Id: "Alloc", Type: model.TypeGauge, Labels: map[string]string{"host": "a"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, out.Metric.GetValue())
+	assert.Equal(t, map[string]string{"host": "a"}, out.Metric.Labels)
+
+	_, err = h.GetValue(ctx, &rpc.GetValueIn{Id: "Alloc", Type: model.TypeGauge})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = h.GetValue(ctx, &rpc.GetValueIn{Id: "Alloc", Type: "unknown"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
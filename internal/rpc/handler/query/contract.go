@@ -0,0 +1,19 @@
+package query
+
+import (
+	"context"
+
+	"github.com/bjlag/go-metrics/internal/logger"
+	"github.com/bjlag/go-metrics/internal/query"
+)
+
+type engine interface {
+	Query(ctx context.Context, req query.Request) (query.Result, error)
+}
+
+type log interface {
+	WithField(key string, value interface{}) logger.Logger
+	WithError(err error) logger.Logger
+	Error(msg string)
+	Info(msg string)
+}
@@ -0,0 +1,65 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bjlag/go-metrics/internal/generated/rpc"
+	"github.com/bjlag/go-metrics/internal/model"
+	"github.com/bjlag/go-metrics/internal/query"
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+type Handler struct {
+	engine engine
+	log    log
+}
+
+func NewHandler(engine engine, log log) *Handler {
+	return &Handler{
+		engine: engine,
+		log:    log,
+	}
+}
+
+// Query вычисляет функцию по истории значений метрики за окно.
+func (h *Handler) Query(ctx context.Context, in *rpc.QueryIn) (*rpc.QueryOut, error) {
+	if err := model.Labels(in.Labels).Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	req := query.Request{
+		Func:   in.Func,
+		Kind:   in.Type,
+		ID:     in.Id,
+		Window: time.Duration(in.WindowSeconds) * time.Second,
+	}
+
+	if req.ID != "" {
+		req.ID = model.SeriesKey(req.ID, in.Labels)
+	}
+
+	result, err := h.engine.Query(ctx, req)
+	if err != nil {
+		var metricNotFoundError *storage.NotFoundError
+
+		switch {
+		case errors.Is(err, query.ErrInvalidQuery):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, query.ErrNoData), errors.As(err, &metricNotFoundError):
+			return nil, status.Error(codes.NotFound, err.Error())
+		default:
+			h.log.WithError(err).Error("Failed to execute query")
+			return nil, status.Error(codes.Internal, "failed to execute query")
+		}
+	}
+
+	return &rpc.QueryOut{
+		Value:   result.Value,
+		Samples: int64(result.Samples),
+	}, nil
+}
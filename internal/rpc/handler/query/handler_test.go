@@ -0,0 +1,122 @@
+package query_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bjlag/go-metrics/internal/generated/rpc"
+	mockLogger "github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/query"
+	handler "github.com/bjlag/go-metrics/internal/rpc/handler/query"
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+type fakeEngine struct {
+	req    *query.Request
+	result query.Result
+	err    error
+}
+
+func (e *fakeEngine) Query(_ context.Context, req query.Request) (query.Result, error) {
+	e.req = &req
+	return e.result, e.err
+}
+
+func TestHandler_Query(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       *rpc.QueryIn
+		result   query.Result
+		err      error
+		wantReq  *query.Request
+		wantOut  *rpc.QueryOut
+		wantCode codes.Code
+		wantLog  bool
+	}{
+		{
+			name:    "rate with labels",
+			in:      &rpc.QueryIn{Func: query.FuncRate, Type: "counter", Id: "Requests", WindowSeconds: 300, Labels: map[string]string{"path": "/", "code": "200"}},
+			result:  query.Result{Value: 1.5, Samples: 10},
+			wantReq: &query.Request{Func: query.FuncRate, Kind: "counter", ID: `Requests{code="200",path="/"}`, Window: 5 * time.Minute},
+			wantOut: &rpc.QueryOut{Value: 1.5, Samples: 10},
+		},
+		{
+			name:    "without labels and window",
+			in:      &rpc.QueryIn{Func: query.FuncMax, Type: "gauge", Id: "Alloc"},
+			result:  query.Result{Value: 7, Samples: 1},
+			wantReq: &query.Request{Func: query.FuncMax, Kind: "gauge", ID: "Alloc"},
+			wantOut: &rpc.QueryOut{Value: 7, Samples: 1},
+		},
+		{
+			name:     "invalid labels",
+			in:       &rpc.QueryIn{Func: query.FuncMax, Type: "gauge", Id: "Alloc", Labels: map[string]string{"host-name": "a"}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid query",
+			in:       &rpc.QueryIn{Func: "median", Type: "gauge", Id: "Alloc", WindowSeconds: 60},
+			err:      fmt.Errorf("%w: unknown function", query.ErrInvalidQuery),
+			wantReq:  &query.Request{Func: "median", Kind: "gauge", ID: "Alloc", Window: time.Minute},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "no data",
+			in:       &rpc.QueryIn{Func: query.FuncRate, Type: "counter", Id: "Requests", WindowSeconds: 60},
+			err:      query.ErrNoData,
+			wantReq:  &query.Request{Func: query.FuncRate, Kind: "counter", ID: "Requests", Window: time.Minute},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "metric not found",
+			in:       &rpc.QueryIn{Func: query.FuncRate, Type: "counter", Id: "Requests", WindowSeconds: 60},
+			err:      storage.NewMetricNotFoundError("counter", "Requests", nil),
+			wantReq:  &query.Request{Func: query.FuncRate, Kind: "counter", ID: "Requests", Window: time.Minute},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "internal error",
+			in:       &rpc.QueryIn{Func: query.FuncRate, Type: "counter", Id: "Requests", WindowSeconds: 60},
+			err:      errors.New("connection refused"),
+			wantReq:  &query.Request{Func: query.FuncRate, Kind: "counter", ID: "Requests", Window: time.Minute},
+			wantCode: codes.Internal,
+			wantLog:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			log := mockLogger.NewMockLogger(ctrl)
+			if tt.wantLog {
+				log.EXPECT().WithError(gomock.Any()).Return(log)
+				log.EXPECT().Error(gomock.Any())
+			}
+
+			engine := &fakeEngine{result: tt.result, err: tt.err}
+			h := handler.NewHandler(engine, log)
+
+			out, err := h.Query(context.Background(), tt.in)
+			assert.Equal(t, tt.wantReq, engine.req)
+
+			if tt.wantCode != codes.OK {
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				assert.Nil(t, out)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOut.Value, out.Value)
+			assert.Equal(t, tt.wantOut.Samples, out.Samples)
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 type repo interface {
 	SetGauges(ctx context.Context, gauges []storage.Gauge) error
 	AddCounters(ctx context.Context, counters []storage.Counter) error
+	AddHistograms(ctx context.Context, histograms []storage.Histogram) error
 }
 
 type backup interface {
@@ -17,6 +18,7 @@ type backup interface {
 }
 
 type log interface {
+	WithField(key string, value interface{}) logger.Logger
 	WithError(err error) logger.Logger
 	Error(msg string)
 	Info(msg string)
@@ -2,6 +2,8 @@ package updates
 
 import (
 	"context"
+	"errors"
+	"io"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -30,10 +32,64 @@ func (h *Handler) Updates(ctx context.Context, in *rpc.UpdatesIn) (*rpc.UpdatesO
 		return nil, nil
 	}
 
-	gauges := make([]storage.Gauge, 0, len(in.Metrics))
-	counters := make([]storage.Counter, 0, len(in.Metrics))
+	err := h.save(ctx, in.Metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rpc.UpdatesOut{}, nil
+}
+
+// StreamUpdates принимает батчи метрик из потока и сохраняет каждый из них так же, как [Handler.Updates].
+// После сохранения каждого батча клиенту отправляется подтверждение,
+// итоговое подтверждение отправляется после закрытия потока клиентом.
+func (h *Handler) StreamUpdates(stream rpc.MetricService_StreamUpdatesServer) error {
+	ack := &rpc.StreamAck{}
+
+	for {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.Send(ack)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(in.Metrics) > 0 {
+			err = h.save(stream.Context(), in.Metrics)
+			if err != nil {
+				return err
+			}
+		}
+
+		ack.Batches++
+		ack.Metrics += int64(len(in.Metrics))
+
+		err = stream.Send(ack)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Метод save сохраняет метрики и создает резервную копию. Возвращает ошибку со статусом gRPC.
+func (h *Handler) save(ctx context.Context, metrics []*rpc.Metric) error {
+	gauges := make([]storage.Gauge, 0, len(metrics))
+	counters := make([]storage.Counter, 0, len(metrics))
+	histograms := make([]storage.Histogram, 0)
+
+	for _, m := range metrics {
+		if !model.IsValidID(m.Id) {
+			h.log.WithField("id", m.Id).Info("Invalid ID")
+			continue
+		}
+
+		labels := model.Labels(m.Labels)
+		if err := labels.Validate(); err != nil {
+			h.log.WithField("id", m.Id).Info("Invalid labels")
+			continue
+		}
 
-	for _, m := range in.Metrics {
 		switch m.Type {
 		case model.TypeGauge:
 			if m.Value == nil {
@@ -42,8 +98,9 @@ func (h *Handler) Updates(ctx context.Context, in *rpc.UpdatesIn) (*rpc.UpdatesO
 			}
 
 			gauges = append(gauges, storage.Gauge{
-				ID:    m.Id,
-				Value: *m.Value,
+				ID:     m.Id,
+				Labels: labels,
+				Value:  *m.Value,
 			})
 		case model.TypeCounter:
 			if m.Delta == nil {
@@ -52,8 +109,31 @@ func (h *Handler) Updates(ctx context.Context, in *rpc.UpdatesIn) (*rpc.UpdatesO
 			}
 
 			counters = append(counters, storage.Counter{
-				ID:    m.Id,
-				Value: *m.Delta,
+				ID:     m.Id,
+				Labels: labels,
+				Value:  *m.Delta,
+			})
+		case model.TypeHistogram:
+			if m.Histogram == nil {
+				h.log.Info("Invalid value")
+				continue
+			}
+
+			value := model.Histogram{
+				Bounds: m.Histogram.Bounds,
+				Counts: m.Histogram.Counts,
+				Sum:    m.Histogram.Sum,
+				Count:  m.Histogram.Count,
+			}
+			if err := value.Validate(); err != nil {
+				h.log.WithField("id", m.Id).WithError(err).Info("Invalid histogram")
+				continue
+			}
+
+			histograms = append(histograms, storage.Histogram{
+				ID:     m.Id,
+				Labels: labels,
+				Value:  value,
 			})
 		}
 	}
@@ -61,13 +141,19 @@ func (h *Handler) Updates(ctx context.Context, in *rpc.UpdatesIn) (*rpc.UpdatesO
 	err := h.repo.SetGauges(ctx, gauges)
 	if err != nil {
 		h.log.WithError(err).Error("Failed to save gauges")
-		return nil, status.Error(codes.Internal, "failed to save gauges")
+		return status.Error(codes.Internal, "failed to save gauges")
 	}
 
 	err = h.repo.AddCounters(ctx, counters)
 	if err != nil {
 		h.log.WithError(err).Error("Failed to save counters")
-		return nil, status.Error(codes.Internal, "failed to save counters")
+		return status.Error(codes.Internal, "failed to save counters")
+	}
+
+	err = h.repo.AddHistograms(ctx, histograms)
+	if err != nil {
+		h.log.WithError(err).Error("Failed to save histograms")
+		return status.Error(codes.Internal, "failed to save histograms")
 	}
 
 	err = h.backup.Create(ctx)
@@ -75,5 +161,5 @@ func (h *Handler) Updates(ctx context.Context, in *rpc.UpdatesIn) (*rpc.UpdatesO
 		h.log.WithError(err).Error("Failed to backup data")
 	}
 
-	return &rpc.UpdatesOut{}, nil
+	return nil
 }
@@ -0,0 +1,86 @@
+package updates_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/bjlag/go-metrics/internal/generated/rpc"
+	mockLogger "github.com/bjlag/go-metrics/internal/mock"
+	"github.com/bjlag/go-metrics/internal/rpc/handler/updates"
+	"github.com/bjlag/go-metrics/internal/storage/memory"
+)
+
+type fakeBackup struct {
+	calls int
+}
+
+func (b *fakeBackup) Create(_ context.Context) error {
+	b.calls++
+	return nil
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+
+	in   []*rpc.UpdatesIn
+	acks []*rpc.StreamAck
+}
+
+func (s *fakeStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *fakeStream) Recv() (*rpc.UpdatesIn, error) {
+	if len(s.in) == 0 {
+		return nil, io.EOF
+	}
+
+	in := s.in[0]
+	s.in = s.in[1:]
+
+	return in, nil
+}
+
+func (s *fakeStream) Send(ack *rpc.StreamAck) error {
+	s.acks = append(s.acks, &rpc.StreamAck{Batches: ack.Batches, Metrics: ack.Metrics})
+	return nil
+}
+
+func TestHandler_StreamUpdates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	log := mockLogger.NewMockLogger(ctrl)
+
+	delta := int64(1)
+	stream := &fakeStream{}
+	const batches = 3
+	for i := 0; i < batches; i++ {
+		stream.in = append(stream.in, &rpc.UpdatesIn{Metrics: []*rpc.Metric{
+			{Id: "PollCount", Type: "counter", Delta: &delta},
+		}})
+	}
+
+	repo := memory.NewStorage()
+	backup := &fakeBackup{}
+
+	err := updates.NewHandler(repo, backup, log).StreamUpdates(stream)
+	require.NoError(t, err)
+
+	// подтверждение на каждый батч и итоговое после закрытия потока
+	require.Len(t, stream.acks, batches+1)
+	for i := 0; i < batches; i++ {
+		assert.Equal(t, int64(i+1), stream.acks[i].Batches)
+	}
+	assert.Equal(t, int64(batches), stream.acks[batches].Batches)
+	assert.Equal(t, int64(batches), stream.acks[batches].Metrics)
+
+	value, err := repo.GetCounter(context.Background(), "PollCount")
+	require.NoError(t, err)
+	assert.Equal(t, int64(batches), value)
+	assert.Equal(t, batches, backup.calls)
+}
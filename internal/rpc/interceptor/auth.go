@@ -0,0 +1,107 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/bjlag/go-metrics/internal/generated/rpc"
+	"github.com/bjlag/go-metrics/internal/securety/auth"
+)
+
+// AuthorizationMeta ключ метаданных с токеном доступа в виде "Bearer <token>".
+const AuthorizationMeta = "authorization"
+
+// methodRoles роли, необходимые для вызова методов сервиса. Для методов не из списка нужна роль admin.
+var methodRoles = map[string]auth.Role{
+	rpc.MetricService_Updates_FullMethodName:       auth.RoleIngest,
+	rpc.MetricService_StreamUpdates_FullMethodName: auth.RoleIngest,
+	rpc.MetricService_GetValue_FullMethodName:      auth.RoleRead,
+	rpc.MetricService_ListMetrics_FullMethodName:   auth.RoleRead,
+	rpc.MetricService_Watch_FullMethodName:         auth.RoleRead,
+	rpc.MetricService_Query_FullMethodName:         auth.RoleRead,
+	rpc.MetricService_Delete_FullMethodName:        auth.RoleAdmin,
+}
+
+// TokenClientInterceptor добавляет токен доступа в метаданные запроса. Пустой токен не передается.
+func TokenClientInterceptor(token string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withToken(ctx, token), method, req, reply, cc, opts...)
+	}
+}
+
+// TokenStreamClientInterceptor добавляет токен доступа в метаданные потока.
+func TokenStreamClientInterceptor(token string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withToken(ctx, token), desc, cc, method, opts...)
+	}
+}
+
+// AuthServerInterceptor проверяет токен доступа и наличие у него роли, необходимой для вызова метода.
+// Возвращает Unauthenticated, если токен не передан или неизвестен, и PermissionDenied, если роли нет.
+func AuthServerInterceptor(tokens *auth.TokenStore) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authorize(ctx, tokens, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamServerInterceptor проверяет токен доступа при открытии потока.
+func AuthStreamServerInterceptor(tokens *auth.TokenStore) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), tokens, info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func withToken(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		md = metadata.New(nil)
+	}
+
+	md.Set(AuthorizationMeta, "Bearer "+token)
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+func authorize(ctx context.Context, tokens *auth.TokenStore, method string) error {
+	if !tokens.Enable() {
+		return nil
+	}
+
+	role, ok := methodRoles[method]
+	if !ok {
+		role = auth.RoleAdmin
+	}
+
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		token = auth.BearerToken(firstValue(md, AuthorizationMeta))
+	}
+
+	_, err := tokens.Authorize(token, role)
+	if err != nil {
+		if errors.Is(err, auth.ErrForbidden) {
+			return status.Errorf(codes.PermissionDenied, "role '%s' is required", role)
+		}
+
+		return status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package interceptor_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/bjlag/go-metrics/internal/generated/rpc"
+	"github.com/bjlag/go-metrics/internal/rpc/interceptor"
+	"github.com/bjlag/go-metrics/internal/securety/auth"
+)
+
+func TestAuthInterceptors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	err := os.WriteFile(path, []byte(`{"tokens":[
+		{"name":"agent","token":"ingest-token","roles":["ingest"]},
+		{"name":"grafana","token":"read-token","roles":["read"]}
+	]}`), 0600)
+	require.NoError(t, err)
+
+	tokens, err := auth.LoadTokenStore(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		tokens   *auth.TokenStore
+		token    string
+		method   string
+		wantCode codes.Code
+	}{
+		{
+			name:     "auth disabled",
+			method:   rpc.MetricService_Delete_FullMethodName,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "ingest",
+			tokens:   tokens,
+			token:    "ingest-token",
+			method:   rpc.MetricService_Updates_FullMethodName,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "read",
+			tokens:   tokens,
+			token:    "read-token",
+			method:   rpc.MetricService_Query_FullMethodName,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "no token",
+			tokens:   tokens,
+			method:   rpc.MetricService_Updates_FullMethodName,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "unknown token",
+			tokens:   tokens,
+			token:    "unknown",
+			method:   rpc.MetricService_Updates_FullMethodName,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "read token can not ingest",
+			tokens:   tokens,
+			token:    "read-token",
+			method:   rpc.MetricService_Updates_FullMethodName,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "delete requires admin",
+			tokens:   tokens,
+			token:    "ingest-token",
+			method:   rpc.MetricService_Delete_FullMethodName,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "unknown method requires admin",
+			tokens:   tokens,
+			token:    "read-token",
+			method:   "/metric.MetricService/Unknown",
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Клиентский интерцептор кладет токен в метаданные, серверный получает их через входящий контекст.
+			var sentCtx context.Context
+			invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				sentCtx = ctx
+				return nil
+			}
+
+			err := interceptor.TokenClientInterceptor(tt.token)(context.Background(), tt.method, nil, nil, nil, invoker)
+			require.NoError(t, err)
+
+			md, _ := metadata.FromOutgoingContext(sentCtx)
+			serverCtx := metadata.NewIncomingContext(context.Background(), md)
+
+			handler := func(_ context.Context, _ any) (any, error) {
+				return &rpc.UpdatesOut{}, nil
+			}
+
+			_, err = interceptor.AuthServerInterceptor(tt.tokens)(serverCtx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
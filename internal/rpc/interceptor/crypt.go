@@ -0,0 +1,183 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bjlag/go-metrics/internal/generated/rpc"
+	"github.com/bjlag/go-metrics/internal/securety/crypt"
+)
+
+// EncryptionSchemeMeta ключ метаданных, в котором клиент сообщает схему шифрования батчей.
+const EncryptionSchemeMeta = "encryption-scheme"
+
+// EncryptClientInterceptor шифрует батч метрик Updates. Зашифрованный батч передается в поле encrypted.
+func EncryptClientInterceptor(encrypt *crypt.EncryptManager) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		in, ok := req.(*rpc.UpdatesIn)
+		if !ok || !encrypt.Enable() {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		encrypted, err := encryptUpdates(encrypt, in)
+		if err != nil {
+			return err
+		}
+
+		return invoker(withEncryptionScheme(ctx, encrypt.Scheme()), method, encrypted, reply, cc, opts...)
+	}
+}
+
+// EncryptStreamClientInterceptor шифрует каждый батч потока StreamUpdates.
+func EncryptStreamClientInterceptor(encrypt *crypt.EncryptManager) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if !encrypt.Enable() {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		stream, err := streamer(withEncryptionScheme(ctx, encrypt.Scheme()), desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return &encryptClientStream{ClientStream: stream, encrypt: encrypt}, nil
+	}
+}
+
+// DecryptServerInterceptor расшифровывает батч метрик Updates, если он пришел в поле encrypted.
+// Незашифрованные батчи пропускаются без изменений, так работают агенты предыдущих версий.
+func DecryptServerInterceptor(decrypt *crypt.DecryptManager) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if in, ok := req.(*rpc.UpdatesIn); ok {
+			if err := decryptUpdates(ctx, decrypt, in); err != nil {
+				return nil, err
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// DecryptStreamServerInterceptor расшифровывает каждый батч потока StreamUpdates.
+func DecryptStreamServerInterceptor(decrypt *crypt.DecryptManager) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &decryptServerStream{ServerStream: ss, decrypt: decrypt})
+	}
+}
+
+type encryptClientStream struct {
+	grpc.ClientStream
+	encrypt *crypt.EncryptManager
+}
+
+func (s *encryptClientStream) SendMsg(m any) error {
+	in, ok := m.(*rpc.UpdatesIn)
+	if !ok {
+		return s.ClientStream.SendMsg(m)
+	}
+
+	encrypted, err := encryptUpdates(s.encrypt, in)
+	if err != nil {
+		return err
+	}
+
+	return s.ClientStream.SendMsg(encrypted)
+}
+
+type decryptServerStream struct {
+	grpc.ServerStream
+	decrypt *crypt.DecryptManager
+}
+
+func (s *decryptServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err != nil {
+		return err
+	}
+
+	in, ok := m.(*rpc.UpdatesIn)
+	if !ok {
+		return nil
+	}
+
+	return decryptUpdates(s.Context(), s.decrypt, in)
+}
+
+// Функция encryptUpdates возвращает новый батч, в котором метрики переданы в зашифрованном виде.
+// Исходный батч не изменяется.
+func encryptUpdates(encrypt *crypt.EncryptManager, in *rpc.UpdatesIn) (*rpc.UpdatesIn, error) {
+	data, err := proto.Marshal(&rpc.UpdatesIn{Metrics: in.Metrics})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal request: %s", err.Error())
+	}
+
+	encrypted, err := encrypt.Encrypt(data)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encrypt request: %s", err.Error())
+	}
+
+	return &rpc.UpdatesIn{
+		Signature: in.Signature,
+		Timestamp: in.Timestamp,
+		Nonce:     in.Nonce,
+		Encrypted: encrypted,
+	}, nil
+}
+
+// Функция decryptUpdates расшифровывает метрики батча на месте.
+func decryptUpdates(ctx context.Context, decrypt *crypt.DecryptManager, in *rpc.UpdatesIn) error {
+	if len(in.Encrypted) == 0 {
+		return nil
+	}
+
+	if !decrypt.Enable() {
+		return status.Error(codes.FailedPrecondition, "encryption is not configured")
+	}
+
+	var scheme string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(EncryptionSchemeMeta); len(values) > 0 {
+			scheme = values[0]
+		}
+	}
+
+	if scheme != crypt.SchemeEnvelope {
+		return status.Errorf(codes.InvalidArgument, "unsupported encryption scheme: %q", scheme)
+	}
+
+	data, err := decrypt.DecryptEnvelope(in.Encrypted)
+	if errors.Is(err, crypt.ErrKeyMismatch) {
+		// Ключи агента и сервера разошлись, батч можно отправить повторно после их согласования.
+		return status.Error(codes.FailedPrecondition, "request is encrypted with another key")
+	}
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "failed to decrypt request")
+	}
+
+	var decrypted rpc.UpdatesIn
+	if err = proto.Unmarshal(data, &decrypted); err != nil {
+		return status.Error(codes.InvalidArgument, "failed to unmarshal decrypted request")
+	}
+
+	in.Metrics = decrypted.Metrics
+	in.Encrypted = nil
+
+	return nil
+}
+
+func withEncryptionScheme(ctx context.Context, scheme string) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		md = metadata.New(nil)
+	}
+
+	md.Set(EncryptionSchemeMeta, scheme)
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
@@ -0,0 +1,159 @@
+package interceptor_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bjlag/go-metrics/internal/generated/rpc"
+	"github.com/bjlag/go-metrics/internal/rpc/interceptor"
+	"github.com/bjlag/go-metrics/internal/securety/crypt"
+)
+
+func TestEncryptDecryptInterceptors(t *testing.T) {
+	encrypt, decrypt := newCryptManagers(t)
+
+	value := 1.5
+	in := &rpc.UpdatesIn{Metrics: []*rpc.Metric{{Id: "Alloc", Type: "gauge", Value: &value}}}
+
+	// Клиент отправляет зашифрованный батч, сервер получает его через метаданные входящего контекста.
+	var (
+		sentCtx context.Context
+		sentReq *rpc.UpdatesIn
+	)
+	invoker := func(ctx context.Context, _ string, req, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		sentCtx, sentReq = ctx, req.(*rpc.UpdatesIn)
+		return nil
+	}
+
+	err := interceptor.EncryptClientInterceptor(encrypt)(context.Background(), "/metric.MetricService/Updates", in, nil, nil, invoker)
+	require.NoError(t, err)
+
+	assert.Empty(t, sentReq.Metrics)
+	assert.NotEmpty(t, sentReq.Encrypted)
+	assert.Len(t, in.Metrics, 1, "original request must not be modified")
+
+	md, _ := metadata.FromOutgoingContext(sentCtx)
+	serverCtx := metadata.NewIncomingContext(context.Background(), md)
+
+	var received *rpc.UpdatesIn
+	handler := func(_ context.Context, req any) (any, error) {
+		received = req.(*rpc.UpdatesIn)
+		return &rpc.UpdatesOut{}, nil
+	}
+
+	_, err = interceptor.DecryptServerInterceptor(decrypt)(serverCtx, sentReq, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+
+	assert.Empty(t, received.Encrypted)
+	assert.True(t, proto.Equal(in, received))
+}
+
+func TestDecryptServerInterceptor(t *testing.T) {
+	encrypt, decrypt := newCryptManagers(t)
+
+	encrypted, err := encrypt.Encrypt([]byte("not a proto message"))
+	require.NoError(t, err)
+
+	anotherKey := bytes.Clone(encrypted)
+	anotherKey[0] ^= 0xff
+
+	withScheme := metadata.NewIncomingContext(context.Background(), metadata.Pairs(interceptor.EncryptionSchemeMeta, crypt.SchemeEnvelope))
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		decrypt  *crypt.DecryptManager
+		in       *rpc.UpdatesIn
+		wantCode codes.Code
+	}{
+		{
+			name:     "plain request of old agent",
+			ctx:      context.Background(),
+			decrypt:  decrypt,
+			in:       &rpc.UpdatesIn{Metrics: []*rpc.Metric{{Id: "Alloc"}}},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "without scheme",
+			ctx:      context.Background(),
+			decrypt:  decrypt,
+			in:       &rpc.UpdatesIn{Encrypted: encrypted},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid payload",
+			ctx:      withScheme,
+			decrypt:  decrypt,
+			in:       &rpc.UpdatesIn{Encrypted: []byte("garbage")},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "encrypted with another key",
+			ctx:      withScheme,
+			decrypt:  decrypt,
+			in:       &rpc.UpdatesIn{Encrypted: anotherKey},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "encryption is not configured",
+			ctx:      withScheme,
+			decrypt:  &crypt.DecryptManager{},
+			in:       &rpc.UpdatesIn{Encrypted: encrypted},
+			wantCode: codes.FailedPrecondition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := func(_ context.Context, _ any) (any, error) {
+				return &rpc.UpdatesOut{}, nil
+			}
+
+			_, err := interceptor.DecryptServerInterceptor(tt.decrypt)(tt.ctx, tt.in, &grpc.UnaryServerInfo{}, handler)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func newCryptManagers(t *testing.T) (*crypt.EncryptManager, *crypt.DecryptManager) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	publicKeyPath := filepath.Join(dir, "public.pem")
+	privateKeyPath := filepath.Join(dir, "private.pem")
+
+	require.NoError(t, os.WriteFile(publicKeyPath, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PUBLIC KEY",
+		Bytes: x509.MarshalPKCS1PublicKey(&privateKey.PublicKey),
+	}), 0o600))
+	require.NoError(t, os.WriteFile(privateKeyPath, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	}), 0o600))
+
+	encrypt, err := crypt.NewEncryptManager(publicKeyPath)
+	require.NoError(t, err)
+
+	decrypt, err := crypt.NewDecryptManager(privateKeyPath)
+	require.NoError(t, err)
+
+	return encrypt, decrypt
+}
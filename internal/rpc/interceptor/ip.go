@@ -3,51 +3,97 @@ package interceptor
 import (
 	"context"
 	"net"
+	"strings"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 
-	clientIP "github.com/bjlag/go-metrics/internal/agent/client"
+	"github.com/bjlag/go-metrics/internal/securety/ipfilter"
 )
 
-const RealIPMeta = "real-ip"
+const (
+	// RealIPMeta ключ метаданных с адресом клиента, который передает агент или прокси.
+	RealIPMeta = "real-ip"
+	// ForwardedForMeta ключ метаданных с цепочкой адресов клиента и прокси, как в заголовке X-Forwarded-For.
+	ForwardedForMeta = "x-forwarded-for"
+)
 
-func RealIPClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-	md, ok := metadata.FromOutgoingContext(ctx)
-	if !ok {
-		md = metadata.New(nil)
+// RealIPClientInterceptor передает адрес клиента, который возвращает ip, в метаданных [RealIPMeta].
+// Адрес запрашивается при каждом вызове. Если адрес не определен, он не передается.
+func RealIPClientInterceptor(ip func() net.IP) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withRealIP(ctx, ip()), method, req, reply, cc, opts...)
 	}
+}
 
-	md.Set(RealIPMeta, clientIP.GetOutboundIP().String())
-
-	ctx = metadata.NewOutgoingContext(ctx, md)
-
-	return invoker(ctx, method, req, reply, cc, opts...)
+// RealIPStreamClientInterceptor передает адрес клиента, который возвращает ip, в метаданных потока.
+func RealIPStreamClientInterceptor(ip func() net.IP) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withRealIP(ctx, ip()), desc, cc, method, opts...)
+	}
 }
 
-func CheckRealIPServerMiddleware(trustedSubnet *net.IPNet) grpc.UnaryServerInterceptor {
+// CheckRealIPServerMiddleware проверяет, что запрос идет с разрешенного адреса. Адрес клиента определяется
+// по адресу соединения, метаданные real-ip и x-forwarded-for учитываются только для доверенных прокси.
+func CheckRealIPServerMiddleware(filter *ipfilter.Filter) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+		if err := checkRealIP(ctx, filter); err != nil {
+			return nil, err
 		}
 
-		realIP := md.Get(RealIPMeta)
-		if len(realIP) == 0 {
-			return nil, status.Errorf(codes.PermissionDenied, "permission denied")
-		}
+		return handler(ctx, req)
+	}
+}
 
-		ip := net.ParseIP(realIP[0])
-		if ip == nil {
-			return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+// CheckRealIPStreamServerInterceptor проверяет адрес клиента при открытии потока, см. [CheckRealIPServerMiddleware].
+func CheckRealIPStreamServerInterceptor(filter *ipfilter.Filter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkRealIP(ss.Context(), filter); err != nil {
+			return err
 		}
 
-		if !trustedSubnet.Contains(ip) {
-			return nil, status.Errorf(codes.PermissionDenied, "permission denied")
-		}
+		return handler(srv, ss)
+	}
+}
 
-		return handler(ctx, req)
+func withRealIP(ctx context.Context, ip net.IP) context.Context {
+	if ip == nil {
+		return ctx
 	}
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		md = metadata.New(nil)
+	}
+
+	md.Set(RealIPMeta, ip.String())
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+func checkRealIP(ctx context.Context, filter *ipfilter.Filter) error {
+	if !filter.Enable() {
+		return nil
+	}
+
+	var remote net.IP
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remote = ipfilter.RemoteIP(p.Addr.String())
+	}
+
+	var forwardedFor, realIP string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		forwardedFor = strings.Join(md.Get(ForwardedForMeta), ",")
+		realIP = firstValue(md, RealIPMeta)
+	}
+
+	ip := filter.ClientIP(remote, forwardedFor, realIP)
+	if ip == nil || !filter.Allowed(ip) {
+		return status.Errorf(codes.PermissionDenied, "permission denied")
+	}
+
+	return nil
 }
@@ -0,0 +1,104 @@
+package interceptor_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/bjlag/go-metrics/internal/rpc/interceptor"
+	"github.com/bjlag/go-metrics/internal/securety/ipfilter"
+)
+
+func TestCheckRealIPServerMiddleware(t *testing.T) {
+	parse := func(s string) []*net.IPNet {
+		nets, err := ipfilter.ParseCIDRs(s)
+		require.NoError(t, err)
+		return nets
+	}
+
+	filter := ipfilter.New(
+		ipfilter.WithAllowed(parse("192.168.1.0/24,fd00::/8")),
+		ipfilter.WithTrustedProxies(parse("10.0.0.1")),
+	)
+
+	tests := []struct {
+		name     string
+		filter   *ipfilter.Filter
+		peer     string
+		md       metadata.MD
+		wantCode codes.Code
+	}{
+		{
+			name:     "filter is disabled",
+			peer:     "192.168.2.1:1234",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "allowed peer",
+			filter:   filter,
+			peer:     "192.168.1.1:1234",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "allowed IPv6 peer",
+			filter:   filter,
+			peer:     "[fd00::1]:1234",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "client behind trusted proxy",
+			filter:   filter,
+			peer:     "10.0.0.1:1234",
+			md:       metadata.Pairs(interceptor.ForwardedForMeta, "192.168.1.1"),
+			wantCode: codes.OK,
+		},
+		{
+			name:     "real-ip from trusted proxy",
+			filter:   filter,
+			peer:     "10.0.0.1:1234",
+			md:       metadata.Pairs(interceptor.RealIPMeta, "192.168.1.1"),
+			wantCode: codes.OK,
+		},
+		{
+			name:     "real-ip from untrusted client is ignored",
+			filter:   filter,
+			peer:     "192.168.2.1:1234",
+			md:       metadata.Pairs(interceptor.RealIPMeta, "192.168.1.1"),
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "without peer",
+			filter:   filter,
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.peer != "" {
+				addr, err := net.ResolveTCPAddr("tcp", tt.peer)
+				require.NoError(t, err)
+				ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+			}
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+
+			handler := func(_ context.Context, _ any) (any, error) {
+				return nil, nil
+			}
+
+			_, err := interceptor.CheckRealIPServerMiddleware(tt.filter)(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
@@ -34,3 +34,29 @@ func LoggerServerInterceptor(log logger.Logger) grpc.UnaryServerInterceptor {
 		return resp, err
 	}
 }
+
+func LoggerStreamClientInterceptor(log logger.Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+
+		log.
+			WithField("method", method).
+			WithField("code", status.Code(err)).
+			Info("Open RPC stream")
+
+		return stream, err
+	}
+}
+
+func LoggerStreamServerInterceptor(log logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+
+		log.
+			WithField("method", info.FullMethod).
+			WithField("code", status.Code(err)).
+			Info("Closed RPC stream")
+
+		return err
+	}
+}
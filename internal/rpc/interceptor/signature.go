@@ -9,14 +9,28 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
+	"github.com/bjlag/go-metrics/internal/generated/rpc"
 	"github.com/bjlag/go-metrics/internal/securety/signature"
 )
 
 const headerHash = "HashSHA256"
 
+// signedMethods изменяющие методы сервиса. Подписываются только их вызовы, читающие методы,
+// как и читающие маршруты HTTP API, не подписываются.
+var signedMethods = map[string]bool{
+	rpc.MetricService_Updates_FullMethodName:       true,
+	rpc.MetricService_StreamUpdates_FullMethodName: true,
+	rpc.MetricService_Delete_FullMethodName:        true,
+}
+
+// Единственный метод, вызовы которого агенты предыдущих версий подписывали без имени метода.
+const legacySignedMethod = rpc.MetricService_Updates_FullMethodName
+
+// SignatureClientInterceptor подписывает вызов изменяющего метода. Подписываются имя метода и запрос,
+// см. [signature.MethodPayload].
 func SignatureClientInterceptor(sign *signature.SignManager) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		if sign.Enable() {
+		if sign.Enable() && signedMethods[method] {
 			jsonb, err := json.Marshal(req)
 			if err != nil {
 				return status.Errorf(codes.Internal, "failed to marshal request: %s", err.Error())
@@ -27,7 +41,13 @@ func SignatureClientInterceptor(sign *signature.SignManager) grpc.UnaryClientInt
 				md = metadata.New(nil)
 			}
 
-			md.Set(headerHash, sign.Sing(jsonb))
+			stamp := signature.NewStamp()
+			md.Set(headerHash, sign.Sing(signature.MethodPayload(method, stamp, jsonb)))
+			md.Set(signature.HeaderTimestamp, stamp.TimestampString())
+			md.Set(signature.HeaderNonce, stamp.Nonce)
+			if keyID := sign.KeyID(); keyID != "" {
+				md.Set(signature.HeaderKeyID, keyID)
+			}
 
 			ctx = metadata.NewOutgoingContext(ctx, md)
 		}
@@ -36,9 +56,11 @@ func SignatureClientInterceptor(sign *signature.SignManager) grpc.UnaryClientInt
 	}
 }
 
+// CheckSignatureServerInterceptor проверяет подпись вызова изменяющего метода. Для метода Updates также принимается
+// подпись одного запроса, которую отправляют агенты предыдущих версий.
 func CheckSignatureServerInterceptor(sign *signature.SignManager) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		if sign.Enable() {
+		if sign.Enable() && signedMethods[info.FullMethod] {
 			md, ok := metadata.FromIncomingContext(ctx)
 			if !ok {
 				return nil, status.Error(codes.FailedPrecondition, "don't have signature")
@@ -54,12 +76,149 @@ func CheckSignatureServerInterceptor(sign *signature.SignManager) grpc.UnaryServ
 				return nil, status.Errorf(codes.Internal, "failed to marshal request: %s", err.Error())
 			}
 
-			isValid, _ := sign.Verify(jsonb, signHash[0])
+			stamp, err := signature.ParseStamp(firstValue(md, signature.HeaderTimestamp), firstValue(md, signature.HeaderNonce))
+			if err != nil {
+				return nil, status.Error(codes.FailedPrecondition, err.Error())
+			}
+
+			keyID := firstValue(md, signature.HeaderKeyID)
+
+			isValid, _ := sign.VerifyKey(keyID, signature.MethodPayload(info.FullMethod, stamp, jsonb), signHash[0])
+			if !isValid && info.FullMethod == legacySignedMethod {
+				isValid, _ = sign.VerifyKey(keyID, stamp.Payload(jsonb), signHash[0])
+			}
 			if !isValid {
 				return nil, status.Error(codes.FailedPrecondition, "invalid signature")
 			}
+
+			if err = sign.CheckReplay(stamp); err != nil {
+				return nil, status.Error(codes.FailedPrecondition, err.Error())
+			}
 		}
 
 		return handler(ctx, req)
 	}
 }
+
+// SignatureStreamClientInterceptor подписывает каждый батч потока вместе с именем метода.
+// Подпись передается в поле signature батча, так как заголовки потока отправляются один раз при его открытии.
+// Идентификатор ключа передается в метаданных потока.
+func SignatureStreamClientInterceptor(sign *signature.SignManager) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if keyID := sign.KeyID(); keyID != "" && sign.Enable() {
+			md, ok := metadata.FromOutgoingContext(ctx)
+			if !ok {
+				md = metadata.New(nil)
+			}
+
+			md.Set(signature.HeaderKeyID, keyID)
+			ctx = metadata.NewOutgoingContext(ctx, md)
+		}
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil || !sign.Enable() {
+			return stream, err
+		}
+
+		return &signClientStream{ClientStream: stream, sign: sign, method: method}, nil
+	}
+}
+
+// CheckSignatureStreamServerInterceptor проверяет подпись каждого батча потока.
+func CheckSignatureStreamServerInterceptor(sign *signature.SignManager) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !sign.Enable() {
+			return handler(srv, ss)
+		}
+
+		return handler(srv, &checkSignServerStream{ServerStream: ss, sign: sign, method: info.FullMethod})
+	}
+}
+
+type signClientStream struct {
+	grpc.ClientStream
+	sign   *signature.SignManager
+	method string
+}
+
+func (s *signClientStream) SendMsg(m any) error {
+	in, ok := m.(*rpc.UpdatesIn)
+	if !ok {
+		return s.ClientStream.SendMsg(m)
+	}
+
+	jsonb, err := unsignedJSON(in)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to marshal request: %s", err.Error())
+	}
+
+	stamp := signature.NewStamp()
+	in.Signature = s.sign.Sing(signature.MethodPayload(s.method, stamp, jsonb))
+	in.Timestamp = stamp.Timestamp
+	in.Nonce = stamp.Nonce
+
+	return s.ClientStream.SendMsg(in)
+}
+
+type checkSignServerStream struct {
+	grpc.ServerStream
+	sign   *signature.SignManager
+	method string
+}
+
+func (s *checkSignServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err != nil {
+		return err
+	}
+
+	in, ok := m.(*rpc.UpdatesIn)
+	if !ok {
+		return nil
+	}
+
+	if in.Signature == "" {
+		return status.Error(codes.FailedPrecondition, "don't have signature")
+	}
+
+	jsonb, err := unsignedJSON(in)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to marshal request: %s", err.Error())
+	}
+
+	stamp := signature.Stamp{Timestamp: in.Timestamp, Nonce: in.Nonce}
+	if err = stamp.Validate(); err != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	var keyID string
+	if md, ok := metadata.FromIncomingContext(s.Context()); ok {
+		keyID = firstValue(md, signature.HeaderKeyID)
+	}
+
+	isValid, _ := s.sign.VerifyKey(keyID, signature.MethodPayload(s.method, stamp, jsonb), in.Signature)
+	if !isValid {
+		return status.Error(codes.FailedPrecondition, "invalid signature")
+	}
+
+	if err = s.sign.CheckReplay(stamp); err != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return nil
+}
+
+// Функция firstValue возвращает первое значение ключа метаданных или пустую строку.
+func firstValue(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// unsignedJSON возвращает JSON батча без полей подписи, именно он подписывается вместе с меткой батча.
+func unsignedJSON(in *rpc.UpdatesIn) ([]byte, error) {
+	return json.Marshal(&rpc.UpdatesIn{Metrics: in.Metrics})
+}
@@ -0,0 +1,145 @@
+package interceptor_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/bjlag/go-metrics/internal/generated/rpc"
+	"github.com/bjlag/go-metrics/internal/rpc/interceptor"
+	"github.com/bjlag/go-metrics/internal/securety/signature"
+)
+
+func TestCheckSignatureServerInterceptor(t *testing.T) {
+	sign := signature.NewSignManager("secret")
+
+	updates := &rpc.UpdatesIn{Metrics: []*rpc.Metric{{Id: "Alloc", Type: "gauge"}}}
+	deletes := &rpc.DeleteIn{Metrics: []*rpc.MetricRef{{Id: "Alloc", Type: "gauge"}}}
+
+	// signedByClient возвращает метаданные, которые клиентский перехватчик отправляет при вызове method.
+	signedByClient := func(t *testing.T, method string, req any) metadata.MD {
+		var md metadata.MD
+		invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+			md, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		}
+
+		err := interceptor.SignatureClientInterceptor(sign)(context.Background(), method, req, nil, nil, invoker)
+		require.NoError(t, err)
+
+		return md
+	}
+
+	// signedBodyOnly возвращает метаданные с подписью одного запроса, как у агентов предыдущих версий.
+	signedBodyOnly := func(t *testing.T, req any) metadata.MD {
+		jsonb, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		stamp := signature.NewStamp()
+
+		return metadata.Pairs(
+			"HashSHA256", sign.Sing(stamp.Payload(jsonb)),
+			signature.HeaderTimestamp, stamp.TimestampString(),
+			signature.HeaderNonce, stamp.Nonce,
+		)
+	}
+
+	tests := []struct {
+		name     string
+		md       func(t *testing.T) metadata.MD
+		method   string
+		req      any
+		wantCode codes.Code
+	}{
+		{
+			name: "updates signed with method",
+			md: func(t *testing.T) metadata.MD {
+				return signedByClient(t, rpc.MetricService_Updates_FullMethodName, updates)
+			},
+			method:   rpc.MetricService_Updates_FullMethodName,
+			req:      updates,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "updates signed with body only",
+			md:       func(t *testing.T) metadata.MD { return signedBodyOnly(t, updates) },
+			method:   rpc.MetricService_Updates_FullMethodName,
+			req:      updates,
+			wantCode: codes.OK,
+		},
+		{
+			name: "delete signed with method",
+			md: func(t *testing.T) metadata.MD {
+				return signedByClient(t, rpc.MetricService_Delete_FullMethodName, deletes)
+			},
+			method:   rpc.MetricService_Delete_FullMethodName,
+			req:      deletes,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "delete signed with body only",
+			md:       func(t *testing.T) metadata.MD { return signedBodyOnly(t, deletes) },
+			method:   rpc.MetricService_Delete_FullMethodName,
+			req:      deletes,
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name: "signed for another method",
+			md: func(t *testing.T) metadata.MD {
+				return signedByClient(t, rpc.MetricService_Delete_FullMethodName, updates)
+			},
+			method:   rpc.MetricService_Updates_FullMethodName,
+			req:      updates,
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "write without signature",
+			md:       func(_ *testing.T) metadata.MD { return metadata.MD{} },
+			method:   rpc.MetricService_Updates_FullMethodName,
+			req:      updates,
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "read without signature",
+			md:       func(_ *testing.T) metadata.MD { return metadata.MD{} },
+			method:   rpc.MetricService_GetValue_FullMethodName,
+			req:      &rpc.GetValueIn{Id: "Alloc", Type: "gauge"},
+			wantCode: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md(t))
+
+			handler := func(_ context.Context, _ any) (any, error) {
+				return nil, nil
+			}
+
+			_, err := interceptor.CheckSignatureServerInterceptor(sign)(ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestSignatureClientInterceptor_ReadNotSigned(t *testing.T) {
+	var md metadata.MD
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+
+	err := interceptor.SignatureClientInterceptor(signature.NewSignManager("secret"))(
+		context.Background(), rpc.MetricService_Query_FullMethodName, &rpc.QueryIn{}, nil, nil, invoker,
+	)
+	require.NoError(t, err)
+
+	assert.Empty(t, md.Get("HashSHA256"))
+}
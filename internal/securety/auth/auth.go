@@ -0,0 +1,173 @@
+// Package auth проверяет токены доступа к API сервера и роли, выданные токенам.
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Role роль токена.
+type Role string
+
+const (
+	// RoleIngest запись метрик.
+	RoleIngest Role = "ingest"
+	// RoleRead чтение метрик, алертов и запросы к истории.
+	RoleRead Role = "read"
+	// RoleAdmin удаление метрик и отладочные ручки. Токен с этой ролью имеет и все остальные роли.
+	RoleAdmin Role = "admin"
+)
+
+var (
+	// ErrUnauthenticated ошибка, если токен не передан или неизвестен.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrForbidden ошибка, если у токена нет нужной роли.
+	ErrForbidden = errors.New("forbidden")
+	// ErrInvalidTokens ошибка, если файл токенов содержит некорректные записи.
+	ErrInvalidTokens = errors.New("invalid tokens file")
+)
+
+// Identity владелец токена.
+type Identity struct {
+	Name  string
+	Roles []Role
+}
+
+// HasRole возвращает true, если у владельца токена есть роль role.
+func (i Identity) HasRole(role Role) bool {
+	for _, r := range i.Roles {
+		if r == role || r == RoleAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TokenStore хранит токены, загруженные из JSON файла вида
+// {"tokens": [{"name": "grafana", "token": "...", "roles": ["read"]}]}.
+// Токены хранятся в виде хешей SHA-256. Изменения файла применяются методом [TokenStore.Reload].
+//
+// Нулевой указатель на TokenStore означает, что аутентификация выключена и разрешены все запросы.
+type TokenStore struct {
+	path string
+
+	lock   sync.RWMutex
+	tokens map[[sha256.Size]byte]Identity
+}
+
+// LoadTokenStore загружает токены из файла path.
+func LoadTokenStore(path string) (*TokenStore, error) {
+	s := &TokenStore{path: path}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Reload перечитывает файл токенов. Если файл прочитать не удалось, продолжают действовать ранее загруженные токены.
+func (s *TokenStore) Reload() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read tokens file: %w", err)
+	}
+
+	var file struct {
+		Tokens []struct {
+			Name  string `json:"name"`
+			Token string `json:"token"`
+			Roles []Role `json:"roles"`
+		} `json:"tokens"`
+	}
+
+	if err = json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTokens, err)
+	}
+
+	tokens := make(map[[sha256.Size]byte]Identity, len(file.Tokens))
+	for _, t := range file.Tokens {
+		if t.Name == "" || t.Token == "" {
+			return fmt.Errorf("%w: token name and value are required", ErrInvalidTokens)
+		}
+
+		for _, role := range t.Roles {
+			if role != RoleIngest && role != RoleRead && role != RoleAdmin {
+				return fmt.Errorf("%w: unknown role '%s' of token '%s'", ErrInvalidTokens, role, t.Name)
+			}
+		}
+
+		hash := sha256.Sum256([]byte(t.Token))
+		if _, ok := tokens[hash]; ok {
+			return fmt.Errorf("%w: duplicate token '%s'", ErrInvalidTokens, t.Name)
+		}
+
+		tokens[hash] = Identity{Name: t.Name, Roles: t.Roles}
+	}
+
+	s.lock.Lock()
+	s.tokens = tokens
+	s.lock.Unlock()
+
+	return nil
+}
+
+// Enable возвращает true, если аутентификация включена.
+func (s *TokenStore) Enable() bool {
+	return s != nil
+}
+
+// Len возвращает количество токенов.
+func (s *TokenStore) Len() int {
+	if s == nil {
+		return 0
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return len(s.tokens)
+}
+
+// Authorize проверяет, что токен token известен и у него есть роль role.
+// Возвращает [ErrUnauthenticated] для пустого или неизвестного токена и [ErrForbidden], если роли нет.
+// Если аутентификация выключена, разрешает любой запрос.
+func (s *TokenStore) Authorize(token string, role Role) (Identity, error) {
+	if !s.Enable() {
+		return Identity{}, nil
+	}
+
+	if token == "" {
+		return Identity{}, ErrUnauthenticated
+	}
+
+	s.lock.RLock()
+	identity, ok := s.tokens[sha256.Sum256([]byte(token))]
+	s.lock.RUnlock()
+
+	if !ok {
+		return Identity{}, ErrUnauthenticated
+	}
+
+	if !identity.HasRole(role) {
+		return identity, ErrForbidden
+	}
+
+	return identity, nil
+}
+
+// BearerToken возвращает токен из значения заголовка Authorization вида "Bearer <token>".
+func BearerToken(header string) string {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,97 @@
+package auth_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/securety/auth"
+)
+
+func writeTokens(t *testing.T, path, data string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+}
+
+func TestTokenStore_Authorize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	writeTokens(t, path, `{"tokens":[
+		{"name":"agent","token":"t1","roles":["ingest"]},
+		{"name":"grafana","token":"t2","roles":["read"]},
+		{"name":"ops","token":"t3","roles":["admin"]}
+	]}`)
+
+	tokens, err := auth.LoadTokenStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, tokens.Len())
+
+	tests := []struct {
+		name    string
+		token   string
+		role    auth.Role
+		wantErr error
+	}{
+		{name: "ingest", token: "t1", role: auth.RoleIngest},
+		{name: "read", token: "t2", role: auth.RoleRead},
+		{name: "admin implies read", token: "t3", role: auth.RoleRead},
+		{name: "admin implies ingest", token: "t3", role: auth.RoleIngest},
+		{name: "ingest can not read", token: "t1", role: auth.RoleRead, wantErr: auth.ErrForbidden},
+		{name: "read can not delete", token: "t2", role: auth.RoleAdmin, wantErr: auth.ErrForbidden},
+		{name: "empty token", role: auth.RoleRead, wantErr: auth.ErrUnauthenticated},
+		{name: "unknown token", token: "t4", role: auth.RoleRead, wantErr: auth.ErrUnauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tokens.Authorize(tt.token, tt.role)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestTokenStore_Disabled(t *testing.T) {
+	var tokens *auth.TokenStore
+
+	assert.False(t, tokens.Enable())
+
+	_, err := tokens.Authorize("", auth.RoleAdmin)
+	assert.NoError(t, err)
+}
+
+func TestTokenStore_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	writeTokens(t, path, `{"tokens":[{"name":"agent","token":"t1","roles":["ingest"]}]}`)
+
+	tokens, err := auth.LoadTokenStore(path)
+	require.NoError(t, err)
+
+	writeTokens(t, path, `{"tokens":[{"name":"agent","token":"t2","roles":["ingest"]}]}`)
+	require.NoError(t, tokens.Reload())
+
+	_, err = tokens.Authorize("t1", auth.RoleIngest)
+	assert.ErrorIs(t, err, auth.ErrUnauthenticated)
+
+	_, err = tokens.Authorize("t2", auth.RoleIngest)
+	assert.NoError(t, err)
+
+	writeTokens(t, path, `{"tokens":[{"name":"agent","token":"t3","roles":["root"]}]}`)
+	assert.ErrorIs(t, tokens.Reload(), auth.ErrInvalidTokens)
+
+	_, err = tokens.Authorize("t2", auth.RoleIngest)
+	assert.NoError(t, err)
+}
+
+func TestBearerToken(t *testing.T) {
+	assert.Equal(t, "abc", auth.BearerToken("Bearer abc"))
+	assert.Equal(t, "abc", auth.BearerToken("bearer abc"))
+	assert.Equal(t, "", auth.BearerToken("Basic abc"))
+	assert.Equal(t, "", auth.BearerToken(""))
+}
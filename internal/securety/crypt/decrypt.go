@@ -30,7 +30,34 @@ func NewDecryptManager(pathToPrivateKey string) (*DecryptManager, error) {
 	}, nil
 }
 
-// Decrypt расшифровывает переданные данные.
+// DecryptScheme расшифровывает данные, зашифрованные по схеме scheme.
+// Пустая схема означает [SchemeRSA]. Если приватный ключ не задан, данные возвращаются без изменений.
+func (c DecryptManager) DecryptScheme(scheme string, cipherData []byte) ([]byte, error) {
+	switch scheme {
+	case "", SchemeRSA:
+		return c.Decrypt(cipherData)
+	case SchemeEnvelope:
+		return c.DecryptEnvelope(cipherData)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownScheme, scheme)
+	}
+}
+
+// DecryptEnvelope расшифровывает данные, зашифрованные по схеме [SchemeEnvelope].
+func (c DecryptManager) DecryptEnvelope(cipherData []byte) ([]byte, error) {
+	if !c.isEnabled() {
+		return cipherData, nil
+	}
+
+	return open(c.privateKey, cipherData)
+}
+
+// Enable сообщает, включена ли расшифровка.
+func (c DecryptManager) Enable() bool {
+	return c.isEnabled()
+}
+
+// Decrypt расшифровывает данные, зашифрованные по устаревшей схеме [SchemeRSA].
 func (c DecryptManager) Decrypt(cipherData []byte) ([]byte, error) {
 	if !c.isEnabled() {
 		return cipherData, nil
@@ -49,7 +76,7 @@ func (c DecryptManager) Decrypt(cipherData []byte) ([]byte, error) {
 
 		data, err := rsa.DecryptPKCS1v15(rand.Reader, c.privateKey, cipherData[start:end])
 		if err != nil {
-			return nil, fmt.Errorf("rsa.DecryptOAEP: %w", err)
+			return nil, fmt.Errorf("rsa.DecryptPKCS1v15: %w", err)
 		}
 
 		result = append(result, data...)
@@ -1,12 +1,10 @@
 package crypt
 
 import (
-	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
-	"math"
 	"os"
 )
 
@@ -31,33 +29,29 @@ func NewEncryptManager(pathToPublicKey string) (*EncryptManager, error) {
 	}, nil
 }
 
-// Encrypt шифрует переданные данные.
+// Encrypt шифрует переданные данные по схеме [SchemeEnvelope].
+// Если публичный ключ не задан, данные возвращаются без изменений.
 func (c EncryptManager) Encrypt(data []byte) ([]byte, error) {
 	if !c.isEnabled() {
 		return data, nil
 	}
 
-	dataLen := len(data)
-	sizeOneMsg := c.publicKey.Size() - 11
-
-	sizeCipherData := int(math.Ceil(float64(dataLen)/float64(sizeOneMsg))) * c.publicKey.Size()
-	result := make([]byte, 0, sizeCipherData)
-
-	for start := 0; start < dataLen; start += sizeOneMsg {
-		end := start + sizeOneMsg
-		if end > dataLen {
-			end = dataLen
-		}
-
-		cipherData, err := rsa.EncryptPKCS1v15(rand.Reader, c.publicKey, data[start:end])
-		if err != nil {
-			return nil, fmt.Errorf("rsa.EncryptPKCS1v15: %w", err)
-		}
+	return seal(c.publicKey, data)
+}
 
-		result = append(result, cipherData...)
+// Scheme возвращает схему, по которой [EncryptManager.Encrypt] шифрует данные,
+// или пустую строку, если шифрование выключено.
+func (c EncryptManager) Scheme() string {
+	if !c.isEnabled() {
+		return ""
 	}
 
-	return result, nil
+	return SchemeEnvelope
+}
+
+// Enable сообщает, включено ли шифрование.
+func (c EncryptManager) Enable() bool {
+	return c.isEnabled()
 }
 
 func (c EncryptManager) isEnabled() bool {
@@ -0,0 +1,113 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// HeaderScheme заголовок, в котором клиент сообщает схему шифрования тела запроса.
+// Запрос без заголовка расшифровывается по схеме [SchemeRSA], так работают агенты предыдущих версий.
+const HeaderScheme = "X-Encryption-Scheme"
+
+const (
+	// SchemeRSA устаревшая схема: данные шифруются блоками RSA PKCS#1 v1.5.
+	SchemeRSA = "rsa-pkcs1v15"
+	// SchemeEnvelope схема конверта: данные шифруются случайным ключом AES-256-GCM,
+	// который в свою очередь шифруется RSA-OAEP (SHA-256) и передается вместе с данными.
+	SchemeEnvelope = "aes256gcm-rsaoaep"
+)
+
+// Размер ключа AES-256.
+const dataKeySize = 32
+
+var (
+	// ErrUnknownScheme ошибка, если схема шифрования не поддерживается.
+	ErrUnknownScheme = errors.New("unknown encryption scheme")
+	// ErrInvalidEnvelope ошибка, если зашифрованные данные не соответствуют формату конверта.
+	ErrInvalidEnvelope = errors.New("invalid encryption envelope")
+	// ErrKeyMismatch ошибка, если ключ данных зашифрован не тем ключом, что есть у сервера.
+	// Обычно означает, что ключи агента и сервера разошлись, например после смены ключа.
+	ErrKeyMismatch = errors.New("data key is encrypted with another key")
+)
+
+// Функция seal шифрует данные по схеме [SchemeEnvelope].
+// Результат имеет вид: зашифрованный RSA-OAEP ключ данных (размер ключа RSA) || nonce || шифротекст с тегом GCM.
+func seal(publicKey *rsa.PublicKey, data []byte) ([]byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	wrappedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, dataKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("rsa.EncryptOAEP: %w", err)
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	result := make([]byte, 0, len(wrappedKey)+len(nonce)+len(data)+gcm.Overhead())
+	result = append(result, wrappedKey...)
+	result = append(result, nonce...)
+
+	return gcm.Seal(result, nonce, data, nil), nil
+}
+
+// Функция open расшифровывает данные, зашифрованные функцией seal.
+// Если ключ данных не удалось расшифровать, возвращается [ErrKeyMismatch], остальные ошибки возвращаются
+// как [ErrInvalidEnvelope], так как означают некорректные данные клиента.
+func open(privateKey *rsa.PrivateKey, cipherData []byte) ([]byte, error) {
+	keySize := privateKey.PublicKey.Size()
+	if len(cipherData) < keySize {
+		return nil, ErrInvalidEnvelope
+	}
+
+	dataKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, cipherData[:keySize], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: rsa.DecryptOAEP: %w", ErrKeyMismatch, err)
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+
+	rest := cipherData[keySize:]
+	if len(rest) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, ErrInvalidEnvelope
+	}
+
+	data, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+
+	return data, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("aes.NewCipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("cipher.NewGCM: %w", err)
+	}
+
+	return gcm, nil
+}
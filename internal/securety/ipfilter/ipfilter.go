@@ -0,0 +1,170 @@
+// Package ipfilter определяет IP адрес клиента и проверяет его по спискам разрешенных и запрещенных подсетей.
+//
+// Адрес клиента берется из адреса соединения. Заголовки X-Forwarded-For и X-Real-IP учитываются,
+// только если соединение установлено с доверенного прокси, иначе клиент мог бы подставить в них любой адрес.
+package ipfilter
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Filter проверяет IP адрес клиента. Поддерживаются адреса IPv4 и IPv6.
+//
+// Нулевой указатель на Filter означает, что проверка выключена: разрешены все адреса, а адресом клиента считается
+// адрес соединения.
+type Filter struct {
+	allowed []*net.IPNet
+	denied  []*net.IPNet
+	proxies []*net.IPNet
+}
+
+// Option настраивает фильтр.
+type Option func(f *Filter)
+
+// WithAllowed разрешает запросы только из подсетей nets.
+func WithAllowed(nets []*net.IPNet) Option {
+	return func(f *Filter) {
+		f.allowed = append(f.allowed, nets...)
+	}
+}
+
+// WithDenied запрещает запросы из подсетей nets. Запрет имеет приоритет над разрешением.
+func WithDenied(nets []*net.IPNet) Option {
+	return func(f *Filter) {
+		f.denied = append(f.denied, nets...)
+	}
+}
+
+// WithTrustedProxies задает подсети доверенных прокси. Только для соединений из них учитываются
+// заголовки X-Forwarded-For и X-Real-IP.
+func WithTrustedProxies(nets []*net.IPNet) Option {
+	return func(f *Filter) {
+		f.proxies = append(f.proxies, nets...)
+	}
+}
+
+// New создает фильтр.
+func New(opts ...Option) *Filter {
+	f := &Filter{}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// Enable возвращает true, если задан хотя бы один список разрешенных или запрещенных подсетей.
+func (f *Filter) Enable() bool {
+	return f != nil && (len(f.allowed) > 0 || len(f.denied) > 0)
+}
+
+// Allowed возвращает true, если запросы с адреса ip разрешены: адрес не входит в запрещенные подсети и,
+// если список разрешенных подсетей задан, входит в одну из них. Пустой адрес не разрешен, если фильтр включен.
+func (f *Filter) Allowed(ip net.IP) bool {
+	if !f.Enable() {
+		return true
+	}
+
+	if ip == nil || contains(f.denied, ip) {
+		return false
+	}
+
+	return len(f.allowed) == 0 || contains(f.allowed, ip)
+}
+
+// ClientIP возвращает адрес клиента по адресу соединения remote и значениям заголовков X-Forwarded-For и X-Real-IP.
+//
+// Если remote не доверенный прокси, заголовки игнорируются и возвращается remote. Иначе X-Forwarded-For
+// просматривается справа налево, пропуская доверенные прокси, и возвращается первый адрес не из них.
+// Если X-Forwarded-For не передан, используется X-Real-IP.
+func (f *Filter) ClientIP(remote net.IP, forwardedFor, realIP string) net.IP {
+	if f == nil || remote == nil || !contains(f.proxies, remote) {
+		return remote
+	}
+
+	if forwardedFor != "" {
+		client := remote
+
+		hops := strings.Split(forwardedFor, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				// Адрес перед доверенным прокси записан некорректно, клиентом считается последний известный адрес.
+				return client
+			}
+
+			client = ip
+			if !contains(f.proxies, ip) {
+				return ip
+			}
+		}
+
+		return client
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(realIP)); ip != nil {
+		return ip
+	}
+
+	return remote
+}
+
+// RemoteIP возвращает IP адрес из адреса соединения вида host:port или nil, если адрес некорректный.
+func RemoteIP(addr string) net.IP {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	return net.ParseIP(host)
+}
+
+// ParseCIDRs разбирает список подсетей через запятую, например "192.168.1.0/24,fd00::/8".
+// Отдельный адрес без маски считается подсетью из одного адреса.
+func ParseCIDRs(s string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+
+	for _, value := range strings.Split(s, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address '%s'", value)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("parse CIDR error: %w", err)
+		}
+
+		nets = append(nets, ipNet)
+	}
+
+	return nets, nil
+}
+
+func contains(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
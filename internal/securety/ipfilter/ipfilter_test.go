@@ -0,0 +1,157 @@
+package ipfilter_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/securety/ipfilter"
+)
+
+func mustParseCIDRs(t *testing.T, s string) []*net.IPNet {
+	t.Helper()
+
+	nets, err := ipfilter.ParseCIDRs(s)
+	require.NoError(t, err)
+
+	return nets
+}
+
+func TestFilter_Allowed(t *testing.T) {
+	filter := ipfilter.New(
+		ipfilter.WithAllowed(mustParseCIDRs(t, "192.168.1.0/24, 10.0.0.0/8, fd00::/8")),
+		ipfilter.WithDenied(mustParseCIDRs(t, "10.0.0.13, fd00::bad")),
+	)
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "192.168.1.10", want: true},
+		{ip: "10.1.2.3", want: true},
+		{ip: "fd00::1", want: true},
+		{ip: "::ffff:192.168.1.10", want: true},
+		{ip: "192.168.2.10", want: false},
+		{ip: "10.0.0.13", want: false},
+		{ip: "fd00::bad", want: false},
+		{ip: "2001:db8::1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.want, filter.Allowed(net.ParseIP(tt.ip)))
+		})
+	}
+
+	assert.False(t, filter.Allowed(nil))
+}
+
+func TestFilter_Allowed_DenyOnly(t *testing.T) {
+	filter := ipfilter.New(ipfilter.WithDenied(mustParseCIDRs(t, "203.0.113.0/24")))
+
+	assert.True(t, filter.Allowed(net.ParseIP("192.168.1.1")))
+	assert.False(t, filter.Allowed(net.ParseIP("203.0.113.7")))
+}
+
+func TestFilter_Disabled(t *testing.T) {
+	var filter *ipfilter.Filter
+
+	assert.False(t, filter.Enable())
+	assert.True(t, filter.Allowed(nil))
+	assert.Equal(t, "127.0.0.1", filter.ClientIP(net.ParseIP("127.0.0.1"), "1.2.3.4", "5.6.7.8").String())
+
+	assert.False(t, ipfilter.New().Enable())
+}
+
+func TestFilter_ClientIP(t *testing.T) {
+	filter := ipfilter.New(ipfilter.WithTrustedProxies(mustParseCIDRs(t, "10.0.0.0/8")))
+
+	tests := []struct {
+		name         string
+		remote       string
+		forwardedFor string
+		realIP       string
+		want         string
+	}{
+		{
+			name:         "not a proxy, headers are ignored",
+			remote:       "192.168.1.5",
+			forwardedFor: "1.2.3.4",
+			realIP:       "1.2.3.4",
+			want:         "192.168.1.5",
+		},
+		{
+			name:   "proxy without headers",
+			remote: "10.0.0.1",
+			want:   "10.0.0.1",
+		},
+		{
+			name:   "proxy with X-Real-IP",
+			remote: "10.0.0.1",
+			realIP: "203.0.113.7",
+			want:   "203.0.113.7",
+		},
+		{
+			name:         "chain of proxies",
+			remote:       "10.0.0.1",
+			forwardedFor: "198.51.100.1, 203.0.113.7, 10.0.0.2",
+			realIP:       "198.51.100.1",
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "IPv6 client",
+			remote:       "10.0.0.1",
+			forwardedFor: "2001:db8::1",
+			want:         "2001:db8::1",
+		},
+		{
+			name:         "only proxies",
+			remote:       "10.0.0.1",
+			forwardedFor: "10.0.0.3, 10.0.0.2",
+			want:         "10.0.0.3",
+		},
+		{
+			name:         "invalid hop",
+			remote:       "10.0.0.1",
+			forwardedFor: "unknown, 10.0.0.2",
+			want:         "10.0.0.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filter.ClientIP(net.ParseIP(tt.remote), tt.forwardedFor, tt.realIP)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestParseCIDRs(t *testing.T) {
+	nets, err := ipfilter.ParseCIDRs("192.168.1.0/24,2001:db8::/32,10.0.0.1,::1")
+	require.NoError(t, err)
+	require.Len(t, nets, 4)
+
+	assert.Equal(t, "192.168.1.0/24", nets[0].String())
+	assert.Equal(t, "2001:db8::/32", nets[1].String())
+	assert.Equal(t, "10.0.0.1/32", nets[2].String())
+	assert.Equal(t, "::1/128", nets[3].String())
+
+	nets, err = ipfilter.ParseCIDRs("")
+	require.NoError(t, err)
+	assert.Empty(t, nets)
+
+	_, err = ipfilter.ParseCIDRs("192.168.1.0/33")
+	assert.Error(t, err)
+
+	_, err = ipfilter.ParseCIDRs("localhost")
+	assert.Error(t, err)
+}
+
+func TestRemoteIP(t *testing.T) {
+	assert.Equal(t, "192.0.2.1", ipfilter.RemoteIP("192.0.2.1:1234").String())
+	assert.Equal(t, "2001:db8::1", ipfilter.RemoteIP("[2001:db8::1]:1234").String())
+	assert.Equal(t, "192.0.2.1", ipfilter.RemoteIP("192.0.2.1").String())
+	assert.Nil(t, ipfilter.RemoteIP("pipe"))
+}
@@ -0,0 +1,104 @@
+package signature
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// HeaderKeyID заголовок HTTP и ключ метаданных gRPC с идентификатором ключа, которым подписан запрос.
+const HeaderKeyID = "X-Key-ID"
+
+// ErrInvalidKeys ошибка, если файл ключей содержит некорректные записи.
+var ErrInvalidKeys = errors.New("invalid keys file")
+
+// Key ключ агента в файле ключей.
+type Key struct {
+	// ID идентификатор ключа, агент передает его вместе с подписью.
+	ID string `json:"id"`
+	// Secret секрет для подписи HMAC-SHA256.
+	Secret string `json:"secret"`
+	// Revoked отозванный ключ, запросы с ним отклоняются.
+	Revoked bool `json:"revoked,omitempty"`
+}
+
+// KeyStore хранит ключи агентов, загруженные из JSON файла вида {"keys": [{"id": "...", "secret": "..."}]}.
+//
+// У каждого агента свой ключ, поэтому утечка конфигурации одного агента не компрометирует остальных.
+// Для ротации агенту выдается новый ключ с новым идентификатором, оба ключа действуют одновременно,
+// а после перехода агента старый ключ отзывается. Изменения файла применяются методом [KeyStore.Reload].
+type KeyStore struct {
+	path string
+
+	lock sync.RWMutex
+	keys map[string][]byte
+}
+
+// LoadKeyStore загружает ключи из файла path.
+func LoadKeyStore(path string) (*KeyStore, error) {
+	s := &KeyStore{path: path}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Reload перечитывает файл ключей. Если файл прочитать не удалось, продолжают действовать ранее загруженные ключи.
+func (s *KeyStore) Reload() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read keys file: %w", err)
+	}
+
+	var file struct {
+		Keys []Key `json:"keys"`
+	}
+
+	if err = json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidKeys, err)
+	}
+
+	keys := make(map[string][]byte, len(file.Keys))
+	for _, key := range file.Keys {
+		if key.ID == "" || key.Secret == "" {
+			return fmt.Errorf("%w: key id and secret are required", ErrInvalidKeys)
+		}
+
+		if _, ok := keys[key.ID]; ok {
+			return fmt.Errorf("%w: duplicate key id '%s'", ErrInvalidKeys, key.ID)
+		}
+
+		if key.Revoked {
+			continue
+		}
+
+		keys[key.ID] = []byte(key.Secret)
+	}
+
+	s.lock.Lock()
+	s.keys = keys
+	s.lock.Unlock()
+
+	return nil
+}
+
+// Secret возвращает секрет действующего ключа id.
+func (s *KeyStore) Secret(id string) ([]byte, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	secret, ok := s.keys[id]
+	return secret, ok
+}
+
+// Len возвращает количество действующих ключей.
+func (s *KeyStore) Len() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return len(s.keys)
+}
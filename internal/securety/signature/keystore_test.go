@@ -0,0 +1,82 @@
+package signature_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/securety/signature"
+)
+
+func TestKeyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	writeKeys := func(content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	writeKeys(`{"keys": [
+		{"id": "agent-1", "secret": "secret-1"},
+		{"id": "agent-2", "secret": "secret-2"},
+		{"id": "agent-3", "secret": "secret-3", "revoked": true}
+	]}`)
+
+	keys, err := signature.LoadKeyStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, keys.Len())
+
+	server := signature.NewSignManager("", signature.WithKeyStore(keys))
+	require.True(t, server.Enable())
+
+	agent1 := signature.NewSignManager("secret-1", signature.WithKeyID("agent-1"))
+	agent3 := signature.NewSignManager("secret-3", signature.WithKeyID("agent-3"))
+	data := []byte("some data")
+
+	t.Run("active key", func(t *testing.T) {
+		ok, _ := server.VerifyKey(agent1.KeyID(), data, agent1.Sing(data))
+		assert.True(t, ok)
+	})
+
+	t.Run("signed with another agent key", func(t *testing.T) {
+		ok, _ := server.VerifyKey("agent-2", data, agent1.Sing(data))
+		assert.False(t, ok)
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		ok, _ := server.VerifyKey(agent3.KeyID(), data, agent3.Sing(data))
+		assert.False(t, ok)
+	})
+
+	t.Run("without key id and shared secret", func(t *testing.T) {
+		ok, _ := server.VerifyKey("", data, agent1.Sing(data))
+		assert.False(t, ok)
+	})
+
+	t.Run("rotation", func(t *testing.T) {
+		writeKeys(`{"keys": [
+			{"id": "agent-1", "secret": "secret-1", "revoked": true},
+			{"id": "agent-1-v2", "secret": "secret-1-v2"}
+		]}`)
+		require.NoError(t, keys.Reload())
+
+		ok, _ := server.VerifyKey(agent1.KeyID(), data, agent1.Sing(data))
+		assert.False(t, ok)
+
+		rotated := signature.NewSignManager("secret-1-v2", signature.WithKeyID("agent-1-v2"))
+		ok, _ = server.VerifyKey(rotated.KeyID(), data, rotated.Sing(data))
+		assert.True(t, ok)
+	})
+
+	t.Run("invalid file keeps previous keys", func(t *testing.T) {
+		writeKeys(`{"keys": [{"id": "agent-4"}]}`)
+		assert.ErrorIs(t, keys.Reload(), signature.ErrInvalidKeys)
+
+		writeKeys(`{"keys": [{"id": "a", "secret": "1"}, {"id": "a", "secret": "2"}]}`)
+		assert.ErrorIs(t, keys.Reload(), signature.ErrInvalidKeys)
+
+		_, ok := keys.Secret("agent-1-v2")
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,203 @@
+package signature
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// HeaderTimestamp заголовок HTTP и ключ метаданных gRPC с временем подписи запроса в секундах Unix.
+	HeaderTimestamp = "X-Sign-Timestamp"
+	// HeaderNonce заголовок HTTP и ключ метаданных gRPC с одноразовым значением запроса.
+	HeaderNonce = "X-Sign-Nonce"
+
+	// Максимальная длина одноразового значения, более длинные значения отклоняются.
+	maxNonceLen = 64
+)
+
+var (
+	// ErrInvalidStamp ошибка, если время подписи или одноразовое значение переданы некорректно.
+	ErrInvalidStamp = errors.New("invalid signature timestamp or nonce")
+	// ErrMissingStamp ошибка, если включена защита от повторов, а запрос подписан без времени и одноразового значения.
+	ErrMissingStamp = errors.New("signature timestamp and nonce are required")
+	// ErrStaleRequest ошибка, если время подписи выходит за окно допустимой давности.
+	ErrStaleRequest = errors.New("signature timestamp is out of window")
+	// ErrReplayedRequest ошибка, если одноразовое значение уже использовалось.
+	ErrReplayedRequest = errors.New("request is replayed")
+)
+
+// Stamp время подписи и одноразовое значение запроса. Подписываются вместе с данными запроса,
+// поэтому перехваченный запрос нельзя повторно отправить: сервер отклонит устаревшее время или повторное значение.
+type Stamp struct {
+	Timestamp int64
+	Nonce     string
+}
+
+// NewStamp создает метку с текущим временем и случайным одноразовым значением.
+func NewStamp() Stamp {
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+
+	return Stamp{
+		Timestamp: time.Now().Unix(),
+		Nonce:     hex.EncodeToString(nonce),
+	}
+}
+
+// ParseStamp разбирает метку из заголовков. Если оба значения пустые, возвращается пустая метка:
+// так подписывают запросы клиенты предыдущих версий.
+func ParseStamp(timestamp, nonce string) (Stamp, error) {
+	if timestamp == "" && nonce == "" {
+		return Stamp{}, nil
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return Stamp{}, fmt.Errorf("%w: %w", ErrInvalidStamp, err)
+	}
+
+	stamp := Stamp{Timestamp: ts, Nonce: nonce}
+	if err = stamp.Validate(); err != nil {
+		return Stamp{}, err
+	}
+
+	return stamp, nil
+}
+
+// Validate проверяет, что непустая метка содержит время подписи и одноразовое значение допустимой длины.
+func (s Stamp) Validate() error {
+	if s.IsZero() {
+		return nil
+	}
+
+	if s.Timestamp <= 0 || s.Nonce == "" || len(s.Nonce) > maxNonceLen {
+		return ErrInvalidStamp
+	}
+
+	return nil
+}
+
+// IsZero возвращает true для пустой метки.
+func (s Stamp) IsZero() bool {
+	return s.Timestamp == 0 && s.Nonce == ""
+}
+
+// TimestampString возвращает время подписи в том виде, в котором оно передается в заголовке.
+func (s Stamp) TimestampString() string {
+	return strconv.FormatInt(s.Timestamp, 10)
+}
+
+// Payload возвращает подписываемые данные: время подписи, одноразовое значение и данные запроса через перевод строки.
+// Для пустой метки данные возвращаются без изменений.
+func (s Stamp) Payload(data []byte) []byte {
+	if s.IsZero() {
+		return data
+	}
+
+	prefix := s.TimestampString() + "\n" + s.Nonce + "\n"
+
+	payload := make([]byte, 0, len(prefix)+len(data))
+	payload = append(payload, prefix...)
+
+	return append(payload, data...)
+}
+
+// ReplayGuard проверяет, что время подписи запроса не старше окна window,
+// и запоминает одноразовые значения, пока запрос с ними может пройти проверку времени.
+type ReplayGuard struct {
+	window         time.Duration
+	allowUnstamped bool
+	now            func() time.Time
+
+	lock      sync.Mutex
+	seen      map[string]time.Time
+	lastPurge time.Time
+}
+
+// ReplayOption настраивает защиту от повторов.
+type ReplayOption func(g *ReplayGuard)
+
+// WithAllowUnstamped пропускать запросы без метки, так подписывают запросы клиенты предыдущих версий.
+// Такие запросы не защищены от повторов, поэтому по умолчанию они отклоняются.
+func WithAllowUnstamped(allow bool) ReplayOption {
+	return func(g *ReplayGuard) {
+		g.allowUnstamped = allow
+	}
+}
+
+// NewReplayGuard создает защиту от повторов с окном допустимой давности window. Нулевое окно выключает защиту.
+func NewReplayGuard(window time.Duration, opts ...ReplayOption) *ReplayGuard {
+	g := &ReplayGuard{
+		window: window,
+		now:    time.Now,
+		seen:   make(map[string]time.Time),
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// Enable возвращает true, если защита от повторов включена.
+func (g *ReplayGuard) Enable() bool {
+	return g != nil && g.window > 0
+}
+
+// Check проверяет метку запроса и запоминает ее одноразовое значение.
+// Метку нужно проверять только после проверки подписи, иначе неподписанные запросы займут одноразовые значения.
+func (g *ReplayGuard) Check(stamp Stamp) error {
+	if !g.Enable() {
+		return nil
+	}
+
+	if stamp.IsZero() {
+		if g.allowUnstamped {
+			return nil
+		}
+
+		return ErrMissingStamp
+	}
+
+	now := g.now()
+	signedAt := time.Unix(stamp.Timestamp, 0)
+
+	if signedAt.Before(now.Add(-g.window)) || signedAt.After(now.Add(g.window)) {
+		return ErrStaleRequest
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.purge(now)
+
+	if _, ok := g.seen[stamp.Nonce]; ok {
+		return ErrReplayedRequest
+	}
+
+	// После этого момента запрос с той же меткой будет отклонен проверкой времени.
+	g.seen[stamp.Nonce] = signedAt.Add(g.window)
+
+	return nil
+}
+
+// Метод purge удаляет одноразовые значения с истекшим окном. Выполняется не чаще одного раза за окно.
+func (g *ReplayGuard) purge(now time.Time) {
+	if now.Sub(g.lastPurge) < g.window {
+		return
+	}
+
+	for nonce, expiresAt := range g.seen {
+		if now.After(expiresAt) {
+			delete(g.seen, nonce)
+		}
+	}
+
+	g.lastPurge = now
+}
@@ -0,0 +1,95 @@
+package signature_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/securety/signature"
+)
+
+func TestParseStamp(t *testing.T) {
+	tests := []struct {
+		name      string
+		timestamp string
+		nonce     string
+		want      signature.Stamp
+		wantErr   bool
+	}{
+		{name: "legacy request", want: signature.Stamp{}},
+		{name: "valid", timestamp: "1700000000", nonce: "abc", want: signature.Stamp{Timestamp: 1700000000, Nonce: "abc"}},
+		{name: "without nonce", timestamp: "1700000000", wantErr: true},
+		{name: "without timestamp", nonce: "abc", wantErr: true},
+		{name: "invalid timestamp", timestamp: "yesterday", nonce: "abc", wantErr: true},
+		{name: "too long nonce", timestamp: "1700000000", nonce: string(make([]byte, 65)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stamp, err := signature.ParseStamp(tt.timestamp, tt.nonce)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, signature.ErrInvalidStamp)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, stamp)
+		})
+	}
+}
+
+func TestStamp_Payload(t *testing.T) {
+	m := signature.NewSignManager("secret")
+	stamp := signature.Stamp{Timestamp: 1700000000, Nonce: "abc"}
+
+	assert.Equal(t, []byte("1700000000\nabc\nbody"), stamp.Payload([]byte("body")))
+	assert.Equal(t, []byte("body"), signature.Stamp{}.Payload([]byte("body")))
+
+	sign := m.Sing(stamp.Payload([]byte("body")))
+	ok, _ := m.Verify(signature.Stamp{Timestamp: 1700000001, Nonce: "abc"}.Payload([]byte("body")), sign)
+	assert.False(t, ok, "changed timestamp must invalidate signature")
+}
+
+func TestReplayGuard_Check(t *testing.T) {
+	now := time.Now().Unix()
+
+	t.Run("disabled", func(t *testing.T) {
+		guard := signature.NewReplayGuard(0)
+
+		assert.False(t, guard.Enable())
+		assert.NoError(t, guard.Check(signature.Stamp{}))
+		assert.NoError(t, guard.Check(signature.Stamp{Timestamp: 1, Nonce: "abc"}))
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		guard := signature.NewReplayGuard(time.Minute)
+
+		assert.ErrorIs(t, guard.Check(signature.Stamp{}), signature.ErrMissingStamp)
+		assert.ErrorIs(t, guard.Check(signature.Stamp{Timestamp: now - 120, Nonce: "old"}), signature.ErrStaleRequest)
+		assert.ErrorIs(t, guard.Check(signature.Stamp{Timestamp: now + 120, Nonce: "future"}), signature.ErrStaleRequest)
+
+		assert.NoError(t, guard.Check(signature.Stamp{Timestamp: now, Nonce: "abc"}))
+		assert.ErrorIs(t, guard.Check(signature.Stamp{Timestamp: now, Nonce: "abc"}), signature.ErrReplayedRequest)
+		assert.NoError(t, guard.Check(signature.Stamp{Timestamp: now, Nonce: "def"}))
+	})
+
+	t.Run("unstamped allowed", func(t *testing.T) {
+		guard := signature.NewReplayGuard(time.Minute, signature.WithAllowUnstamped(true))
+
+		assert.NoError(t, guard.Check(signature.Stamp{}))
+		assert.ErrorIs(t, guard.Check(signature.Stamp{Timestamp: now - 120, Nonce: "old"}), signature.ErrStaleRequest)
+		assert.NoError(t, guard.Check(signature.Stamp{Timestamp: now, Nonce: "abc"}))
+		assert.ErrorIs(t, guard.Check(signature.Stamp{Timestamp: now, Nonce: "abc"}), signature.ErrReplayedRequest)
+	})
+
+	t.Run("through sign manager", func(t *testing.T) {
+		m := signature.NewSignManager("secret", signature.WithReplayGuard(signature.NewReplayGuard(time.Minute)))
+		stamp := signature.NewStamp()
+
+		assert.NoError(t, m.CheckReplay(stamp))
+		assert.ErrorIs(t, m.CheckReplay(stamp), signature.ErrReplayedRequest)
+		assert.NoError(t, signature.NewSignManager("secret").CheckReplay(stamp))
+	})
+}
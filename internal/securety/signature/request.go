@@ -0,0 +1,26 @@
+package signature
+
+import "net/http"
+
+// RequestPayload возвращает данные HTTP запроса для подписи: метод, путь с параметрами запроса
+// и тело вместе с меткой stamp (см. [Stamp.Payload]), каждое с новой строки.
+//
+// Метод и путь подписываются, потому что у запросов вида POST /update/gauge/{name}/{value} нет тела:
+// подпись одного тела не защищает такие запросы от подмены пути.
+func RequestPayload(method, uri string, stamp Stamp, body []byte) []byte {
+	data := stamp.Payload(body)
+
+	payload := make([]byte, 0, len(method)+len(uri)+2+len(data))
+	payload = append(payload, method...)
+	payload = append(payload, '\n')
+	payload = append(payload, uri...)
+	payload = append(payload, '\n')
+
+	return append(payload, data...)
+}
+
+// MethodPayload возвращает данные gRPC вызова для подписи. Вызов gRPC передается запросом POST на путь
+// с полным именем метода, поэтому данные совпадают с [RequestPayload] для такого запроса.
+func MethodPayload(fullMethod string, stamp Stamp, body []byte) []byte {
+	return RequestPayload(http.MethodPost, fullMethod, stamp, body)
+}
@@ -7,33 +7,83 @@ import (
 )
 
 // SignManager обслуживает создание и проверку подписи.
+//
+// Клиент подписывает запросы своим секретом и, если ему выдан ключ из файла ключей, передает идентификатор ключа
+// (см. [WithKeyID]). Сервер проверяет подпись секретом ключа с переданным идентификатором из [KeyStore],
+// а запросы без идентификатора — общим секретом, если он задан.
 type SignManager struct {
 	secretKey []byte
 	enable    bool
+	keyID     string
+	keys      *KeyStore
+	guard     *ReplayGuard
+}
+
+// Option настраивает менеджер.
+type Option func(m *SignManager)
+
+// WithReplayGuard включает проверку времени подписи и одноразового значения запросов, см. [SignManager.CheckReplay].
+func WithReplayGuard(guard *ReplayGuard) Option {
+	return func(m *SignManager) {
+		m.guard = guard
+	}
+}
+
+// WithKeyID задает идентификатор ключа клиента, который передается вместе с подписью в заголовке [HeaderKeyID].
+func WithKeyID(keyID string) Option {
+	return func(m *SignManager) {
+		m.keyID = keyID
+	}
+}
+
+// WithKeyStore включает на сервере проверку подписей ключами агентов из хранилища keys.
+func WithKeyStore(keys *KeyStore) Option {
+	return func(m *SignManager) {
+		m.keys = keys
+	}
 }
 
 // NewSignManager создает менеджер.
-func NewSignManager(secretKey string) *SignManager {
-	return &SignManager{
+func NewSignManager(secretKey string, opts ...Option) *SignManager {
+	m := &SignManager{
 		secretKey: []byte(secretKey),
-		enable:    len(secretKey) > 0,
 	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.enable = len(secretKey) > 0 || m.keys != nil
+
+	return m
 }
 
 // Sing создает подпись.
 func (m SignManager) Sing(data []byte) string {
-	if !m.enable {
+	if !m.enable || len(m.secretKey) == 0 {
 		return ""
 	}
 
-	return hex.EncodeToString(m.new(data))
+	return hex.EncodeToString(m.new(m.secretKey, data))
+}
+
+// KeyID возвращает идентификатор ключа клиента или пустую строку, если используется общий секрет.
+func (m SignManager) KeyID() string {
+	return m.keyID
 }
 
-// Verify проверяет переданную подпись.
+// Verify проверяет переданную подпись общим секретом.
 // Первым параметром возвращает TRUE, если данные подписаны верно, иначе FALSE.
 // Вторым параметров подпись проверенных данных.
 func (m SignManager) Verify(data []byte, signature string) (bool, string) {
-	if !m.enable {
+	return m.VerifyKey("", data, signature)
+}
+
+// VerifyKey проверяет подпись данных ключом keyID. Если keyID пустой, подпись проверяется общим секретом.
+// Подпись неизвестным или отозванным ключом считается неверной.
+func (m SignManager) VerifyKey(keyID string, data []byte, signature string) (bool, string) {
+	secret, ok := m.secret(keyID)
+	if !ok {
 		return false, ""
 	}
 
@@ -42,19 +92,55 @@ func (m SignManager) Verify(data []byte, signature string) (bool, string) {
 		return false, ""
 	}
 
-	dataSign := m.new(data)
+	dataSign := m.new(secret, data)
 
 	return hmac.Equal(dataSign, sign), hex.EncodeToString(dataSign)
 }
 
+// SignKey подписывает данные секретом ключа keyID, а если keyID пустой — общим секретом.
+// Сервер так подписывает ответ тем же ключом, которым был подписан запрос.
+// Возвращает пустую строку, если подписывание выключено или ключ неизвестен.
+func (m SignManager) SignKey(keyID string, data []byte) string {
+	secret, ok := m.secret(keyID)
+	if !ok {
+		return ""
+	}
+
+	return hex.EncodeToString(m.new(secret, data))
+}
+
+// CheckReplay проверяет, что запрос с меткой stamp не устарел и не был принят ранее.
+// Если защита от повторов не включена, проверка всегда проходит.
+func (m SignManager) CheckReplay(stamp Stamp) error {
+	return m.guard.Check(stamp)
+}
+
 // Enable возвращает true, если включено подписывание запросов.
 func (m SignManager) Enable() bool {
 	return m.enable
 }
 
+// Метод secret возвращает секрет ключа keyID или общий секрет, если keyID пустой.
+// Вторым параметром возвращает FALSE, если подписывание выключено, ключ неизвестен или отозван.
+func (m SignManager) secret(keyID string) ([]byte, bool) {
+	if !m.enable {
+		return nil, false
+	}
+
+	if keyID == "" {
+		return m.secretKey, len(m.secretKey) > 0
+	}
+
+	if m.keys == nil {
+		return nil, false
+	}
+
+	return m.keys.Secret(keyID)
+}
+
 // Метод new создает HMAC hash.
-func (m SignManager) new(data []byte) []byte {
-	h := hmac.New(sha256.New, m.secretKey)
+func (m SignManager) new(secret, data []byte) []byte {
+	h := hmac.New(sha256.New, secret)
 	h.Write(data)
 	return h.Sum(nil)
 }
@@ -30,3 +30,19 @@ func TestSignManager(t *testing.T) {
 		assert.Empty(t, hash)
 	})
 }
+
+func TestSignManager_SignKey(t *testing.T) {
+	m := signature.NewSignManager("secret")
+
+	assert.Equal(t, m.Sing([]byte("some data")), m.SignKey("", []byte("some data")))
+	assert.Empty(t, m.SignKey("unknown", []byte("some data")))
+	assert.Empty(t, signature.NewSignManager("").SignKey("", []byte("some data")))
+}
+
+func TestRequestPayload(t *testing.T) {
+	stamp := signature.Stamp{Timestamp: 1700000000, Nonce: "abc"}
+
+	assert.Equal(t, []byte("POST\n/update/gauge/Alloc/1\n1700000000\nabc\n"),
+		signature.RequestPayload("POST", "/update/gauge/Alloc/1", stamp, nil))
+	assert.Equal(t, []byte("POST\n/updates/\nbody"), signature.RequestPayload("POST", "/updates/", signature.Stamp{}, []byte("body")))
+}
@@ -0,0 +1,118 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+// Usage назначение выпускаемого сертификата.
+type Usage int
+
+const (
+	// UsageServer сертификат сервера.
+	UsageServer Usage = iota
+	// UsageClient сертификат клиента для mTLS.
+	UsageClient
+)
+
+// Certificate сертификат и его приватный ключ.
+type Certificate struct {
+	// CertPEM сертификат в формате PEM.
+	CertPEM []byte
+	// KeyPEM приватный ключ ECDSA P-256 в формате PEM.
+	KeyPEM []byte
+
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// NewCA создает самоподписанный корневой сертификат, которым затем выпускаются сертификаты сервера и клиентов.
+func NewCA(commonName string, validFor time.Duration) (*Certificate, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	return create(template, nil, validFor)
+}
+
+// Issue выпускает сертификат, подписанный корневым сертификатом ca.
+// В hosts передаются имена и IP адреса, для которых действителен сертификат сервера.
+func (ca *Certificate) Issue(commonName string, hosts []string, usage Usage, validFor time.Duration) (*Certificate, error) {
+	template := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: commonName},
+		KeyUsage: x509.KeyUsageDigitalSignature,
+	}
+
+	switch usage {
+	case UsageServer:
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	case UsageClient:
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	default:
+		return nil, fmt.Errorf("unknown certificate usage: %d", usage)
+	}
+
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	return create(template, ca, validFor)
+}
+
+// Функция create создает сертификат по шаблону. Если parent не задан, сертификат самоподписанный.
+func create(template *x509.Certificate, parent *Certificate, validFor time.Duration) (*Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	template.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	template.NotBefore = time.Now().Add(-time.Minute)
+	template.NotAfter = template.NotBefore.Add(validFor)
+
+	issuer, issuerKey := template, key
+	if parent != nil {
+		issuer, issuerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	return &Certificate{
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		cert:    cert,
+		key:     key,
+	}, nil
+}
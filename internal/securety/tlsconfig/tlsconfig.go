@@ -0,0 +1,103 @@
+// Package tlsconfig собирает настройки TLS сервера и клиента из файлов сертификатов в формате PEM.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrIncompleteConfig ошибка, если задана только часть файлов, необходимых для TLS.
+var ErrIncompleteConfig = errors.New("incomplete TLS config")
+
+// NewServer возвращает настройки TLS сервера с сертификатом certFile и ключом keyFile.
+// Если задан clientCAFile, сервер требует сертификат клиента, подписанный одним из сертификатов этого файла (mTLS).
+// Если сертификат и ключ не заданы, TLS выключен и возвращается nil.
+func NewServer(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" {
+		if clientCAFile != "" {
+			return nil, fmt.Errorf("%w: client CA requires server certificate and key", ErrIncompleteConfig)
+		}
+
+		return nil, nil
+	}
+
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("%w: both certificate and key are required", ErrIncompleteConfig)
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if clientCAFile != "" {
+		config.ClientCAs, err = loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+// NewClient возвращает настройки TLS клиента.
+// Сертификат сервера проверяется по сертификатам из caFile, а если он не задан, по системным корневым сертификатам.
+// Если заданы certFile и keyFile, клиент предъявляет серверу свой сертификат (mTLS).
+// TLS включается флагом enabled или любым из заданных файлов, иначе TLS выключен и возвращается nil.
+func NewClient(enabled bool, caFile, certFile, keyFile string) (*tls.Config, error) {
+	if !enabled && caFile == "" && certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("%w: both client certificate and key are required", ErrIncompleteConfig)
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if caFile != "" {
+		var err error
+
+		config.RootCAs, err = loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificates: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no CA certificates found in %s", path)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,237 @@
+package tlsconfig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/securety/tlsconfig"
+)
+
+func TestNewServer(t *testing.T) {
+	files := writeCerts(t)
+
+	tests := []struct {
+		name         string
+		cert, key    string
+		clientCA     string
+		wantNil      bool
+		wantErr      error
+		wantClientCA bool
+	}{
+		{name: "disabled", wantNil: true},
+		{name: "tls", cert: files.serverCert, key: files.serverKey},
+		{name: "mtls", cert: files.serverCert, key: files.serverKey, clientCA: files.ca, wantClientCA: true},
+		{name: "without key", cert: files.serverCert, wantErr: tlsconfig.ErrIncompleteConfig},
+		{name: "client CA without certificate", clientCA: files.ca, wantErr: tlsconfig.ErrIncompleteConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := tlsconfig.NewServer(tt.cert, tt.key, tt.clientCA)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			if tt.wantNil {
+				assert.Nil(t, config)
+				return
+			}
+
+			require.NotNil(t, config)
+			assert.Len(t, config.Certificates, 1)
+			assert.Equal(t, tt.wantClientCA, config.ClientAuth == tls.RequireAndVerifyClientCert)
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	files := writeCerts(t)
+
+	tests := []struct {
+		name        string
+		enabled     bool
+		ca          string
+		cert, key   string
+		wantNil     bool
+		wantErr     error
+		wantRootCAs bool
+	}{
+		{name: "disabled", wantNil: true},
+		{name: "system CAs", enabled: true},
+		{name: "custom CA", ca: files.ca, wantRootCAs: true},
+		{name: "mtls", enabled: true, ca: files.ca, cert: files.clientCert, key: files.clientKey, wantRootCAs: true},
+		{name: "without key", enabled: true, cert: files.clientCert, wantErr: tlsconfig.ErrIncompleteConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := tlsconfig.NewClient(tt.enabled, tt.ca, tt.cert, tt.key)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			if tt.wantNil {
+				assert.Nil(t, config)
+				return
+			}
+
+			require.NotNil(t, config)
+			assert.Equal(t, tt.wantRootCAs, config.RootCAs != nil)
+		})
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	files := writeCerts(t)
+
+	serverConfig, err := tlsconfig.NewServer(files.serverCert, files.serverKey, files.ca)
+	require.NoError(t, err)
+
+	t.Run("client with certificate", func(t *testing.T) {
+		clientConfig, err := tlsconfig.NewClient(false, files.ca, files.clientCert, files.clientKey)
+		require.NoError(t, err)
+
+		assert.NoError(t, handshake(serverConfig, clientConfig))
+	})
+
+	t.Run("client without certificate", func(t *testing.T) {
+		clientConfig, err := tlsconfig.NewClient(false, files.ca, "", "")
+		require.NoError(t, err)
+
+		assert.Error(t, handshake(serverConfig, clientConfig))
+	})
+
+	t.Run("unknown server CA", func(t *testing.T) {
+		clientConfig, err := tlsconfig.NewClient(false, "", files.clientCert, files.clientKey)
+		require.NoError(t, err)
+
+		assert.Error(t, handshake(serverConfig, clientConfig))
+	})
+}
+
+// handshake выполняет TLS рукопожатие и возвращает ошибку сервера.
+func handshake(serverConfig, clientConfig *tls.Config) error {
+	serverConn, clientConn := net.Pipe()
+	defer func() {
+		_ = serverConn.Close()
+		_ = clientConn.Close()
+	}()
+
+	clientConfig = clientConfig.Clone()
+	clientConfig.ServerName = "localhost"
+
+	clientErr := make(chan error, 1)
+	go func() {
+		client := tls.Client(clientConn, clientConfig)
+		err := client.Handshake()
+		if err == nil {
+			// Сервер проверяет сертификат клиента после того, как клиент завершил рукопожатие,
+			// поэтому ошибку сервера клиент увидит только при чтении.
+			_, err = client.Read(make([]byte, 1))
+		}
+		clientErr <- err
+		_ = clientConn.Close()
+	}()
+
+	server := tls.Server(serverConn, serverConfig)
+	err := server.Handshake()
+	if err == nil {
+		_ = server.Close()
+	}
+	_ = serverConn.Close()
+	<-clientErr
+
+	return err
+}
+
+type certFiles struct {
+	ca                    string
+	serverCert, serverKey string
+	clientCert, clientKey string
+}
+
+// writeCerts создает CA, сертификат сервера для localhost и сертификат клиента, подписанные этим CA.
+func writeCerts(t *testing.T) certFiles {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	files := certFiles{ca: filepath.Join(dir, "ca.pem")}
+	writePEM(t, files.ca, "CERTIFICATE", caDER)
+
+	issue := func(name string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{"localhost"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+
+		certPath := filepath.Join(dir, name+".pem")
+		keyPath := filepath.Join(dir, name+"-key.pem")
+		writePEM(t, certPath, "CERTIFICATE", der)
+		writePEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+
+		return certPath, keyPath
+	}
+
+	files.serverCert, files.serverKey = issue("server", 2, x509.ExtKeyUsageServerAuth)
+	files.clientCert, files.clientKey = issue("client", 3, x509.ExtKeyUsageClientAuth)
+
+	return files
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
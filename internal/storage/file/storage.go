@@ -6,14 +6,18 @@ import (
 	"fmt"
 	"os"
 	"sync"
+
+	"github.com/bjlag/go-metrics/internal/model"
 )
 
 // Metric модель описывает метрику, которая будет записана в файл.
 type Metric struct {
-	ID    string   `json:"id"`
-	MType string   `json:"type"`
-	Delta *int64   `json:"delta,omitempty"`
-	Value *float64 `json:"value,omitempty"`
+	ID        string           `json:"id"`
+	MType     string           `json:"type"`
+	Delta     *int64           `json:"delta,omitempty"`
+	Value     *float64         `json:"value,omitempty"`
+	Histogram *model.Histogram `json:"histogram,omitempty"`
+	Labels    model.Labels     `json:"labels,omitempty"`
 }
 
 // Storage обслуживает запись метрик в файл.
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+// ring кольцевой буфер истории значений одной метрики.
+// Буфер растет по мере поступления значений до limit, после чего новое значение затирает самое старое.
+type ring struct {
+	buf   []storage.Sample
+	limit int
+	start int
+	size  int
+}
+
+func newRing(limit int) *ring {
+	return &ring{
+		limit: limit,
+	}
+}
+
+// push добавляет значение в буфер и удаляет значения старше before.
+func (r *ring) push(sample storage.Sample, before time.Time) {
+	for r.size > 0 && r.buf[r.start].Timestamp.Before(before) {
+		r.start = (r.start + 1) % len(r.buf)
+		r.size--
+	}
+
+	if r.size == len(r.buf) {
+		if len(r.buf) >= r.limit {
+			r.buf[r.start] = sample
+			r.start = (r.start + 1) % len(r.buf)
+			return
+		}
+
+		r.grow()
+	}
+
+	r.buf[(r.start+r.size)%len(r.buf)] = sample
+	r.size++
+}
+
+// grow увеличивает буфер, но не больше limit. Значения переносятся в начало нового буфера в порядке записи.
+func (r *ring) grow() {
+	capacity := min(max(2*len(r.buf), 1), r.limit)
+
+	buf := make([]storage.Sample, capacity)
+	for i := 0; i < r.size; i++ {
+		buf[i] = r.buf[(r.start+i)%len(r.buf)]
+	}
+
+	r.buf = buf
+	r.start = 0
+}
+
+// rangeSamples возвращает значения за период [from, to] в порядке записи.
+func (r *ring) rangeSamples(from, to time.Time) []storage.Sample {
+	samples := make([]storage.Sample, 0, r.size)
+
+	for i := 0; i < r.size; i++ {
+		s := r.buf[(r.start+i)%len(r.buf)]
+		if s.Timestamp.Before(from) || s.Timestamp.After(to) {
+			continue
+		}
+
+		samples = append(samples, s)
+	}
+
+	return samples
+}
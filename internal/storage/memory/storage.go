@@ -2,31 +2,86 @@ package memory
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
+	"github.com/bjlag/go-metrics/internal/model"
 	"github.com/bjlag/go-metrics/internal/storage"
 )
 
 const (
 	initSize = 100
+
+	defaultHistorySize      = 3600
+	defaultHistoryRetention = time.Hour
 )
 
 // Storage обслуживает in-memory хранилище.
 type Storage struct {
-	lock     sync.RWMutex
-	gauges   storage.Gauges
-	counters storage.Counters
+	lock       sync.RWMutex
+	gauges     storage.Gauges
+	counters   storage.Counters
+	histograms storage.Histograms
+
+	historySize      int
+	historyRetention time.Duration
+	gaugeSamples     map[string]*ring
+	counterSamples   map[string]*ring
+	now              func() time.Time
+}
+
+// Option настраивает хранилище.
+type Option func(s *Storage)
+
+// WithHistorySize задает максимальное количество значений в истории одной метрики.
+func WithHistorySize(size int) Option {
+	return func(s *Storage) {
+		if size > 0 {
+			s.historySize = size
+		}
+	}
+}
+
+// WithRetention задает, сколько времени хранится история значений метрик.
+func WithRetention(retention time.Duration) Option {
+	return func(s *Storage) {
+		if retention > 0 {
+			s.historyRetention = retention
+		}
+	}
+}
+
+// WithClock задает функцию получения текущего времени, которым помечаются значения в истории.
+func WithClock(now func() time.Time) Option {
+	return func(s *Storage) {
+		s.now = now
+	}
 }
 
 // NewStorage создает хранилище.
-func NewStorage() *Storage {
+func NewStorage(opts ...Option) *Storage {
 	gauges := make(storage.Gauges, initSize)
 	counters := make(storage.Counters, initSize)
+	histograms := make(storage.Histograms)
 
-	return &Storage{
-		gauges:   gauges,
-		counters: counters,
+	s := &Storage{
+		gauges:     gauges,
+		counters:   counters,
+		histograms: histograms,
+
+		historySize:      defaultHistorySize,
+		historyRetention: defaultHistoryRetention,
+		gaugeSamples:     make(map[string]*ring, initSize),
+		counterSamples:   make(map[string]*ring, initSize),
+		now:              time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
 func (s *Storage) GetAllGauges(_ context.Context) storage.Gauges {
@@ -54,14 +109,18 @@ func (s *Storage) SetGauge(_ context.Context, id string, value float64) {
 	defer s.lock.Unlock()
 
 	s.gauges[id] = value
+	s.pushSample(s.gaugeSamples, id, value, s.now())
 }
 
 func (s *Storage) SetGauges(_ context.Context, gauges []storage.Gauge) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
+	now := s.now()
 	for _, gauge := range gauges {
-		s.gauges[gauge.ID] = gauge.Value
+		key := gauge.Key()
+		s.gauges[key] = gauge.Value
+		s.pushSample(s.gaugeSamples, key, gauge.Value, now)
 	}
 
 	return nil
@@ -83,28 +142,128 @@ func (s *Storage) AddCounter(_ context.Context, id string, value int64) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	currentValue, ok := s.counters[id]
+	s.counters[id] += value
+	s.pushSample(s.counterSamples, id, float64(s.counters[id]), s.now())
+}
+
+func (s *Storage) AddCounters(_ context.Context, counters []storage.Counter) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	for _, counter := range counters {
+		key := counter.Key()
+		s.counters[key] += counter.Value
+		s.pushSample(s.counterSamples, key, float64(s.counters[key]), now)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetAllHistograms(_ context.Context) storage.Histograms {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	histograms := make(storage.Histograms, len(s.histograms))
+	for id, value := range s.histograms {
+		histograms[id] = value.Clone()
+	}
+
+	return histograms
+}
+
+func (s *Storage) GetHistogram(_ context.Context, id string) (model.Histogram, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	value, ok := s.histograms[id]
 	if !ok {
-		s.counters[id] = value
-		return
+		return model.Histogram{}, storage.NewMetricNotFoundError(model.TypeHistogram, id, nil)
 	}
 
-	s.counters[id] = currentValue + value
+	return value.Clone(), nil
 }
 
-func (s *Storage) AddCounters(_ context.Context, counters []storage.Counter) error {
+func (s *Storage) AddHistograms(_ context.Context, histograms []storage.Histogram) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	for _, counter := range counters {
-		currentValue, ok := s.counters[counter.ID]
-		if !ok {
-			s.counters[counter.ID] = counter.Value
+	for _, histogram := range histograms {
+		key := histogram.Key()
+		if current, ok := s.histograms[key]; ok {
+			s.histograms[key] = current.Merge(histogram.Value)
 			continue
 		}
 
-		s.counters[counter.ID] = currentValue + counter.Value
+		s.histograms[key] = histogram.Value.Clone()
+	}
+
+	return nil
+}
+
+func (s *Storage) Delete(_ context.Context, kind, id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var ok bool
+
+	switch kind {
+	case model.TypeGauge:
+		_, ok = s.gauges[id]
+		delete(s.gauges, id)
+		delete(s.gaugeSamples, id)
+	case model.TypeCounter:
+		_, ok = s.counters[id]
+		delete(s.counters, id)
+		delete(s.counterSamples, id)
+	case model.TypeHistogram:
+		_, ok = s.histograms[id]
+		delete(s.histograms, id)
+	default:
+		return fmt.Errorf("unknown metric type: %s", kind)
+	}
+
+	if !ok {
+		return storage.NewMetricNotFoundError(kind, id, nil)
 	}
 
 	return nil
 }
+
+func (s *Storage) GetSamples(_ context.Context, kind, id string, from, to time.Time) ([]storage.Sample, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var samples map[string]*ring
+
+	switch kind {
+	case model.TypeGauge:
+		samples = s.gaugeSamples
+	case model.TypeCounter:
+		samples = s.counterSamples
+	default:
+		return nil, fmt.Errorf("unknown metric type: %s", kind)
+	}
+
+	r, ok := samples[id]
+	if !ok {
+		return nil, storage.NewMetricNotFoundError(kind, id, nil)
+	}
+
+	if retentionFrom := s.now().Add(-s.historyRetention); from.Before(retentionFrom) {
+		from = retentionFrom
+	}
+
+	return r.rangeSamples(from, to), nil
+}
+
+// Метод pushSample добавляет значение в историю метрики. Вызывается под блокировкой на запись.
+func (s *Storage) pushSample(samples map[string]*ring, id string, value float64, now time.Time) {
+	r, ok := samples[id]
+	if !ok {
+		r = newRing(s.historySize)
+		samples[id] = r
+	}
+
+	r.push(storage.Sample{Timestamp: now, Value: value}, now.Add(-s.historyRetention))
+}
@@ -2,10 +2,14 @@ package memory_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/bjlag/go-metrics/internal/model"
 	"github.com/bjlag/go-metrics/internal/storage"
 	"github.com/bjlag/go-metrics/internal/storage/memory"
 )
@@ -185,3 +189,190 @@ func TestStorage_Counters(t *testing.T) {
 	assert.Equal(t, int64(4), c1)
 	assert.Equal(t, int64(5), c2)
 }
+
+func TestStorage_CountersWithLabels(t *testing.T) {
+	s := memory.NewStorage()
+	_ = s.AddCounters(context.Background(), []storage.Counter{
+		{
+			ID:     "requests",
+			Labels: model.Labels{"code": "200"},
+			Value:  1,
+		},
+		{
+			ID:     "requests",
+			Labels: model.Labels{"code": "500"},
+			Value:  2,
+		},
+		{
+			ID:     "requests",
+			Labels: model.Labels{"code": "200"},
+			Value:  3,
+		},
+	})
+
+	c200, err := s.GetCounter(context.Background(), `requests{code="200"}`)
+	assert.Nil(t, err)
+	c500, err := s.GetCounter(context.Background(), `requests{code="500"}`)
+	assert.Nil(t, err)
+	_, err = s.GetCounter(context.Background(), "requests")
+	assert.Error(t, err)
+
+	assert.Equal(t, int64(4), c200)
+	assert.Equal(t, int64(2), c500)
+}
+
+func TestStorage_AddHistograms(t *testing.T) {
+	s := memory.NewStorage()
+	_ = s.AddHistograms(context.Background(), []storage.Histogram{
+		{
+			ID:    "latency",
+			Value: model.Histogram{Bounds: []float64{0.1}, Counts: []uint64{1, 2}, Sum: 1.5, Count: 3},
+		},
+		{
+			ID:    "latency",
+			Value: model.Histogram{Bounds: []float64{0.1}, Counts: []uint64{1, 0}, Sum: 0.05, Count: 1},
+		},
+		{
+			ID:    "size",
+			Value: model.Histogram{Bounds: []float64{10}, Counts: []uint64{1, 0}, Sum: 5, Count: 1},
+		},
+		{
+			ID:    "size",
+			Value: model.Histogram{Bounds: []float64{10, 100}, Counts: []uint64{0, 1, 0}, Sum: 50, Count: 1},
+		},
+	})
+
+	latency, err := s.GetHistogram(context.Background(), "latency")
+	assert.Nil(t, err)
+	assert.Equal(t, model.Histogram{Bounds: []float64{0.1}, Counts: []uint64{2, 2}, Sum: 1.55, Count: 4}, latency)
+
+	size, err := s.GetHistogram(context.Background(), "size")
+	assert.Nil(t, err)
+	assert.Equal(t, model.Histogram{Bounds: []float64{10, 100}, Counts: []uint64{0, 1, 0}, Sum: 50, Count: 1}, size)
+
+	_, err = s.GetHistogram(context.Background(), "unknown")
+	assert.Error(t, err)
+}
+
+func TestStorage_Delete(t *testing.T) {
+	ctx := context.Background()
+
+	s := memory.NewStorage()
+	s.SetGauge(ctx, "Alloc", 1)
+	s.AddCounter(ctx, "PollCount", 1)
+	s.AddCounter(ctx, `PollCount{host="alpha"}`, 1)
+
+	err := s.Delete(ctx, model.TypeCounter, "PollCount")
+	assert.NoError(t, err)
+
+	_, err = s.GetCounter(ctx, "PollCount")
+	assert.Error(t, err)
+	_, err = s.GetSamples(ctx, model.TypeCounter, "PollCount", time.Time{}, time.Now())
+	assert.Error(t, err)
+
+	c, err := s.GetCounter(ctx, `PollCount{host="alpha"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	err = s.Delete(ctx, model.TypeCounter, "Alloc")
+	var notFoundErr *storage.NotFoundError
+	assert.ErrorAs(t, err, &notFoundErr)
+
+	err = s.Delete(ctx, "unknown", "Alloc")
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &notFoundErr))
+
+	g, err := s.GetGauge(ctx, "Alloc")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), g)
+}
+
+func TestStorage_GetSamples(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now()
+	now := start
+
+	s := memory.NewStorage(
+		memory.WithHistorySize(3),
+		memory.WithRetention(time.Hour),
+		memory.WithClock(func() time.Time { return now }),
+	)
+
+	for i := 1; i <= 4; i++ {
+		now = start.Add(time.Duration(i) * time.Minute)
+		s.SetGauge(ctx, "gauge", float64(i))
+		s.AddCounter(ctx, "counter", int64(i))
+	}
+
+	t.Run("gauge ring buffer keeps last values", func(t *testing.T) {
+		samples, err := s.GetSamples(ctx, model.TypeGauge, "gauge", start, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, []storage.Sample{
+			{Timestamp: start.Add(2 * time.Minute), Value: 2},
+			{Timestamp: start.Add(3 * time.Minute), Value: 3},
+			{Timestamp: start.Add(4 * time.Minute), Value: 4},
+		}, samples)
+	})
+
+	t.Run("counter accumulated values in range", func(t *testing.T) {
+		samples, err := s.GetSamples(ctx, model.TypeCounter, "counter", start.Add(3*time.Minute), now)
+		require.NoError(t, err)
+
+		assert.Equal(t, []storage.Sample{
+			{Timestamp: start.Add(3 * time.Minute), Value: 6},
+			{Timestamp: start.Add(4 * time.Minute), Value: 10},
+		}, samples)
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		now = start.Add(time.Hour + 150*time.Second)
+
+		samples, err := s.GetSamples(ctx, model.TypeGauge, "gauge", start, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, []storage.Sample{
+			{Timestamp: start.Add(3 * time.Minute), Value: 3},
+			{Timestamp: start.Add(4 * time.Minute), Value: 4},
+		}, samples)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := s.GetSamples(ctx, model.TypeGauge, "unknown", start, now)
+
+		var notFoundErr *storage.NotFoundError
+		assert.ErrorAs(t, err, &notFoundErr)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := s.GetSamples(ctx, "unknown", "gauge", start, now)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_GetSamples_Growth(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now()
+	now := start
+
+	s := memory.NewStorage(
+		memory.WithHistorySize(5),
+		memory.WithRetention(3*time.Minute),
+		memory.WithClock(func() time.Time { return now }),
+	)
+
+	var want []storage.Sample
+	for i := 1; i <= 8; i++ {
+		now = start.Add(time.Duration(i) * time.Minute)
+		s.SetGauge(ctx, "gauge", float64(i))
+
+		want = append(want, storage.Sample{Timestamp: now, Value: float64(i)})
+		if len(want) > 4 {
+			want = want[1:]
+		}
+
+		samples, err := s.GetSamples(ctx, model.TypeGauge, "gauge", start, now)
+		require.NoError(t, err)
+		assert.Equal(t, want, samples)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jmoiron/sqlx"
 
 	"github.com/bjlag/go-metrics/internal/logger"
@@ -13,31 +16,71 @@ import (
 )
 
 type modelGauge struct {
-	ID    string  `db:"id"`
-	Value float64 `db:"value"`
+	ID     string  `db:"id"`
+	Labels string  `db:"labels"`
+	Value  float64 `db:"value"`
 }
 
 type modelCounter struct {
-	ID    string `db:"id"`
-	Value int64  `db:"value"`
+	ID     string `db:"id"`
+	Labels string `db:"labels"`
+	Value  int64  `db:"value"`
 }
 
+type modelHistogram struct {
+	ID     string
+	Labels string
+	Bounds []float64
+	Counts []int64
+	Sum    float64
+	Count  int64
+}
+
+type modelSample struct {
+	Timestamp time.Time `db:"ts"`
+	Value     float64   `db:"value"`
+}
+
+const defaultHistoryRetention = time.Hour
+
 // Storage обслуживает PostgreSQL хранилище.
 type Storage struct {
 	db  *sqlx.DB
 	log logger.Logger
+
+	historyRetention time.Duration
+}
+
+// Option настраивает хранилище.
+type Option func(s *Storage)
+
+// WithRetention задает, сколько времени хранится история значений метрик.
+func WithRetention(retention time.Duration) Option {
+	return func(s *Storage) {
+		if retention > 0 {
+			s.historyRetention = retention
+		}
+	}
 }
 
 // NewStorage создает хранилище.
-func NewStorage(db *sqlx.DB, log logger.Logger) *Storage {
-	return &Storage{
+func NewStorage(db *sqlx.DB, log logger.Logger, opts ...Option) *Storage {
+	s := &Storage{
 		db:  db,
 		log: log,
+
+		historyRetention: defaultHistoryRetention,
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
 func (s Storage) GetAllGauges(ctx context.Context) storage.Gauges {
-	query := `SELECT id, value FROM gauge_metrics ORDER BY id`
+	query := `SELECT id, labels, value FROM gauge_metrics ORDER BY id, labels`
 
 	stmt, err := s.db.PrepareContext(ctx, query)
 	if err != nil {
@@ -66,7 +109,7 @@ func (s Storage) GetAllGauges(ctx context.Context) storage.Gauges {
 	var models []modelGauge
 	for rows.Next() {
 		var m modelGauge
-		err = rows.Scan(&m.ID, &m.Value)
+		err = rows.Scan(&m.ID, &m.Labels, &m.Value)
 		if err != nil {
 			s.log.WithError(err).Error("Failed to scan")
 			return nil
@@ -77,14 +120,14 @@ func (s Storage) GetAllGauges(ctx context.Context) storage.Gauges {
 
 	gauges := make(storage.Gauges, len(models))
 	for _, gauge := range models {
-		gauges[gauge.ID] = gauge.Value
+		gauges[seriesKey(gauge.ID, gauge.Labels)] = gauge.Value
 	}
 
 	return gauges
 }
 
 func (s Storage) GetAllCounters(ctx context.Context) storage.Counters {
-	query := `SELECT id, value FROM counter_metrics ORDER BY id`
+	query := `SELECT id, labels, value FROM counter_metrics ORDER BY id, labels`
 	stmt, err := s.db.PrepareContext(ctx, query)
 	if err != nil {
 		s.log.WithError(err).Error("Failed to prepare query")
@@ -112,7 +155,7 @@ func (s Storage) GetAllCounters(ctx context.Context) storage.Counters {
 	var models []modelCounter
 	for rows.Next() {
 		var model modelCounter
-		err = rows.Scan(&model.ID, &model.Value)
+		err = rows.Scan(&model.ID, &model.Labels, &model.Value)
 		if err != nil {
 			s.log.WithError(err).Error("Failed to scan")
 			return nil
@@ -123,14 +166,14 @@ func (s Storage) GetAllCounters(ctx context.Context) storage.Counters {
 
 	counters := make(storage.Counters, len(models))
 	for _, counter := range models {
-		counters[counter.ID] = counter.Value
+		counters[seriesKey(counter.ID, counter.Labels)] = counter.Value
 	}
 
 	return counters
 }
 
 func (s Storage) GetGauge(ctx context.Context, id string) (float64, error) {
-	query := `SELECT id, value FROM gauge_metrics WHERE id = $1`
+	query := `SELECT id, value FROM gauge_metrics WHERE id = $1 AND labels = $2`
 	stmt, err := s.db.PrepareContext(ctx, query)
 	if err != nil {
 		s.log.WithError(err).Error("Failed to prepare query")
@@ -141,7 +184,8 @@ func (s Storage) GetGauge(ctx context.Context, id string) (float64, error) {
 	}()
 
 	var m modelGauge
-	row := stmt.QueryRowContext(ctx, id)
+	metricID, labels := splitKey(id)
+	row := stmt.QueryRowContext(ctx, metricID, labels)
 	err = row.Scan(&m.ID, &m.Value)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -157,24 +201,23 @@ func (s Storage) GetGauge(ctx context.Context, id string) (float64, error) {
 
 func (s Storage) SetGauge(ctx context.Context, id string, value float64) {
 	query := `
-		INSERT INTO gauge_metrics (id, value) VALUES ($1, $2)
-		ON CONFLICT (id) DO UPDATE
+		INSERT INTO gauge_metrics (id, labels, value) VALUES ($1, $2, $3)
+		ON CONFLICT (id, labels) DO UPDATE
     		SET value = excluded.value
 	`
 
-	stmt, err := s.db.PrepareContext(ctx, query)
-	if err != nil {
-		s.log.WithError(err).Error("Failed to prepare query")
-		return
-	}
-	defer func() {
-		_ = stmt.Close()
-	}()
+	metricID, labels := splitKey(id)
+	err := s.inTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, query, metricID, labels, value)
+		if err != nil {
+			s.log.WithError(err).Error("Error setting gauge")
+			return err
+		}
 
-	_, err = stmt.ExecContext(ctx, id, value)
+		return s.addSamples(ctx, tx, model.TypeGauge, []string{id})
+	})
 	if err != nil {
-		s.log.WithError(err).Error("Error setting gauge")
-		return
+		s.log.WithError(err).Error("Failed to set gauge")
 	}
 }
 
@@ -184,10 +227,11 @@ func (s Storage) SetGauges(ctx context.Context, gauges []storage.Gauge) error {
 	}
 
 	models := make(map[string]modelGauge, len(gauges))
-	for _, counter := range gauges {
-		models[counter.ID] = modelGauge{
-			ID:    counter.ID,
-			Value: counter.Value,
+	for _, gauge := range gauges {
+		models[gauge.Key()] = modelGauge{
+			ID:     gauge.ID,
+			Labels: gauge.Labels.String(),
+			Value:  gauge.Value,
 		}
 	}
 
@@ -197,22 +241,29 @@ func (s Storage) SetGauges(ctx context.Context, gauges []storage.Gauge) error {
 	}
 
 	query := `
-		INSERT INTO gauge_metrics (id, value) VALUES (:id, :value)
-		ON CONFLICT (id) DO UPDATE
+		INSERT INTO gauge_metrics (id, labels, value) VALUES (:id, :labels, :value)
+		ON CONFLICT (id, labels) DO UPDATE
     		SET value = excluded.value
 	`
 
-	_, err := s.db.NamedExecContext(ctx, query, rows)
-	if err != nil {
-		s.log.WithError(err).Error("Error setting gauges")
-		return err
+	keys := make([]string, 0, len(rows))
+	for _, m := range rows {
+		keys = append(keys, seriesKey(m.ID, m.Labels))
 	}
 
-	return nil
+	return s.inTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.NamedExecContext(ctx, query, rows)
+		if err != nil {
+			s.log.WithError(err).Error("Error setting gauges")
+			return err
+		}
+
+		return s.addSamples(ctx, tx, model.TypeGauge, keys)
+	})
 }
 
 func (s Storage) GetCounter(ctx context.Context, id string) (int64, error) {
-	query := `SELECT id, value FROM counter_metrics WHERE id = $1`
+	query := `SELECT id, value FROM counter_metrics WHERE id = $1 AND labels = $2`
 	stmt, err := s.db.PrepareContext(ctx, query)
 	if err != nil {
 		s.log.WithError(err).Error("Failed to prepare query")
@@ -223,7 +274,8 @@ func (s Storage) GetCounter(ctx context.Context, id string) (int64, error) {
 	}()
 
 	var m modelCounter
-	row := stmt.QueryRowContext(ctx, id)
+	metricID, labels := splitKey(id)
+	row := stmt.QueryRowContext(ctx, metricID, labels)
 	err = row.Scan(&m.ID, &m.Value)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -239,24 +291,23 @@ func (s Storage) GetCounter(ctx context.Context, id string) (int64, error) {
 
 func (s Storage) AddCounter(ctx context.Context, id string, value int64) {
 	query := `
-		INSERT INTO counter_metrics VALUES ($1, $2)
-		ON CONFLICT (id) DO UPDATE
-    		SET value = counter_metrics.value + $2
+		INSERT INTO counter_metrics (id, labels, value) VALUES ($1, $2, $3)
+		ON CONFLICT (id, labels) DO UPDATE
+    		SET value = counter_metrics.value + $3
 	`
 
-	stmt, err := s.db.PrepareContext(ctx, query)
-	if err != nil {
-		s.log.WithError(err).Error("Failed to prepare query")
-		return
-	}
-	defer func() {
-		_ = stmt.Close()
-	}()
+	metricID, labels := splitKey(id)
+	err := s.inTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, query, metricID, labels, value)
+		if err != nil {
+			s.log.WithError(err).Error("Error adding counter")
+			return err
+		}
 
-	_, err = stmt.ExecContext(ctx, id, value)
+		return s.addSamples(ctx, tx, model.TypeCounter, []string{id})
+	})
 	if err != nil {
-		s.log.WithError(err).Error("Error setting gauge")
-		return
+		s.log.WithError(err).Error("Failed to add counter")
 	}
 }
 
@@ -267,15 +318,17 @@ func (s Storage) AddCounters(ctx context.Context, counters []storage.Counter) er
 
 	models := make(map[string]modelCounter, len(counters))
 	for _, counter := range counters {
-		if v, ok := models[counter.ID]; ok {
+		key := counter.Key()
+		if v, ok := models[key]; ok {
 			v.Value += counter.Value
-			models[counter.ID] = v
+			models[key] = v
 			continue
 		}
 
-		models[counter.ID] = modelCounter{
-			ID:    counter.ID,
-			Value: counter.Value,
+		models[key] = modelCounter{
+			ID:     counter.ID,
+			Labels: counter.Labels.String(),
+			Value:  counter.Value,
 		}
 	}
 
@@ -285,16 +338,352 @@ func (s Storage) AddCounters(ctx context.Context, counters []storage.Counter) er
 	}
 
 	query := `
-		INSERT INTO counter_metrics (id, value) VALUES (:id, :value)
-		ON CONFLICT (id) DO UPDATE
+		INSERT INTO counter_metrics (id, labels, value) VALUES (:id, :labels, :value)
+		ON CONFLICT (id, labels) DO UPDATE
     		SET value = counter_metrics.value + :value
 	`
 
-	_, err := s.db.NamedExecContext(ctx, query, rows)
+	keys := make([]string, 0, len(rows))
+	for _, m := range rows {
+		keys = append(keys, seriesKey(m.ID, m.Labels))
+	}
+
+	return s.inTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.NamedExecContext(ctx, query, rows)
+		if err != nil {
+			s.log.WithError(err).Error("Error setting counters")
+			return err
+		}
+
+		return s.addSamples(ctx, tx, model.TypeCounter, keys)
+	})
+}
+
+func (s Storage) GetAllHistograms(ctx context.Context) storage.Histograms {
+	query := `SELECT id, labels, bounds, counts, sum, count FROM histogram_metrics ORDER BY id, labels`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to query")
+		return nil
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	typeMap := pgtype.NewMap()
+	histograms := make(storage.Histograms)
+	for rows.Next() {
+		var m modelHistogram
+		err = rows.Scan(&m.ID, &m.Labels, typeMap.SQLScanner(&m.Bounds), typeMap.SQLScanner(&m.Counts), &m.Sum, &m.Count)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to scan")
+			return nil
+		}
+
+		histograms[seriesKey(m.ID, m.Labels)] = m.toHistogram()
+	}
+
+	if rows.Err() != nil {
+		s.log.WithError(rows.Err()).Error("Failed to query")
+		return nil
+	}
+
+	return histograms
+}
+
+func (s Storage) GetHistogram(ctx context.Context, id string) (model.Histogram, error) {
+	m, err := s.getHistogram(ctx, s.db, id, false)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Histogram{}, storage.NewMetricNotFoundError(model.TypeHistogram, id, err)
+		}
+
+		s.log.WithError(err).Error("Failed to scan")
+		return model.Histogram{}, err
+	}
+
+	return m.toHistogram(), nil
+}
+
+// AddHistograms объединяет хранимые гистограммы с переданными в одной транзакции.
+// Строка гистограммы блокируется на время объединения, поэтому параллельные обновления не теряются.
+func (s Storage) AddHistograms(ctx context.Context, histograms []storage.Histogram) error {
+	if len(histograms) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(histograms))
+	merged := make(map[string]model.Histogram, len(histograms))
+	for _, histogram := range histograms {
+		key := histogram.Key()
+		if current, ok := merged[key]; ok {
+			merged[key] = current.Merge(histogram.Value)
+			continue
+		}
+
+		keys = append(keys, key)
+		merged[key] = histogram.Value
+	}
+
+	insertQuery := `
+		INSERT INTO histogram_metrics (id, labels, bounds, counts, sum, count) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id, labels) DO NOTHING
+	`
+	updateQuery := `
+		UPDATE histogram_metrics SET bounds = $3, counts = $4, sum = $5, count = $6
+		WHERE id = $1 AND labels = $2
+	`
+
+	return s.inTx(ctx, func(tx *sqlx.Tx) error {
+		for _, key := range keys {
+			id, labels := splitKey(key)
+			value := merged[key]
+
+			res, err := tx.ExecContext(ctx, insertQuery, id, labels, append([]float64{}, value.Bounds...), toInt64s(value.Counts), value.Sum, int64(value.Count))
+			if err != nil {
+				s.log.WithError(err).Error("Error adding histogram")
+				return err
+			}
+
+			if inserted, err := res.RowsAffected(); err == nil && inserted > 0 {
+				continue
+			}
+
+			current, err := s.getHistogram(ctx, tx, key, true)
+			if err != nil {
+				s.log.WithError(err).Error("Error getting histogram")
+				return err
+			}
+
+			value = current.toHistogram().Merge(value)
+
+			_, err = tx.ExecContext(ctx, updateQuery, id, labels, append([]float64{}, value.Bounds...), toInt64s(value.Counts), value.Sum, int64(value.Count))
+			if err != nil {
+				s.log.WithError(err).Error("Error adding histogram")
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s Storage) Delete(ctx context.Context, kind, id string) error {
+	var table string
+
+	switch kind {
+	case model.TypeGauge:
+		table = "gauge_metrics"
+	case model.TypeCounter:
+		table = "counter_metrics"
+	case model.TypeHistogram:
+		table = "histogram_metrics"
+	default:
+		return fmt.Errorf("unknown metric type: %s", kind)
+	}
+
+	metricID, labels := splitKey(id)
+
+	var deleted int64
+	err := s.inTx(ctx, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND labels = $2`, table), metricID, labels)
+		if err != nil {
+			s.log.WithError(err).Error("Error deleting metric")
+			return err
+		}
+
+		deleted, err = res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM metric_samples WHERE kind = $1 AND id = $2 AND labels = $3`, kind, metricID, labels)
+		if err != nil {
+			s.log.WithError(err).Error("Error deleting samples")
+			return err
+		}
+
+		return nil
+	})
 	if err != nil {
-		s.log.WithError(err).Error("Error setting counters")
 		return err
 	}
 
+	if deleted == 0 {
+		return storage.NewMetricNotFoundError(kind, id, nil)
+	}
+
 	return nil
 }
+
+func (s Storage) GetSamples(ctx context.Context, kind, id string, from, to time.Time) ([]storage.Sample, error) {
+	if kind != model.TypeGauge && kind != model.TypeCounter {
+		return nil, fmt.Errorf("unknown metric type: %s", kind)
+	}
+
+	if retentionFrom := time.Now().Add(-s.historyRetention); from.Before(retentionFrom) {
+		from = retentionFrom
+	}
+
+	query := `
+		SELECT ts, value FROM metric_samples
+		WHERE kind = $1 AND id = $2 AND labels = $3 AND ts BETWEEN $4 AND $5
+		ORDER BY ts
+	`
+
+	metricID, labels := splitKey(id)
+
+	var models []modelSample
+	err := s.db.SelectContext(ctx, &models, query, kind, metricID, labels, from, to)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to query samples")
+		return nil, err
+	}
+
+	if len(models) == 0 {
+		var exists bool
+		err = s.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM metric_samples WHERE kind = $1 AND id = $2 AND labels = $3)`, kind, metricID, labels)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to query samples")
+			return nil, err
+		}
+
+		if !exists {
+			return nil, storage.NewMetricNotFoundError(kind, id, nil)
+		}
+	}
+
+	samples := make([]storage.Sample, 0, len(models))
+	for _, m := range models {
+		samples = append(samples, storage.Sample{
+			Timestamp: m.Timestamp,
+			Value:     m.Value,
+		})
+	}
+
+	return samples, nil
+}
+
+// StartRetention запускает воркер, который с периодичностью interval удаляет устаревшую историю значений.
+func (s Storage) StartRetention(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, err := s.db.ExecContext(ctx, `DELETE FROM metric_samples WHERE ts < $1`, time.Now().Add(-s.historyRetention))
+				if err != nil {
+					s.log.WithError(err).Error("Failed to delete outdated samples")
+				}
+			}
+		}
+	}()
+}
+
+// Метод addSamples добавляет в историю текущие значения перечисленных по ключам серий метрик.
+func (s Storage) addSamples(ctx context.Context, db sqlx.ExecerContext, kind string, keys []string) error {
+	table := "gauge_metrics"
+	if kind == model.TypeCounter {
+		table = "counter_metrics"
+	}
+
+	ids := make([]string, 0, len(keys))
+	labels := make([]string, 0, len(keys))
+	for _, key := range keys {
+		id, l := splitKey(key)
+		ids = append(ids, id)
+		labels = append(labels, l)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO metric_samples (kind, id, labels, ts, value)
+		SELECT $1, m.id, m.labels, now(), m.value FROM %s m
+		JOIN unnest($2::text[], $3::text[]) AS k(id, labels) ON m.id = k.id AND m.labels = k.labels
+	`, table)
+
+	_, err := db.ExecContext(ctx, query, kind, ids, labels)
+	if err != nil {
+		s.log.WithError(err).Error("Error adding samples")
+		return err
+	}
+
+	return nil
+}
+
+// Метод getHistogram читает гистограмму по ключу серии. Если forUpdate равен true, строка блокируется до конца транзакции.
+func (s Storage) getHistogram(ctx context.Context, db sqlx.QueryerContext, key string, forUpdate bool) (modelHistogram, error) {
+	query := `SELECT id, labels, bounds, counts, sum, count FROM histogram_metrics WHERE id = $1 AND labels = $2`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	id, labels := splitKey(key)
+	typeMap := pgtype.NewMap()
+
+	var m modelHistogram
+	err := db.QueryRowxContext(ctx, query, id, labels).
+		Scan(&m.ID, &m.Labels, typeMap.SQLScanner(&m.Bounds), typeMap.SQLScanner(&m.Counts), &m.Sum, &m.Count)
+
+	return m, err
+}
+
+// Метод inTx выполняет функцию fn в транзакции.
+func (s Storage) inTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to begin transaction")
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Функция seriesKey собирает ключ серии из ID метрики и меток в каноническом виде, как они хранятся в БД.
+func seriesKey(id, labels string) string {
+	if labels == "" {
+		return id
+	}
+
+	return id + "{" + labels + "}"
+}
+
+// Функция splitKey разбирает ключ серии на ID метрики и метки в каноническом виде, как они хранятся в БД.
+func splitKey(key string) (string, string) {
+	id, labels := model.SplitSeriesKey(key)
+	return id, labels.String()
+}
+
+func (m modelHistogram) toHistogram() model.Histogram {
+	counts := make([]uint64, 0, len(m.Counts))
+	for _, c := range m.Counts {
+		counts = append(counts, uint64(c))
+	}
+
+	return model.Histogram{
+		Bounds: m.Bounds,
+		Counts: counts,
+		Sum:    m.Sum,
+		Count:  uint64(m.Count),
+	}
+}
+
+func toInt64s(values []uint64) []int64 {
+	res := make([]int64, 0, len(values))
+	for _, v := range values {
+		res = append(res, int64(v))
+	}
+
+	return res
+}
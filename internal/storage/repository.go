@@ -1,30 +1,78 @@
 package storage
 
-import "context"
+import (
+	"context"
+	"time"
 
-// Gauges тип для метрик типа gauge.
+	"github.com/bjlag/go-metrics/internal/model"
+)
+
+// Gauges тип для метрик типа gauge. Ключом является ключ серии, см. [model.SeriesKey].
 type Gauges map[string]float64
 
-// Counters тип для метрик типа counter.
+// Counters тип для метрик типа counter. Ключом является ключ серии, см. [model.SeriesKey].
 type Counters map[string]int64
 
+// Histograms тип для метрик типа histogram. Ключом является ключ серии, см. [model.SeriesKey].
+type Histograms map[string]model.Histogram
+
 // Gauge тип метрики gauge.
 type Gauge struct {
 	// ID метрики.
 	ID string
+	// Labels метки метрики.
+	Labels model.Labels
 	// Value значение метрики.
 	Value float64
 }
 
+// Key возвращает ключ серии метрики.
+func (g Gauge) Key() string {
+	return model.SeriesKey(g.ID, g.Labels)
+}
+
 // Counter тип метрики counter.
 type Counter struct {
 	// ID метрики.
 	ID string
+	// Labels метки метрики.
+	Labels model.Labels
 	// Value значение метрики.
 	Value int64
 }
 
+// Key возвращает ключ серии метрики.
+func (c Counter) Key() string {
+	return model.SeriesKey(c.ID, c.Labels)
+}
+
+// Histogram тип метрики histogram.
+type Histogram struct {
+	// ID метрики.
+	ID string
+	// Labels метки метрики.
+	Labels model.Labels
+	// Value значение метрики.
+	Value model.Histogram
+}
+
+// Key возвращает ключ серии метрики.
+func (h Histogram) Key() string {
+	return model.SeriesKey(h.ID, h.Labels)
+}
+
+// Sample значение метрики в момент времени.
+type Sample struct {
+	// Timestamp время записи значения.
+	Timestamp time.Time
+	// Value значение метрики. Для метрики типа counter это накопленное значение после записи.
+	Value float64
+}
+
 // Repository общий интерфейс репозитория для работы с метриками.
+//
+// Параметр id методов репозитория — ключ серии: ID метрики вместе с метками, см. [model.SeriesKey].
+// Для метрики без меток ключ совпадает с ее ID.
 type Repository interface {
 	// GetAllGauges возвращает все метрики типа Gauge, которые хранятся в хранилище.
 	GetAllGauges(ctx context.Context) Gauges
@@ -42,4 +90,15 @@ type Repository interface {
 	AddCounter(ctx context.Context, id string, value int64)
 	// AddCounters добавляет значения из набора переданных метрик типа Counter в хранилище.
 	AddCounters(ctx context.Context, counters []Counter) error
+	// GetAllHistograms возвращает все метрики типа Histogram, которые хранятся в хранилище.
+	GetAllHistograms(ctx context.Context) Histograms
+	// GetHistogram возвращает значение метрики типа Histogram по ее ID.
+	GetHistogram(ctx context.Context, id string) (model.Histogram, error)
+	// AddHistograms объединяет хранимые метрики типа Histogram с переданными, см. [model.Histogram.Merge].
+	AddHistograms(ctx context.Context, histograms []Histogram) error
+	// Delete удаляет метрику указанного типа вместе с историей ее значений.
+	// Если метрики нет, возвращает [NotFoundError].
+	Delete(ctx context.Context, kind, id string) error
+	// GetSamples возвращает историю значений метрики указанного типа за период [from, to] в порядке записи.
+	GetSamples(ctx context.Context, kind, id string, from, to time.Time) ([]Sample, error)
 }
@@ -0,0 +1,92 @@
+package watch
+
+import (
+	"sync"
+
+	"github.com/bjlag/go-metrics/internal/model"
+)
+
+// Event изменение метрики.
+type Event struct {
+	// Kind тип метрики.
+	Kind string
+	// ID ключ серии метрики, см. [model.SeriesKey].
+	ID string
+	// Deleted метрика удалена, значение не заполняется.
+	Deleted bool
+	// Gauge значение метрики типа gauge после изменения.
+	Gauge float64
+	// Counter накопленное значение метрики типа counter после изменения.
+	Counter int64
+	// Histogram значение метрики типа histogram после изменения.
+	Histogram model.Histogram
+}
+
+// Hub рассылает события об изменении метрик подписчикам.
+//
+// Рассылка не блокирует запись метрик: если буфер подписчика переполнен, подписка закрывается,
+// и подписчик узнает об этом по закрытию канала.
+type Hub struct {
+	lock        sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+// NewHub создает хаб.
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Subscribe подписывает на события. Размер буфера канала задается параметром buffer.
+// Возвращает канал событий и функцию отмены подписки.
+func (h *Hub) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+
+	h.lock.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.lock.Unlock()
+
+	return ch, func() {
+		h.unsubscribe(ch)
+	}
+}
+
+// HasSubscribers возвращает true, если есть хотя бы один подписчик.
+func (h *Hub) HasSubscribers() bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	return len(h.subscribers) > 0
+}
+
+// Publish рассылает событие всем подписчикам.
+func (h *Hub) Publish(event Event) {
+	var slow []chan Event
+
+	h.lock.RLock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+			slow = append(slow, ch)
+		}
+	}
+	h.lock.RUnlock()
+
+	for _, ch := range slow {
+		h.unsubscribe(ch)
+	}
+}
+
+func (h *Hub) unsubscribe(ch chan Event) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, ok := h.subscribers[ch]; !ok {
+		return
+	}
+
+	delete(h.subscribers, ch)
+	close(ch)
+}
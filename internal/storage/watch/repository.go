@@ -0,0 +1,124 @@
+package watch
+
+import (
+	"context"
+
+	"github.com/bjlag/go-metrics/internal/model"
+	"github.com/bjlag/go-metrics/internal/storage"
+)
+
+// Repository декоратор хранилища, который после каждой записи публикует в [Hub] новые значения измененных метрик.
+// Значения читаются из хранилища только при наличии подписчиков.
+type Repository struct {
+	storage.Repository
+	hub *Hub
+}
+
+// NewRepository создает декоратор хранилища repo.
+func NewRepository(repo storage.Repository, hub *Hub) *Repository {
+	return &Repository{
+		Repository: repo,
+		hub:        hub,
+	}
+}
+
+func (r *Repository) SetGauge(ctx context.Context, id string, value float64) {
+	r.Repository.SetGauge(ctx, id, value)
+	r.publish(ctx, model.TypeGauge, id)
+}
+
+func (r *Repository) SetGauges(ctx context.Context, gauges []storage.Gauge) error {
+	err := r.Repository.SetGauges(ctx, gauges)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(gauges))
+	for _, g := range gauges {
+		keys = append(keys, g.Key())
+	}
+	r.publish(ctx, model.TypeGauge, keys...)
+
+	return nil
+}
+
+func (r *Repository) AddCounter(ctx context.Context, id string, value int64) {
+	r.Repository.AddCounter(ctx, id, value)
+	r.publish(ctx, model.TypeCounter, id)
+}
+
+func (r *Repository) AddCounters(ctx context.Context, counters []storage.Counter) error {
+	err := r.Repository.AddCounters(ctx, counters)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(counters))
+	for _, c := range counters {
+		keys = append(keys, c.Key())
+	}
+	r.publish(ctx, model.TypeCounter, keys...)
+
+	return nil
+}
+
+func (r *Repository) AddHistograms(ctx context.Context, histograms []storage.Histogram) error {
+	err := r.Repository.AddHistograms(ctx, histograms)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(histograms))
+	for _, h := range histograms {
+		keys = append(keys, h.Key())
+	}
+	r.publish(ctx, model.TypeHistogram, keys...)
+
+	return nil
+}
+
+func (r *Repository) Delete(ctx context.Context, kind, id string) error {
+	err := r.Repository.Delete(ctx, kind, id)
+	if err != nil {
+		return err
+	}
+
+	if r.hub.HasSubscribers() {
+		r.hub.Publish(Event{Kind: kind, ID: id, Deleted: true})
+	}
+
+	return nil
+}
+
+// Метод publish читает текущие значения метрик и публикует их. Повторяющиеся ключи публикуются один раз.
+func (r *Repository) publish(ctx context.Context, kind string, keys ...string) {
+	if !r.hub.HasSubscribers() {
+		return
+	}
+
+	seen := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		event := Event{Kind: kind, ID: key}
+
+		var err error
+		switch kind {
+		case model.TypeGauge:
+			event.Gauge, err = r.Repository.GetGauge(ctx, key)
+		case model.TypeCounter:
+			event.Counter, err = r.Repository.GetCounter(ctx, key)
+		case model.TypeHistogram:
+			event.Histogram, err = r.Repository.GetHistogram(ctx, key)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		r.hub.Publish(event)
+	}
+}
@@ -0,0 +1,56 @@
+package watch_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/model"
+	"github.com/bjlag/go-metrics/internal/storage"
+	"github.com/bjlag/go-metrics/internal/storage/memory"
+	"github.com/bjlag/go-metrics/internal/storage/watch"
+)
+
+func TestRepository_Publish(t *testing.T) {
+	ctx := context.Background()
+	hub := watch.NewHub()
+	repo := watch.NewRepository(memory.NewStorage(), hub)
+
+	events, unsubscribe := hub.Subscribe(10)
+	defer unsubscribe()
+
+	repo.AddCounter(ctx, "PollCount", 2)
+	err := repo.AddCounters(ctx, []storage.Counter{
+		{ID: "PollCount", Value: 1},
+		{ID: "PollCount", Value: 1},
+	})
+	require.NoError(t, err)
+	err = repo.Delete(ctx, model.TypeCounter, "PollCount")
+	require.NoError(t, err)
+
+	require.Len(t, events, 3)
+	assert.Equal(t, watch.Event{Kind: model.TypeCounter, ID: "PollCount", Counter: 2}, <-events)
+	assert.Equal(t, watch.Event{Kind: model.TypeCounter, ID: "PollCount", Counter: 4}, <-events)
+	assert.Equal(t, watch.Event{Kind: model.TypeCounter, ID: "PollCount", Deleted: true}, <-events)
+}
+
+func TestHub_SlowSubscriber(t *testing.T) {
+	hub := watch.NewHub()
+
+	slow, _ := hub.Subscribe(1)
+	fast, unsubscribe := hub.Subscribe(2)
+	defer unsubscribe()
+
+	hub.Publish(watch.Event{ID: "first"})
+	hub.Publish(watch.Event{ID: "second"})
+
+	assert.Equal(t, "first", (<-slow).ID)
+	_, ok := <-slow
+	assert.False(t, ok, "slow subscriber must be closed")
+
+	assert.Equal(t, "first", (<-fast).ID)
+	assert.Equal(t, "second", (<-fast).ID)
+	assert.True(t, hub.HasSubscribers())
+}
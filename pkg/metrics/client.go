@@ -0,0 +1,295 @@
+// Package metrics клиент для отправки метрик из Go сервисов на сервер go-metrics.
+//
+// Сервис заводит типизированные метрики [Counter] и [Gauge] в [Registry], а [Client] в фоне
+// отправляет накопленные значения батчами по HTTP или gRPC тем же протоколом, что и агент:
+// с подписью HashSHA256, шифрованием публичным ключом и сжатием gzip.
+//
+//	client, err := metrics.New(metrics.WithHTTP("localhost:8080"), metrics.WithSecretKey("secret"))
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//
+//	requests := client.Counter("requests", map[string]string{"route": "/api"})
+//	requests.Inc()
+package metrics
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	agent "github.com/bjlag/go-metrics/internal/agent/client"
+	"github.com/bjlag/go-metrics/internal/agent/client/http"
+	"github.com/bjlag/go-metrics/internal/agent/client/rpc"
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+	"github.com/bjlag/go-metrics/internal/agent/limiter"
+	"github.com/bjlag/go-metrics/internal/logger"
+	"github.com/bjlag/go-metrics/internal/securety/crypt"
+	"github.com/bjlag/go-metrics/internal/securety/signature"
+)
+
+// DefaultFlushInterval интервал отправки метрик по умолчанию.
+const DefaultFlushInterval = 10 * time.Second
+
+var errNoTransport = errors.New("metrics: server address is not set, use WithHTTP or WithGRPC")
+
+// Logger интерфейс логгера клиента.
+type Logger = logger.Logger
+
+// Client отправляет метрики реестра на сервер через равные интервалы времени.
+type Client struct {
+	*Registry
+
+	sender agent.Client
+	closer func() error
+	labels map[string]string
+	log    Logger
+
+	flushLock sync.Mutex
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type options struct {
+	httpAddr      string
+	grpcAddr      string
+	secretKey     string
+	keyID         string
+	token         string
+	publicKeyPath string
+	tls           *tls.Config
+	flushInterval time.Duration
+	registry      *Registry
+	labels        map[string]string
+	log           Logger
+}
+
+// Option настраивает клиент.
+type Option func(o *options)
+
+// WithHTTP отправлять метрики на HTTP сервер по адресу host:port.
+func WithHTTP(addr string) Option {
+	return func(o *options) {
+		o.httpAddr = addr
+	}
+}
+
+// WithGRPC отправлять метрики на gRPC сервер по адресу host:port. Имеет приоритет над [WithHTTP].
+func WithGRPC(addr string) Option {
+	return func(o *options) {
+		o.grpcAddr = addr
+	}
+}
+
+// WithSecretKey подписывать запросы ключом key.
+func WithSecretKey(key string) Option {
+	return func(o *options) {
+		o.secretKey = key
+	}
+}
+
+// WithKeyID передавать с подписью идентификатор id ключа, выданного сервером. Сам ключ задается [WithSecretKey].
+func WithKeyID(id string) Option {
+	return func(o *options) {
+		o.keyID = id
+	}
+}
+
+// WithToken передавать токен доступа token, выданный сервером. Токену нужна роль ingest.
+func WithToken(token string) Option {
+	return func(o *options) {
+		o.token = token
+	}
+}
+
+// WithPublicKey шифровать отправляемые метрики публичным ключом сервера из файла path.
+func WithPublicKey(path string) Option {
+	return func(o *options) {
+		o.publicKeyPath = path
+	}
+}
+
+// WithTLS подключаться к серверу по TLS с настройками config: HTTPS для [WithHTTP] и TLS для [WithGRPC].
+// Без этой опции соединение не шифруется.
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) {
+		o.tls = config
+	}
+}
+
+// WithFlushInterval задает интервал отправки метрик, по умолчанию [DefaultFlushInterval].
+func WithFlushInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.flushInterval = interval
+	}
+}
+
+// WithRegistry использовать реестр registry вместо нового.
+func WithRegistry(registry *Registry) Option {
+	return func(o *options) {
+		o.registry = registry
+	}
+}
+
+// WithLabels добавлять метки labels ко всем метрикам. Метки самой метрики с теми же названиями имеют приоритет.
+func WithLabels(labels map[string]string) Option {
+	return func(o *options) {
+		o.labels = copyLabels(labels)
+	}
+}
+
+// WithLogger задает логгер, по умолчанию логи не пишутся.
+func WithLogger(log Logger) Option {
+	return func(o *options) {
+		o.log = log
+	}
+}
+
+// New создает клиент и запускает фоновую отправку метрик. Клиент нужно закрыть методом [Client.Close].
+func New(opts ...Option) (*Client, error) {
+	o := &options{
+		flushInterval: DefaultFlushInterval,
+		log:           nopLogger{},
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.flushInterval <= 0 {
+		return nil, fmt.Errorf("metrics: flush interval must be positive")
+	}
+
+	if o.registry == nil {
+		o.registry = NewRegistry()
+	}
+
+	c := &Client{
+		Registry: o.registry,
+		closer:   func() error { return nil },
+		labels:   o.labels,
+		log:      o.log,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	sign := signature.NewSignManager(o.secretKey, signature.WithKeyID(o.keyID))
+
+	encrypt, err := crypt.NewEncryptManager(o.publicKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("metrics: %w", err)
+	}
+
+	switch {
+	case o.grpcAddr != "":
+		sender, err := rpc.NewSender(o.grpcAddr, sign, o.log,
+			rpc.WithEncryption(encrypt),
+			rpc.WithToken(o.token),
+			rpc.WithTLS(o.tls),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("metrics: %w", err)
+		}
+
+		c.sender = sender
+		c.closer = sender.Close
+	case o.httpAddr != "":
+		host, portStr, err := net.SplitHostPort(o.httpAddr)
+		if err != nil {
+			return nil, fmt.Errorf("metrics: invalid HTTP address: %w", err)
+		}
+
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return nil, fmt.Errorf("metrics: invalid HTTP port: %w", err)
+		}
+
+		c.sender, err = http.NewSender(host, port, sign, encrypt, limiter.NewRateLimiter(1), o.log,
+			http.WithToken(o.token),
+			http.WithTLS(o.tls),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("metrics: %w", err)
+		}
+	default:
+		return nil, errNoTransport
+	}
+
+	go c.run(o.flushInterval)
+
+	return c, nil
+}
+
+// Flush отправляет накопленные значения. Если сервер недоступен, значения вернутся в реестр
+// и будут отправлены при следующей попытке. Значения, отклоненные сервером как некорректные,
+// в реестр не возвращаются.
+func (c *Client) Flush() error {
+	c.flushLock.Lock()
+	defer c.flushLock.Unlock()
+
+	metrics, restore := c.collect()
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	if len(c.labels) > 0 {
+		for i, m := range metrics {
+			metrics[i] = collector.NewMetric(m.Kind(), m.Name(), m.Value()).
+				WithLabels(c.labels).
+				WithLabels(m.Labels())
+		}
+	}
+
+	err := c.sender.Send(metrics)
+	if err != nil && !errors.Is(err, agent.ErrRejected) {
+		restore()
+	}
+
+	return err
+}
+
+// Close останавливает фоновую отправку, отправляет оставшиеся значения и закрывает соединение с сервером.
+func (c *Client) Close() error {
+	var err error
+
+	c.closeOnce.Do(func() {
+		close(c.stop)
+		<-c.done
+
+		err = errors.Join(c.Flush(), c.closer())
+	})
+
+	return err
+}
+
+func (c *Client) run(interval time.Duration) {
+	defer close(c.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if err := c.Flush(); err != nil {
+				c.log.WithError(err).Error("Failed to flush metrics")
+			}
+		}
+	}
+}
+
+type nopLogger struct{}
+
+func (l nopLogger) WithField(_ string, _ interface{}) logger.Logger { return l }
+func (l nopLogger) WithError(_ error) logger.Logger                 { return l }
+func (l nopLogger) Error(_ string)                                  {}
+func (l nopLogger) Info(_ string)                                   {}
+func (l nopLogger) Debug(_ string)                                  {}
@@ -0,0 +1,152 @@
+package metrics_test
+
+import (
+	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjlag/go-metrics/internal/model"
+	"github.com/bjlag/go-metrics/internal/securety/signature"
+	"github.com/bjlag/go-metrics/pkg/metrics"
+)
+
+type fakeServer struct {
+	lock     sync.Mutex
+	fail     bool
+	requests [][]model.UpdateIn
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	zr, err := gzip.NewReader(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var in []model.UpdateIn
+	if err = json.NewDecoder(zr).Decode(&in); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	stamp, err := signature.ParseStamp(r.Header.Get(signature.HeaderTimestamp), r.Header.Get(signature.HeaderNonce))
+	if err != nil || stamp.IsZero() {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	jsonb, _ := json.Marshal(in)
+	if ok, _ := signature.NewSignManager("secret").Verify(signature.RequestPayload(r.Method, r.URL.RequestURI(), stamp, jsonb), r.Header.Get("HashSHA256")); !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.requests = append(s.requests, in)
+}
+
+func TestClient_Flush(t *testing.T) {
+	server := &fakeServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := metrics.New(
+		metrics.WithHTTP(strings.TrimPrefix(ts.URL, "http://")),
+		metrics.WithSecretKey("secret"),
+		metrics.WithFlushInterval(time.Hour),
+		metrics.WithLabels(map[string]string{"service": "billing", "route": "default"}),
+	)
+	require.NoError(t, err)
+
+	requests := client.Counter("requests", map[string]string{"route": "/api"})
+	requests.Inc()
+	requests.Add(2)
+	assert.Same(t, requests, client.Counter("requests", map[string]string{"route": "/api"}))
+
+	client.Gauge("queue_size", nil).Set(5)
+
+	server.fail = true
+	assert.Error(t, client.Flush())
+
+	requests.Inc()
+	server.fail = false
+	require.NoError(t, client.Flush())
+
+	require.Len(t, server.requests, 1)
+	require.Len(t, server.requests[0], 2)
+
+	counter := server.requests[0][0]
+	assert.Equal(t, "requests", counter.ID)
+	assert.Equal(t, int64(4), *counter.Delta)
+	assert.Equal(t, model.Labels{"service": "billing", "route": "/api"}, counter.Labels)
+
+	gauge := server.requests[0][1]
+	assert.Equal(t, "queue_size", gauge.ID)
+	assert.Equal(t, float64(5), *gauge.Value)
+
+	require.NoError(t, client.Flush())
+	assert.Len(t, server.requests, 1, "nothing changed since last flush")
+
+	requests.Inc()
+	require.NoError(t, client.Close())
+	require.Len(t, server.requests, 2)
+	assert.Equal(t, int64(1), *server.requests[1][0].Delta)
+}
+
+func TestClient_Flush_TLS(t *testing.T) {
+	server := &fakeServer{}
+	ts := httptest.NewTLSServer(server)
+	defer ts.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+
+	client, err := metrics.New(
+		metrics.WithHTTP(strings.TrimPrefix(ts.URL, "https://")),
+		metrics.WithSecretKey("secret"),
+		metrics.WithTLS(&tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}),
+		metrics.WithFlushInterval(time.Hour),
+	)
+	require.NoError(t, err)
+
+	client.Gauge("queue_size", nil).Set(5)
+	require.NoError(t, client.Flush())
+	require.NoError(t, client.Close())
+
+	require.Len(t, server.requests, 1)
+	assert.Equal(t, "queue_size", server.requests[0][0].ID)
+}
+
+func TestNew_WithoutAddress(t *testing.T) {
+	_, err := metrics.New()
+	assert.Error(t, err)
+}
+
+func TestRegistry_InvalidName(t *testing.T) {
+	assert.Panics(t, func() {
+		metrics.NewRegistry().Gauge("requests{route}", nil)
+	})
+}
+
+func TestRegistry_InvalidLabels(t *testing.T) {
+	assert.Panics(t, func() {
+		metrics.NewRegistry().Counter("requests", map[string]string{"1route": "/api"})
+	})
+}
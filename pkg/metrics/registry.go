@@ -0,0 +1,198 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bjlag/go-metrics/internal/agent/collector"
+	"github.com/bjlag/go-metrics/internal/model"
+)
+
+// Counter счетчик. Приращения накапливаются до отправки и обнуляются после нее: на сервере счетчик суммируется.
+type Counter struct {
+	name   string
+	labels map[string]string
+	delta  atomic.Int64
+}
+
+// Add увеличивает счетчик на delta.
+func (c *Counter) Add(delta int64) {
+	c.delta.Add(delta)
+}
+
+// Inc увеличивает счетчик на единицу.
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Gauge метрика, которая хранит текущее значение. Отправляется, только если изменилась с прошлой отправки.
+type Gauge struct {
+	name    string
+	labels  map[string]string
+	bits    atomic.Uint64
+	updated atomic.Bool
+}
+
+// Set устанавливает значение.
+func (g *Gauge) Set(value float64) {
+	g.bits.Store(math.Float64bits(value))
+	g.updated.Store(true)
+}
+
+// Add прибавляет delta к текущему значению.
+func (g *Gauge) Add(delta float64) {
+	for {
+		old := g.bits.Load()
+		value := math.Float64bits(math.Float64frombits(old) + delta)
+		if g.bits.CompareAndSwap(old, value) {
+			break
+		}
+	}
+	g.updated.Store(true)
+}
+
+// Value возвращает текущее значение.
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(g.bits.Load())
+}
+
+// Registry реестр метрик сервиса. Метрика определяется названием и метками,
+// повторный запрос метрики с теми же названием и метками возвращает тот же объект.
+type Registry struct {
+	lock     sync.Mutex
+	counters map[string]*Counter
+	gauges   map[string]*Gauge
+}
+
+// NewRegistry создает пустой реестр.
+func NewRegistry() *Registry {
+	return &Registry{
+		counters: make(map[string]*Counter),
+		gauges:   make(map[string]*Gauge),
+	}
+}
+
+// Counter возвращает счетчик с названием name и метками labels, создавая его при первом обращении.
+// Паникует, если название пустое или метки некорректны.
+func (r *Registry) Counter(name string, labels map[string]string) *Counter {
+	key := mustKey(name, labels)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	c, ok := r.counters[key]
+	if !ok {
+		c = &Counter{name: name, labels: copyLabels(labels)}
+		r.counters[key] = c
+	}
+
+	return c
+}
+
+// Gauge возвращает gauge с названием name и метками labels, создавая его при первом обращении.
+// Паникует, если название пустое или метки некорректны.
+func (r *Registry) Gauge(name string, labels map[string]string) *Gauge {
+	key := mustKey(name, labels)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	g, ok := r.gauges[key]
+	if !ok {
+		g = &Gauge{name: name, labels: copyLabels(labels)}
+		r.gauges[key] = g
+	}
+
+	return g
+}
+
+// Метод collect забирает накопленные приращения счетчиков и измененные gauge.
+// Возвращает метрики для отправки и функцию, которая возвращает забранные значения, если отправить их не удалось.
+func (r *Registry) collect() ([]*collector.Metric, func()) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var (
+		metrics  []*collector.Metric
+		counters []*Counter
+		deltas   []int64
+		gauges   []*Gauge
+	)
+
+	for _, key := range sortedKeys(r.counters) {
+		c := r.counters[key]
+
+		delta := c.delta.Swap(0)
+		if delta == 0 {
+			continue
+		}
+
+		metrics = append(metrics, collector.NewCounterMetric(c.name, delta).WithLabels(c.labels))
+		counters = append(counters, c)
+		deltas = append(deltas, delta)
+	}
+
+	for _, key := range sortedKeys(r.gauges) {
+		g := r.gauges[key]
+		if !g.updated.Swap(false) {
+			continue
+		}
+
+		metrics = append(metrics, collector.NewGaugeMetric(g.name, g.Value()).WithLabels(g.labels))
+		gauges = append(gauges, g)
+	}
+
+	restore := func() {
+		for i, c := range counters {
+			c.Add(deltas[i])
+		}
+
+		for _, g := range gauges {
+			g.updated.Store(true)
+		}
+	}
+
+	return metrics, restore
+}
+
+func mustKey(name string, labels map[string]string) string {
+	if name == "" {
+		panic("metrics: metric name must not be empty")
+	}
+
+	if !model.IsValidID(name) {
+		panic(fmt.Sprintf("metrics: metric %q: name must not contain '{' or '}'", name))
+	}
+
+	if err := model.Labels(labels).Validate(); err != nil {
+		panic(fmt.Sprintf("metrics: metric %s: %s", name, err))
+	}
+
+	return model.SeriesKey(name, labels)
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	c := make(map[string]string, len(labels))
+	for name, value := range labels {
+		c[name] = value
+	}
+
+	return c
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}